@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+	"go-rod-testing-browser-restrict/internal/logger"
+)
+
+// Exit code CLI
+const (
+	exitOK = 0
+	// exitError untuk kegagalan umum (setup, download, tulis file)
+	exitError = 1
+	// exitUsage untuk argumen atau flag yang salah
+	exitUsage = 2
+	// exitDoctor jika doctor menemukan masalah atau library Chrome tidak ada
+	exitDoctor = 3
+	// exitLaunch jika Chrome gagal dijalankan atau terhubung
+	exitLaunch = 4
+	// exitNavigate jika navigasi gagal atau diblokir policy
+	exitNavigate = 5
+)
+
+// command adalah satu subcommand CLI
+type command struct {
+	name    string
+	summary string
+	run     func(c *cli, args []string) int
+}
+
+// commands berisi semua subcommand sesuai urutan di usage
+var commands = []command{
+	{"install", "download Chrome dan dependencies, lalu tampilkan path executable", runInstall},
+	{"doctor", "periksa runtime, sandbox, instalasi dan library", runDoctor},
+	{"open", "buka URL dan tampilkan judul page", runOpen},
+	{"screenshot", "simpan screenshot URL ke file PNG/JPEG", runScreenshot},
+	{"pdf", "simpan URL sebagai PDF", runPDF},
+	{"versions", "kelola versi Chrome yang terinstall (list, pin, unpin, prune)", func(c *cli, args []string) int {
+		return runVersions(c.quietManager(), args)
+	}},
+	{"prune", "alias untuk \"versions prune\"", func(c *cli, args []string) int {
+		return runVersions(c.quietManager(), append([]string{"prune"}, args...))
+	}},
+	{"serve", "jalankan Chrome dengan port DevTools tetap sampai dihentikan", runServe},
+}
+
+// cli berisi state global yang dipakai semua subcommand
+type cli struct {
+	config browser.Config
+	launch browser.LaunchOptions
+	log    *logger.Logger
+}
+
+// globalFlags adalah flag sebelum nama subcommand
+type globalFlags struct {
+	configPath string
+	logFormat  string
+	installDir string
+}
+
+// newGlobalFlagSet mendaftarkan flag global ke g
+func newGlobalFlagSet(g *globalFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("go-rod-testing-browser-restrict", flag.ContinueOnError)
+	fs.StringVar(&g.configPath, "config", "", "file konfigurasi JSON")
+	fs.StringVar(&g.logFormat, "log-format", "text", "format log: text atau json")
+	fs.StringVar(&g.installDir, "install-dir", "", "direktori instalasi Chrome (default ~/.local/share/<install_dir_name>)")
+	return fs
+}
+
+// parseGlobalFlags membaca flag global dan mengembalikan sisa argumen
+// (nama subcommand dan argumennya)
+func parseGlobalFlags(args []string, output io.Writer) (globalFlags, []string, error) {
+	var g globalFlags
+	fs := newGlobalFlagSet(&g)
+	fs.SetOutput(output)
+	fs.Usage = func() { printUsage(output) }
+	if err := fs.Parse(args); err != nil {
+		return g, nil, err
+	}
+	return g, fs.Args(), nil
+}
+
+// runCLI menjalankan CLI dan mengembalikan exit code
+func runCLI(args []string) int {
+	globals, rest, err := parseGlobalFlags(args, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	} else if err != nil {
+		return exitUsage
+	}
+	if len(rest) == 0 {
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+
+	cmd, ok := findCommand(rest[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", rest[0])
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+
+	format, err := logger.ParseFormat(globals.logFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return exitUsage
+	}
+
+	file, err := loadConfigFile(globals.configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return exitUsage
+	}
+	c := &cli{config: browser.DefaultConfig(), launch: browser.DefaultLaunchOptions()}
+	if err := file.apply(&c.config, &c.launch); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid config %s: %s\n", globals.configPath, err)
+		return exitUsage
+	}
+	if globals.installDir != "" {
+		c.config.RootDir = globals.installDir
+	}
+
+	// Inisialisasi logger
+	c.log, err = logger.NewWithFormat(format)
+	if err == nil && c.log.GetPath() != "" {
+		c.log.LogKV("log_file", c.log.GetPath())
+	} else if err != nil {
+		c.log.LogKV("log_file_error", err.Error())
+	}
+
+	return cmd.run(c, rest[1:])
+}
+
+// findCommand mencari subcommand berdasarkan nama
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// manager membuat ChromiumManager dari config global dengan logger CLI
+func (c *cli) manager() *browser.ChromiumManager {
+	return browser.NewChromiumManagerWithConfig(c.config, c.log.LogKV)
+}
+
+// quietManager membuat ChromiumManager tanpa log, untuk subcommand yang
+// output-nya berupa tabel
+func (c *cli) quietManager() *browser.ChromiumManager {
+	return browser.NewChromiumManagerWithConfig(c.config, nil)
+}
+
+// launchExitCode mencatat error setup/launch dan mengembalikan exit code-nya
+func (c *cli) launchExitCode(err error) int {
+	c.log.LogKV("browser_error", err.Error())
+	fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+
+	var missingLibs *browser.MissingLibrariesError
+	var sandboxErr *browser.SandboxUnavailableError
+	var launchErr *browser.LaunchError
+	var connectErr *browser.ConnectError
+	switch {
+	case errors.As(err, &missingLibs):
+		fmt.Fprintf(os.Stderr, "Chrome membutuhkan library: %s\n", strings.Join(missingLibs.Libraries, ", "))
+		fmt.Fprintln(os.Stderr, "Jalankan \"doctor\" untuk detailnya.")
+		return exitDoctor
+	case errors.As(err, &sandboxErr):
+		fmt.Fprintln(os.Stderr, "Sandbox tidak tersedia. Jalankan \"doctor\" untuk detailnya.")
+		return exitDoctor
+	case errors.As(err, &launchErr), errors.As(err, &connectErr):
+		fmt.Fprintln(os.Stderr, "Chrome gagal dijalankan. Lihat stderr di atas.")
+		return exitLaunch
+	}
+	fmt.Fprintln(os.Stderr, "Chrome gagal disetup. Pastikan koneksi internet aktif.")
+	return exitError
+}
+
+// printUsage menulis bantuan CLI ke w
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: go-rod-testing-browser-restrict [global flags] <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Global flags:")
+	fs := newGlobalFlagSet(&globalFlags{})
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Exit codes:")
+	fmt.Fprintln(w, "  0 sukses, 1 error, 2 argumen salah, 3 masalah sistem (doctor/library),")
+	fmt.Fprintln(w, "  4 Chrome gagal dijalankan, 5 navigasi gagal atau diblokir")
+}
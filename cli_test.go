@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// useTempLog mengarahkan file log CLI ke direktori sementara
+func useTempLog(t *testing.T) {
+	t.Helper()
+	t.Setenv("RUNTIME_LOG_PATH", filepath.Join(t.TempDir(), "runtime-info.log"))
+}
+
+// Test exit code untuk argumen yang salah
+func TestRunCLIUsageErrors(t *testing.T) {
+	useTempLog(t)
+	installDir := t.TempDir()
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"no command", nil, exitUsage},
+		{"unknown command", []string{"frobnicate"}, exitUsage},
+		{"unknown global flag", []string{"-nope", "doctor"}, exitUsage},
+		{"invalid log format", []string{"-log-format", "xml", "versions", "list"}, exitUsage},
+		{"missing config", []string{"-config", filepath.Join(installDir, "missing.json"), "versions", "list"}, exitUsage},
+		{"open without url", []string{"-install-dir", installDir, "open"}, exitUsage},
+		{"versions without action", []string{"-install-dir", installDir, "versions"}, exitUsage},
+		{"help", []string{"-h"}, exitOK},
+		{"versions list", []string{"-install-dir", installDir, "-log-format", "json", "versions", "list"}, exitOK},
+		{"prune alias", []string{"-install-dir", installDir, "prune", "-keep", "1", "-dry-run"}, exitOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runCLI(tt.args); got != tt.want {
+				t.Errorf("runCLI(%q) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+// Test file -config diterapkan ke Config dan LaunchOptions
+func TestConfigFileApply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+		"milestone": "131",
+		"install_dir": "/opt/chrome",
+		"download_timeout": "5m",
+		"download_attempts": 5,
+		"launch": {
+			"headless": "off",
+			"sandbox": "strict",
+			"window_width": 800,
+			"window_height": 600,
+			"extra_flags": {"mute-audio": ""}
+		}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fc, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatalf("loadConfigFile: %v", err)
+	}
+	config := browser.DefaultConfig()
+	launch := browser.DefaultLaunchOptions()
+	if err := fc.apply(&config, &launch); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	if config.Channel != "" || config.Milestone != "131" {
+		t.Errorf("version selection = channel %q milestone %q, want milestone only", config.Channel, config.Milestone)
+	}
+	if config.RootDir != "/opt/chrome" || config.DownloadTimeout != 5*time.Minute || config.DownloadAttempts != 5 {
+		t.Errorf("config not applied: %+v", config)
+	}
+	if launch.Headless != browser.HeadlessOff || launch.Sandbox != browser.SandboxStrict {
+		t.Errorf("launch modes = %s/%s", launch.Headless, launch.Sandbox)
+	}
+	if launch.WindowWidth != 800 || launch.WindowHeight != 600 {
+		t.Errorf("window = %dx%d", launch.WindowWidth, launch.WindowHeight)
+	}
+	if _, ok := launch.ExtraFlags["mute-audio"]; !ok {
+		t.Error("extra flag not merged")
+	}
+	if _, ok := launch.ExtraFlags["disable-gpu"]; !ok {
+		t.Error("default flags should be kept")
+	}
+}
+
+// Test file yang hanya memilih distribusi non Chrome for Testing tidak mewarisi
+// channel dari config dasar
+func TestConfigFileDistributionOnly(t *testing.T) {
+	for _, dist := range []string{browser.DistChromiumSnapshot, browser.DistUngoogled} {
+		config := browser.DefaultConfig()
+		config.Channel = browser.ChannelStable
+		launch := browser.DefaultLaunchOptions()
+		if err := (fileConfig{Distribution: dist}).apply(&config, &launch); err != nil {
+			t.Fatalf("apply: %v", err)
+		}
+		if config.Distribution != dist || config.Channel != "" {
+			t.Errorf("%s: distribution %q channel %q, want channel cleared", dist, config.Distribution, config.Channel)
+		}
+	}
+
+	config := browser.DefaultConfig()
+	config.Channel = browser.ChannelBeta
+	launch := browser.DefaultLaunchOptions()
+	if err := (fileConfig{Distribution: browser.DistHeadlessShell}).apply(&config, &launch); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if config.Channel != browser.ChannelBeta {
+		t.Errorf("headless shell should keep channel, got %q", config.Channel)
+	}
+}
+
+// Test field yang tidak dikenal dan durasi yang salah ditolak
+func TestConfigFileRejectsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	unknown := filepath.Join(dir, "unknown.json")
+	if err := os.WriteFile(unknown, []byte(`{"chanel": "beta"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfigFile(unknown); err == nil {
+		t.Error("expected error for unknown field")
+	}
+
+	fc := fileConfig{LockTimeout: "soon"}
+	config := browser.DefaultConfig()
+	launch := browser.DefaultLaunchOptions()
+	if err := fc.apply(&config, &launch); err == nil {
+		t.Error("expected error for invalid lock_timeout")
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// fileConfig adalah isi file -config. Field yang kosong tidak mengubah
+// browser.DefaultConfig atau browser.DefaultLaunchOptions.
+type fileConfig struct {
+	Distribution     string            `json:"distribution"`
+	Channel          string            `json:"channel"`
+	Milestone        string            `json:"milestone"`
+	Version          string            `json:"version"`
+	DownloadURL      string            `json:"download_url"`
+	VersionsBaseURL  string            `json:"versions_base_url"`
+	SHA256           string            `json:"sha256"`
+	ChecksumURL      string            `json:"checksum_url"`
+	InstallDirName   string            `json:"install_dir_name"`
+	InstallDir       string            `json:"install_dir"`
+	DownloadTimeout  string            `json:"download_timeout"`
+	DownloadAttempts int               `json:"download_attempts"`
+	LockTimeout      string            `json:"lock_timeout"`
+	Launch           *fileLaunchConfig `json:"launch"`
+}
+
+// fileLaunchConfig adalah bagian "launch" dari file -config
+type fileLaunchConfig struct {
+	Headless     string            `json:"headless"`
+	Sandbox      string            `json:"sandbox"`
+	WindowWidth  int               `json:"window_width"`
+	WindowHeight int               `json:"window_height"`
+	Proxy        string            `json:"proxy"`
+	Locale       string            `json:"locale"`
+	UserDataDir  string            `json:"user_data_dir"`
+	ExtraFlags   map[string]string `json:"extra_flags"`
+	RemoveFlags  []string          `json:"remove_flags"`
+	Env          []string          `json:"env"`
+}
+
+// loadConfigFile membaca file konfigurasi JSON. Path kosong berarti tanpa file.
+func loadConfigFile(path string) (fileConfig, error) {
+	var fc fileConfig
+	if path == "" {
+		return fc, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fc, fmt.Errorf("failed to read config: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&fc); err != nil {
+		return fc, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return fc, nil
+}
+
+// isChromeForTesting mengembalikan true jika distribusi (kosong = tidak
+// diubah) mendukung pemilihan channel
+func isChromeForTesting(distribution string) bool {
+	switch distribution {
+	case "", browser.DistChromeForTesting, browser.DistHeadlessShell:
+		return true
+	}
+	return false
+}
+
+// apply menerapkan isi file ke config dan opsi launch
+func (fc fileConfig) apply(config *browser.Config, launch *browser.LaunchOptions) error {
+	// Versi dipilih lewat salah satu dari channel, milestone atau version.
+	// Channel dari config dasar dikosongkan jika file memilih yang lain atau
+	// memilih distribusi yang hanya mendukung Version (snapshot, ungoogled).
+	if fc.Milestone != "" || fc.Version != "" || !isChromeForTesting(fc.Distribution) {
+		config.Channel = ""
+	}
+	setString(&config.Distribution, fc.Distribution)
+	setString(&config.Channel, fc.Channel)
+	setString(&config.Milestone, fc.Milestone)
+	setString(&config.Version, fc.Version)
+	setString(&config.DownloadURL, fc.DownloadURL)
+	setString(&config.VersionsBaseURL, fc.VersionsBaseURL)
+	setString(&config.SHA256, fc.SHA256)
+	setString(&config.ChecksumURL, fc.ChecksumURL)
+	setString(&config.InstallDirName, fc.InstallDirName)
+	setString(&config.RootDir, fc.InstallDir)
+	if fc.DownloadAttempts < 0 {
+		return fmt.Errorf("download_attempts must not be negative")
+	}
+	if fc.DownloadAttempts > 0 {
+		config.DownloadAttempts = fc.DownloadAttempts
+	}
+	if err := setDuration(&config.DownloadTimeout, "download_timeout", fc.DownloadTimeout); err != nil {
+		return err
+	}
+	if err := setDuration(&config.LockTimeout, "lock_timeout", fc.LockTimeout); err != nil {
+		return err
+	}
+
+	if l := fc.Launch; l != nil {
+		setString((*string)(&launch.Headless), l.Headless)
+		setString((*string)(&launch.Sandbox), l.Sandbox)
+		setString(&launch.Proxy, l.Proxy)
+		setString(&launch.Locale, l.Locale)
+		setString(&launch.UserDataDir, l.UserDataDir)
+		if l.WindowWidth > 0 && l.WindowHeight > 0 {
+			launch.WindowWidth, launch.WindowHeight = l.WindowWidth, l.WindowHeight
+		}
+		if len(l.ExtraFlags) > 0 && launch.ExtraFlags == nil {
+			launch.ExtraFlags = map[string]string{}
+		}
+		for name, value := range l.ExtraFlags {
+			launch.ExtraFlags[name] = value
+		}
+		launch.RemoveFlags = append(launch.RemoveFlags, l.RemoveFlags...)
+		launch.Env = append(launch.Env, l.Env...)
+	}
+	return nil
+}
+
+// setString mengisi dst jika value tidak kosong
+func setString(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+// setDuration mengisi dst dari string durasi (contoh "5m") jika tidak kosong
+func setDuration(dst *time.Duration, name, value string) error {
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	*dst = d
+	return nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+	"go-rod-testing-browser-restrict/internal/runtime"
+)
+
+// doctorReport mengumpulkan hasil pemeriksaan doctor
+type doctorReport struct {
+	problems int
+}
+
+func (r *doctorReport) ok(format string, args ...any) {
+	fmt.Printf("[ok]   "+format+"\n", args...)
+}
+
+func (r *doctorReport) warn(format string, args ...any) {
+	fmt.Printf("[warn] "+format+"\n", args...)
+}
+
+func (r *doctorReport) fail(format string, args ...any) {
+	r.problems++
+	fmt.Printf("[fail] "+format+"\n", args...)
+}
+
+// runDoctor menjalankan subcommand "doctor". Exit code exitDoctor jika ada
+// pemeriksaan yang gagal.
+func runDoctor(c *cli, args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	launch := fs.Bool("launch", false, "coba jalankan Chrome (bisa memicu download versi baru)")
+	timeout := fs.Duration("timeout", 2*time.Minute, "batas waktu untuk -launch")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "Usage: doctor [-launch] [-timeout 2m]")
+		return exitUsage
+	}
+
+	// Log semua informasi runtime
+	runtime.NewInfo(c.log.LogKV).LogAll()
+
+	report := &doctorReport{}
+	mgr := c.manager()
+	fmt.Println()
+	fmt.Printf("install dir: %s\n", mgr.RootDir())
+
+	checkSandbox(report, c.launch.Sandbox)
+	executables := checkInstalledVersions(report, c.quietManager())
+	if len(executables) == 0 {
+		// Tanpa Chrome terinstall, hanya daftar dependency yang bisa dicek
+		checkDependencies(report, c, mgr.LibDir())
+	}
+	for _, executable := range executables {
+		checkLibraries(report, c.quietManager(), executable)
+	}
+
+	if *launch {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		b, err := mgr.GetBrowserWithOptions(ctx, c.launch)
+		if err != nil {
+			report.fail("launch: %s", err)
+		} else {
+			version, verr := b.Context(ctx).Version()
+			if verr == nil {
+				report.ok("launch: %s", version.Product)
+			} else {
+				report.fail("launch: connected but version query failed: %s", verr)
+			}
+			if err := b.Close(); err != nil {
+				c.log.LogKV("browser_close_error", err.Error())
+			}
+		}
+	}
+
+	if report.problems > 0 {
+		fmt.Printf("\n%d problem(s) found\n", report.problems)
+		return exitDoctor
+	}
+	fmt.Println("\nno problems found")
+	return exitOK
+}
+
+// checkSandbox melaporkan dukungan sandbox. Sandbox yang tidak tersedia hanya
+// menjadi masalah pada mode strict; mode lain memakai --no-sandbox.
+func checkSandbox(report *doctorReport, mode browser.SandboxMode) {
+	status := runtime.DetectSandbox()
+	if status.Supported {
+		report.ok("sandbox: supported")
+		return
+	}
+	reasons := strings.Join(status.Reasons, "; ")
+	if mode == browser.SandboxStrict {
+		report.fail("sandbox: unavailable in strict mode: %s", reasons)
+		return
+	}
+	report.warn("sandbox: unavailable, chrome will run with --no-sandbox: %s", reasons)
+}
+
+// checkInstalledVersions melaporkan versi Chrome yang terinstall dan
+// mengembalikan executable yang ada
+func checkInstalledVersions(report *doctorReport, mgr *browser.ChromiumManager) []string {
+	versions, err := mgr.ListVersions()
+	if err != nil {
+		report.fail("chrome: cannot read installed versions: %s", err)
+		return nil
+	}
+	if len(versions) == 0 {
+		report.fail("chrome: not installed, run \"install\"")
+		return nil
+	}
+	var executables []string
+	for _, v := range versions {
+		if v.Executable == "" {
+			report.warn("chrome %s (%s): executable unknown", v.Version, v.Distribution)
+			continue
+		}
+		if _, err := os.Stat(v.Executable); err != nil {
+			report.fail("chrome %s (%s): executable missing: %s", v.Version, v.Distribution, v.Executable)
+			continue
+		}
+		report.ok("chrome %s (%s): %s", v.Version, v.Distribution, v.Executable)
+		executables = append(executables, v.Executable)
+	}
+	return executables
+}
+
+// checkLibraries melaporkan DT_NEEDED executable dan .so bawaannya yang
+// tidak ditemukan, beserta Dependency yang menyediakannya
+func checkLibraries(report *doctorReport, mgr *browser.ChromiumManager, executable string) {
+	libs, err := mgr.DiagnoseLibraries(executable)
+	if err != nil {
+		report.fail("libraries: %s", err)
+		return
+	}
+	if libs.OK() {
+		report.ok("libraries: %d shared libraries resolved for %s", len(libs.Resolved), executable)
+		return
+	}
+	for _, missing := range libs.Unresolved {
+		neededBy := make([]string, len(missing.NeededBy))
+		for i, path := range missing.NeededBy {
+			neededBy[i] = filepath.Base(path)
+		}
+		provider := "not in dependency list"
+		if missing.Provider != nil {
+			provider = "provided by dependency " + missing.Provider.Name
+		}
+		report.fail("library %s: not found (needed by %s); %s", missing.Soname, strings.Join(neededBy, ", "), provider)
+	}
+}
+
+// checkDependencies melaporkan library dependencies yang tidak ditemukan
+func checkDependencies(report *doctorReport, c *cli, libDir string) {
+	dm := browser.NewDependencyManager(libDir, nil)
+	missing := dm.MissingDependencies(c.config.Dependencies)
+	if len(missing) == 0 {
+		report.ok("dependencies: %d libraries found", len(c.config.Dependencies))
+		return
+	}
+	for _, dep := range missing {
+		report.fail("dependency %s: %s not found in %s or system library dirs", dep.Name, dep.LibraryName, libDir)
+	}
+}
@@ -4,6 +4,7 @@ go 1.25.4
 
 require (
 	github.com/go-rod/rod v0.116.2
+	github.com/klauspost/compress v1.18.0
 	github.com/ulikunitz/xz v0.5.15
 )
 
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// runInstall menjalankan subcommand "install"
+func runInstall(c *cli, args []string) int {
+	fs := flag.NewFlagSet("install", flag.ContinueOnError)
+	timeout := fs.Duration("timeout", 0, "batas waktu download dan ekstraksi (0 = tanpa batas)")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "Usage: install [-timeout 10m]")
+		return exitUsage
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	mgr := c.manager()
+	if err := mgr.SetupContext(ctx); err != nil {
+		return c.launchExitCode(err)
+	}
+	c.log.LogKV("install_duration", time.Since(start).Round(time.Millisecond).String())
+	fmt.Println(mgr.GetExecutablePath())
+	return exitOK
+}
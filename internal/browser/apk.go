@@ -0,0 +1,122 @@
+package browser
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// apkInfoLimit membatasi ukuran .PKGINFO yang dibaca
+const apkInfoLimit = 1 << 20
+
+// apkBackend mengekstrak package Alpine (.apk v2) dengan pure Go. File .apk
+// adalah beberapa stream gzip yang disambung (signature, control berisi
+// .PKGINFO, lalu data); jika dibaca sebagai satu stream hasilnya satu tar.
+type apkBackend struct {
+	logger func(key, value string)
+}
+
+func (b *apkBackend) Format() string {
+	return PackageAPK
+}
+
+func (b *apkBackend) PackageURL(dep Dependency, osType string) string {
+	if dep.APK == nil {
+		return ""
+	}
+	return dep.APK.URL
+}
+
+func (b *apkBackend) Extract(ctx context.Context, apkPath, root string) (PackageInfo, error) {
+	file, err := os.Open(apkPath)
+	if err != nil {
+		return PackageInfo{}, fmt.Errorf("failed to read apk: %w", err)
+	}
+	defer file.Close()
+
+	br := bufio.NewReader(file)
+	if magic, _ := br.Peek(len(gzipMagic)); !bytes.Equal(magic, gzipMagic) {
+		// apk v3 (format ADB) belum didukung
+		return PackageInfo{}, fmt.Errorf("not an apk v2 package")
+	}
+	gr, err := gzip.NewReader(br)
+	if err != nil {
+		return PackageInfo{}, fmt.Errorf("failed to read apk: %w", err)
+	}
+	defer gr.Close()
+
+	reader := &apkReader{tr: tar.NewReader(gr)}
+	if _, err := extractTarTo(ctx, reader, root, nil); err != nil {
+		return reader.info, err
+	}
+	if reader.info.Name == "" {
+		return reader.info, fmt.Errorf("apk package has no .PKGINFO")
+	}
+	return reader.info, nil
+}
+
+// apkReader membungkus tar .apk: file metadata di root (.PKGINFO,
+// .SIGN.*, script .pre-install, dll) tidak diekstrak, .PKGINFO diparse
+// ke info
+type apkReader struct {
+	tr   *tar.Reader
+	info PackageInfo
+}
+
+func (a *apkReader) Next() (*tar.Header, error) {
+	for {
+		hdr, err := a.tr.Next()
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimPrefix(hdr.Name, "./")
+		if strings.Contains(name, "/") || !strings.HasPrefix(name, ".") {
+			return hdr, nil
+		}
+		if name == ".PKGINFO" {
+			info, err := parsePkgInfo(io.LimitReader(a.tr, apkInfoLimit))
+			if err != nil {
+				return nil, err
+			}
+			a.info = info
+		}
+	}
+}
+
+func (a *apkReader) Read(p []byte) (int, error) {
+	return a.tr.Read(p)
+}
+
+// parsePkgInfo membaca .PKGINFO ("key = value" per baris, "#" komentar)
+func parsePkgInfo(r io.Reader) (PackageInfo, error) {
+	var info PackageInfo
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "pkgname":
+			info.Name = strings.TrimSpace(value)
+		case "pkgver":
+			info.Version = strings.TrimSpace(value)
+		case "arch":
+			info.Architecture = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return info, fmt.Errorf("failed to read .PKGINFO: %w", err)
+	}
+	return info, nil
+}
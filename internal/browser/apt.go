@@ -0,0 +1,344 @@
+package browser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+// dpkgStatusPath adalah database package yang terinstall di sistem Debian/Ubuntu
+const dpkgStatusPath = "/var/lib/dpkg/status"
+
+// aptRecordFile mencatat package yang sudah diekstrak ke libDir ("nama versi" per baris)
+const aptRecordFile = ".apt-packages"
+
+// AptSource adalah repository APT tempat dependency diambil. Package
+// diresolve dari index Packages sehingga dependency transitif ikut diunduh
+// dan setiap .deb diverifikasi dengan SHA256 dari index.
+type AptSource struct {
+	// BaseURL mirror, contoh "https://deb.debian.org/debian"
+	BaseURL string
+	// Suite adalah codename rilis, contoh "bookworm". Kosong berarti
+	// VERSION_CODENAME dari os-release host, lalu DefaultSuite.
+	Suite string
+	// DefaultSuite dipakai jika Suite kosong dan os-release tidak punya codename
+	DefaultSuite string
+	// Components kosong berarti "main"
+	Components []string
+	// Arch arsitektur Debian, contoh "amd64"; kosong berarti sesuai runtime.GOARCH
+	Arch string
+	// Exclude berisi package yang tidak pernah diunduh walaupun dibutuhkan,
+	// contoh "libc6" (glibc harus dari sistem)
+	Exclude []string
+	// Keyring adalah keyring OpenPGP (format biner, seperti di
+	// /usr/share/keyrings) untuk memverifikasi InRelease dengan gpgv. Kosong
+	// berarti Release diunduh tanpa verifikasi tanda tangan; SHA256 index
+	// tetap dicek terhadap Release.
+	Keyring string
+}
+
+// defaultAptSources mengembalikan repository resmi Debian dan Ubuntu untuk
+// arsitektur saat ini
+func defaultAptSources() map[string]AptSource {
+	ubuntu := "http://archive.ubuntu.com/ubuntu"
+	if arch := debArch(runtime.GOARCH); arch != "amd64" && arch != "i386" {
+		// Arsitektur selain x86 ada di mirror ports
+		ubuntu = "http://ports.ubuntu.com/ubuntu-ports"
+	}
+	return map[string]AptSource{
+		"debian": {
+			BaseURL:      "https://deb.debian.org/debian",
+			DefaultSuite: "bookworm",
+			Components:   []string{"main"},
+			Exclude:      []string{"libc6"},
+			Keyring:      "/usr/share/keyrings/debian-archive-keyring.gpg",
+		},
+		"ubuntu": {
+			BaseURL:      ubuntu,
+			DefaultSuite: "jammy",
+			Components:   []string{"main", "universe"},
+			Exclude:      []string{"libc6"},
+			Keyring:      "/usr/share/keyrings/ubuntu-archive-keyring.gpg",
+		},
+	}
+}
+
+// debArch menerjemahkan GOARCH ke nama arsitektur Debian
+func debArch(goarch string) string {
+	switch goarch {
+	case "386":
+		return "i386"
+	case "arm":
+		return "armhf"
+	case "ppc64le":
+		return "ppc64el"
+	}
+	return goarch
+}
+
+// withDefaults mengisi Suite (dari codename os-release), Components dan Arch
+// yang kosong
+func (s AptSource) withDefaults(codename string) AptSource {
+	if s.Suite == "" {
+		s.Suite = codename
+	}
+	if s.Suite == "" {
+		s.Suite = s.DefaultSuite
+	}
+	if len(s.Components) == 0 {
+		s.Components = []string{"main"}
+	}
+	if s.Arch == "" {
+		s.Arch = debArch(runtime.GOARCH)
+	}
+	s.BaseURL = strings.TrimSuffix(s.BaseURL, "/")
+	return s
+}
+
+// FetchPackageIndex mengunduh dan menggabungkan index Packages semua
+// component. Packages.xz dicoba lebih dulu, lalu Packages.gz dan Packages;
+// setiap index diverifikasi dengan ukuran dan SHA256 dari Release suite.
+func (dm *DependencyManager) FetchPackageIndex(ctx context.Context, source AptSource) (*PackageIndex, error) {
+	source = source.withDefaults(dm.osRelease()["VERSION_CODENAME"])
+	if source.BaseURL == "" || source.Suite == "" {
+		return nil, fmt.Errorf("apt source requires base url and suite")
+	}
+
+	release, err := dm.fetchRelease(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release for %s: %w", source.Suite, err)
+	}
+
+	idx := NewPackageIndex()
+	for _, component := range source.Components {
+		dir := fmt.Sprintf("%s/binary-%s/", component, source.Arch)
+		base := fmt.Sprintf("%s/dists/%s/", source.BaseURL, source.Suite)
+		var lastErr error
+		loaded := false
+		for _, name := range []string{"Packages.xz", "Packages.gz", "Packages"} {
+			want, ok := release[dir+name]
+			if !ok {
+				lastErr = fmt.Errorf("%s not listed in release", dir+name)
+				continue
+			}
+			err := dm.loadPackageIndex(ctx, idx, base+dir+name, want)
+			if err == nil {
+				dm.logger("dependencies_apt_index", base+dir+name)
+				loaded = true
+				break
+			}
+			var mismatch *ChecksumMismatchError
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			} else if errors.As(err, &mismatch) {
+				// Index yang tidak cocok dengan Release tidak diganti varian lain
+				return nil, err
+			}
+			lastErr = err
+		}
+		if !loaded {
+			return nil, fmt.Errorf("failed to fetch package index for %s/%s: %w", source.Suite, component, lastErr)
+		}
+	}
+	return idx, nil
+}
+
+// loadPackageIndex mengunduh satu file index, memverifikasinya dengan entry
+// Release want lalu menambahkannya ke idx
+func (dm *DependencyManager) loadPackageIndex(ctx context.Context, idx *PackageIndex, url string, want releaseFile) error {
+	// Satu byte lebih dari ukuran di Release agar file yang lebih besar
+	// terdeteksi sebagai mismatch
+	data, err := dm.fetchRepoFile(ctx, url, want.Size+1)
+	if err != nil {
+		return err
+	}
+	if err := verifyReleaseFile(url, data, want); err != nil {
+		return err
+	}
+
+	var r io.Reader = bytes.NewReader(data)
+	switch {
+	case strings.HasSuffix(url, ".xz"):
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("%s: %w", url, err)
+		}
+		r = xr
+	case strings.HasSuffix(url, ".gz"):
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("%s: %w", url, err)
+		}
+		defer gr.Close()
+		r = gr
+	}
+	if err := idx.parse(r); err != nil {
+		return fmt.Errorf("%s: %w", url, err)
+	}
+	return nil
+}
+
+// setupFromApt meresolve dependency yang belum ada di libDir dari index
+// repository, lalu mengunduh, memverifikasi dan mengekstrak setiap package.
+// Dependency yang namanya tidak ada di index dikembalikan sebagai fallback
+// agar dapat diunduh dari URL dependency.
+func (dm *DependencyManager) setupFromApt(ctx context.Context, source AptSource, dependencies []Dependency) (fallback []Dependency, err error) {
+	var names []string
+	for _, dep := range dependencies {
+		if dm.isLibraryInstalled(dep.LibraryName) {
+			dm.logger("dependencies_skip", fmt.Sprintf("%s (already installed)", dep.Name))
+			continue
+		}
+		names = append(names, dep.Name)
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	source = source.withDefaults(dm.osRelease()["VERSION_CODENAME"])
+	idx, err := dm.FetchPackageIndex(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	installed := dm.installedPackages()
+	pkgs, err := idx.Resolve(names, ResolveOptions{Installed: installed, Exclude: source.Exclude})
+	var resolveErr *ResolveError
+	if errors.As(err, &resolveErr) {
+		// Nama yang tidak ada di rilis ini (misalnya libffi7 di bookworm)
+		// diambil dari URL dependency; package lain tetap dari index
+		for _, failure := range resolveErr.Failures {
+			dm.logger("dependencies_apt_unresolved", failure.Error())
+		}
+		missing := map[string]bool{}
+		for _, name := range resolveErr.Missing() {
+			missing[name] = true
+		}
+		for _, dep := range dependencies {
+			if missing[dep.Name] {
+				fallback = append(fallback, dep)
+			}
+		}
+	}
+	if err != nil && len(pkgs) == 0 {
+		return nil, fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+	resolved := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		resolved[i] = pkg.Name + "=" + pkg.Version
+	}
+	dm.logger("dependencies_apt_resolved", strings.Join(resolved, " "))
+
+	for _, pkg := range pkgs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := dm.installAptPackage(ctx, source, pkg); err != nil {
+			return nil, fmt.Errorf("failed to install %s: %w", pkg.Name, err)
+		}
+		dm.logger("dependencies_installed", pkg.Name+"="+pkg.Version)
+	}
+	return fallback, nil
+}
+
+// installAptPackage mengunduh satu .deb, memverifikasi SHA256 dari index lalu mengekstraknya
+func (dm *DependencyManager) installAptPackage(ctx context.Context, source AptSource, pkg *AptPackage) error {
+	if pkg.Filename == "" || pkg.SHA256 == "" {
+		return fmt.Errorf("index entry has no Filename or SHA256")
+	}
+	url := source.BaseURL + "/" + strings.TrimPrefix(pkg.Filename, "/")
+	dm.logger("dependencies_url", url)
+
+	tmpFile := filepath.Join(dm.libDir, fmt.Sprintf(".%s.deb", pkg.Name))
+	hasher := sha256.New()
+	if err := dm.downloader.DownloadContext(ctx, url, tmpFile, hasher); err != nil {
+		return fmt.Errorf("failed to download: %w", err)
+	}
+	defer os.Remove(tmpFile)
+
+	if actual := hex.EncodeToString(hasher.Sum(nil)); actual != pkg.SHA256 {
+		return &ChecksumMismatchError{URL: url, Expected: pkg.SHA256, Actual: actual}
+	}
+	if err := dm.extractDeb(ctx, tmpFile, pkg.Name); err != nil {
+		return err
+	}
+	return dm.recordAptPackage(pkg)
+}
+
+// installedPackages menggabungkan package terinstall dari status dpkg dan
+// package yang sudah diekstrak ke libDir
+func (dm *DependencyManager) installedPackages() map[string]string {
+	installed := map[string]string{}
+	statusFile := dm.StatusFile
+	if statusFile == "" {
+		statusFile = dpkgStatusPath
+	}
+	if file, err := os.Open(statusFile); err == nil {
+		status, err := ParsePackageIndex(file)
+		file.Close()
+		if err != nil {
+			dm.logger("dependencies_dpkg_status_error", err.Error())
+		} else {
+			installed = status.installedPackages()
+		}
+	}
+
+	records, err := readAptRecords(filepath.Join(dm.libDir, aptRecordFile))
+	if err != nil {
+		dm.logger("dependencies_apt_record_error", err.Error())
+	}
+	for name, version := range records {
+		installed[name] = version
+	}
+	return installed
+}
+
+// recordAptPackage mencatat package yang sudah diekstrak agar tidak diunduh ulang
+func (dm *DependencyManager) recordAptPackage(pkg *AptPackage) error {
+	path := filepath.Join(dm.libDir, aptRecordFile)
+	records, err := readAptRecords(path)
+	if err != nil {
+		return err
+	}
+	records[pkg.Name] = pkg.Version
+
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s %s\n", name, records[name])
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// readAptRecords membaca file aptRecordFile; file yang belum ada berarti kosong
+func readAptRecords(path string) (map[string]string, error) {
+	records := map[string]string{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return records, nil
+	} else if err != nil {
+		return records, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if name, version, ok := strings.Cut(strings.TrimSpace(line), " "); ok {
+			records[name] = version
+		}
+	}
+	return records, nil
+}
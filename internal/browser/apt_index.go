@@ -0,0 +1,667 @@
+package browser
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AptPackage adalah satu stanza dari index Packages (atau file status dpkg)
+type AptPackage struct {
+	Name         string
+	Version      string
+	Architecture string
+	// Filename adalah path .deb relatif terhadap BaseURL repository
+	Filename string
+	SHA256   string
+	Size     int64
+	Priority string
+	// Essential bernilai true untuk package yang selalu ada di sistem Debian
+	Essential bool
+	// Depends berisi Pre-Depends dan Depends; setiap elemen adalah daftar
+	// alternatif yang dipisah "|"
+	Depends  [][]AptRelation
+	Provides []AptRelation
+	// Status hanya terisi dari file status dpkg, contoh "install ok installed"
+	Status string
+}
+
+// AptRelation adalah satu nama package dengan batasan versi opsional,
+// contoh "libnspr4 (>= 2:4.34)"
+type AptRelation struct {
+	Name string
+	// Op salah satu dari "<<", "<=", "=", ">=", ">>"; kosong berarti versi apa saja
+	Op      string
+	Version string
+}
+
+func (r AptRelation) String() string {
+	if r.Op == "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%s (%s %s)", r.Name, r.Op, r.Version)
+}
+
+// satisfiedBy mengecek apakah version memenuhi batasan relasi
+func (r AptRelation) satisfiedBy(version string) bool {
+	if r.Op == "" {
+		return true
+	}
+	c := CompareDebVersions(version, r.Version)
+	switch r.Op {
+	case "<<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case "=":
+		return c == 0
+	case ">=":
+		return c >= 0
+	case ">>":
+		return c > 0
+	}
+	return false
+}
+
+// PackageIndex berisi package dari satu atau beberapa index Packages
+type PackageIndex struct {
+	packages map[string][]*AptPackage
+	provides map[string][]*AptPackage
+}
+
+// NewPackageIndex membuat index kosong
+func NewPackageIndex() *PackageIndex {
+	return &PackageIndex{
+		packages: map[string][]*AptPackage{},
+		provides: map[string][]*AptPackage{},
+	}
+}
+
+// ParsePackageIndex membaca format index Packages (stanza "Field: value"
+// dipisah baris kosong, baris lanjutan diawali spasi)
+func ParsePackageIndex(r io.Reader) (*PackageIndex, error) {
+	idx := NewPackageIndex()
+	if err := idx.parse(r); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Len mengembalikan jumlah package di index
+func (idx *PackageIndex) Len() int {
+	n := 0
+	for _, pkgs := range idx.packages {
+		n += len(pkgs)
+	}
+	return n
+}
+
+// Lookup mengembalikan semua versi package name
+func (idx *PackageIndex) Lookup(name string) []*AptPackage {
+	return idx.packages[name]
+}
+
+// Add menambahkan package ke index
+func (idx *PackageIndex) Add(pkg *AptPackage) {
+	idx.packages[pkg.Name] = append(idx.packages[pkg.Name], pkg)
+	for _, p := range pkg.Provides {
+		idx.provides[p.Name] = append(idx.provides[p.Name], pkg)
+	}
+}
+
+// parse membaca stanza dari r ke index
+func (idx *PackageIndex) parse(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	fields := map[string]string{}
+	last := ""
+	line := 0
+	flush := func() error {
+		if len(fields) == 0 {
+			return nil
+		}
+		pkg, err := packageFromFields(fields)
+		if err != nil {
+			return fmt.Errorf("stanza ending at line %d: %w", line, err)
+		}
+		idx.Add(pkg)
+		fields = map[string]string{}
+		last = ""
+		return nil
+	}
+
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		switch {
+		case strings.TrimSpace(text) == "":
+			if err := flush(); err != nil {
+				return err
+			}
+		case text[0] == ' ' || text[0] == '\t':
+			if last == "" {
+				return fmt.Errorf("line %d: continuation without field", line)
+			}
+			fields[last] += "\n" + strings.TrimSpace(text)
+		default:
+			name, value, ok := strings.Cut(text, ":")
+			if !ok {
+				return fmt.Errorf("line %d: expected \"Field: value\"", line)
+			}
+			last = strings.ToLower(strings.TrimSpace(name))
+			fields[last] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read package index: %w", err)
+	}
+	return flush()
+}
+
+// packageFromFields membuat AptPackage dari field stanza (nama field lowercase)
+func packageFromFields(fields map[string]string) (*AptPackage, error) {
+	pkg := &AptPackage{
+		Name:         fields["package"],
+		Version:      fields["version"],
+		Architecture: fields["architecture"],
+		Filename:     fields["filename"],
+		SHA256:       strings.ToLower(fields["sha256"]),
+		Priority:     fields["priority"],
+		Essential:    strings.EqualFold(fields["essential"], "yes"),
+		Status:       fields["status"],
+	}
+	if pkg.Name == "" || pkg.Version == "" {
+		return nil, fmt.Errorf("missing Package or Version")
+	}
+	if size := fields["size"]; size != "" {
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: invalid Size %q", pkg.Name, size)
+		}
+		pkg.Size = n
+	}
+	for _, field := range []string{"pre-depends", "depends"} {
+		if fields[field] == "" {
+			continue
+		}
+		deps, err := parseAptRelations(fields[field])
+		if err != nil {
+			return nil, fmt.Errorf("package %s: invalid %s: %w", pkg.Name, field, err)
+		}
+		pkg.Depends = append(pkg.Depends, deps...)
+	}
+	if fields["provides"] != "" {
+		provides, err := parseAptRelations(fields["provides"])
+		if err != nil {
+			return nil, fmt.Errorf("package %s: invalid Provides: %w", pkg.Name, err)
+		}
+		for _, alternatives := range provides {
+			pkg.Provides = append(pkg.Provides, alternatives...)
+		}
+	}
+	return pkg, nil
+}
+
+// parseAptRelations membaca field seperti Depends:
+// "libc6 (>= 2.34), libnspr4 (>= 2:4.34) | libnspr4-alt, debconf:any"
+func parseAptRelations(value string) ([][]AptRelation, error) {
+	var groups [][]AptRelation
+	for _, group := range strings.Split(value, ",") {
+		group = strings.TrimSpace(group)
+		if group == "" {
+			continue
+		}
+		var alternatives []AptRelation
+		for _, alt := range strings.Split(group, "|") {
+			rel, err := parseAptRelation(alt)
+			if err != nil {
+				return nil, err
+			}
+			alternatives = append(alternatives, rel)
+		}
+		groups = append(groups, alternatives)
+	}
+	return groups, nil
+}
+
+// parseAptRelation membaca satu relasi. Qualifier arsitektur (":any"),
+// restriction arsitektur ("[amd64]") dan build profile ("<!nocheck>") diabaikan.
+func parseAptRelation(value string) (AptRelation, error) {
+	value = stripAptRestrictions(strings.TrimSpace(value))
+
+	var rel AptRelation
+	name := value
+	if open := strings.Index(value, "("); open >= 0 {
+		end := strings.Index(value, ")")
+		if end < open {
+			return rel, fmt.Errorf("unbalanced parenthesis in %q", value)
+		}
+		constraint := strings.TrimSpace(value[open+1 : end])
+		name = strings.TrimSpace(value[:open])
+		for _, op := range []string{"<<", "<=", ">=", ">>", "=", "<", ">"} {
+			if v, ok := strings.CutPrefix(constraint, op); ok {
+				rel.Op = op
+				rel.Version = strings.TrimSpace(v)
+				break
+			}
+		}
+		// "<" dan ">" adalah bentuk lama dari "<=" dan ">="
+		switch rel.Op {
+		case "<":
+			rel.Op = "<="
+		case ">":
+			rel.Op = ">="
+		case "":
+			return rel, fmt.Errorf("invalid version constraint in %q", value)
+		}
+	}
+	if arch := strings.Index(name, ":"); arch >= 0 {
+		name = name[:arch]
+	}
+	if name == "" {
+		return rel, fmt.Errorf("empty package name in %q", value)
+	}
+	rel.Name = name
+	return rel, nil
+}
+
+// ResolveOptions mengatur PackageIndex.Resolve
+type ResolveOptions struct {
+	// Installed berisi package yang sudah ada (nama ke versi), contoh dari
+	// file status dpkg. Package ini tidak diunduh jika versinya memenuhi.
+	Installed map[string]string
+	// Exclude berisi package yang tidak pernah diunduh, contoh libc6
+	Exclude []string
+	// IncludeRequired juga mengunduh package Essential dan Priority required
+	// (default dilewati karena selalu ada di sistem Debian/Ubuntu)
+	IncludeRequired bool
+}
+
+// UnsatisfiableError dikembalikan jika dependency tidak bisa dipenuhi dari index
+type UnsatisfiableError struct {
+	// Package yang membutuhkan; kosong untuk package yang diminta langsung
+	Package  string
+	Relation string
+}
+
+func (e *UnsatisfiableError) Error() string {
+	if e.Package == "" {
+		return fmt.Sprintf("package %s not found in index", e.Relation)
+	}
+	return fmt.Sprintf("dependency %s of %s cannot be satisfied", e.Relation, e.Package)
+}
+
+// VersionConflictError dikembalikan jika dependency membutuhkan versi
+// package yang berbeda dari versi yang sudah dipilih untuk nama lain
+type VersionConflictError struct {
+	// Package yang membutuhkan; kosong untuk package yang diminta langsung
+	Package  string
+	Relation string
+	// Selected adalah package yang sudah dipilih, "nama=versi"
+	Selected string
+}
+
+func (e *VersionConflictError) Error() string {
+	if e.Package == "" {
+		return fmt.Sprintf("package %s conflicts with selected %s", e.Relation, e.Selected)
+	}
+	return fmt.Sprintf("dependency %s of %s conflicts with selected %s", e.Relation, e.Package, e.Selected)
+}
+
+// ResolveFailure adalah satu nama yang gagal diresolve
+type ResolveFailure struct {
+	Name string
+	// Err adalah *UnsatisfiableError atau *VersionConflictError
+	Err error
+}
+
+func (f ResolveFailure) Error() string {
+	return f.Name + ": " + f.Err.Error()
+}
+
+// ResolveError dikembalikan Resolve jika sebagian nama gagal diresolve;
+// package untuk nama lain tetap dikembalikan
+type ResolveError struct {
+	Failures []ResolveFailure
+}
+
+func (e *ResolveError) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		parts[i] = f.Error()
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (e *ResolveError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// Missing mengembalikan nama yang diminta tetapi tidak ada di index
+func (e *ResolveError) Missing() []string {
+	var names []string
+	for _, f := range e.Failures {
+		var unsat *UnsatisfiableError
+		if errors.As(f.Err, &unsat) && unsat.Package == "" {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// Resolve mengembalikan package yang harus diunduh untuk names beserta semua
+// dependency-nya (transitif), diurutkan berdasarkan nama. Untuk setiap grup
+// alternatif dipilih alternatif pertama yang sudah terpenuhi, lalu alternatif
+// pertama yang ada di index; versi tertinggi yang memenuhi batasan dipakai.
+//
+// Setiap nama diresolve sendiri-sendiri: nama yang tidak ada di index,
+// dependency-nya tidak terpenuhi atau membutuhkan versi lain dari package
+// yang sudah dipilih dilewati tanpa menggagalkan nama lain. Kegagalan
+// tersebut dikembalikan sebagai *ResolveError bersama package yang berhasil
+// diresolve.
+func (idx *PackageIndex) Resolve(names []string, opts ResolveOptions) ([]*AptPackage, error) {
+	exclude := map[string]bool{}
+	for _, name := range opts.Exclude {
+		exclude[name] = true
+	}
+	selected := map[string]*AptPackage{}
+	var queue []*AptPackage
+
+	// satisfied mengecek relasi yang sudah terpenuhi tanpa menambah package
+	satisfied := func(rel AptRelation) bool {
+		if exclude[rel.Name] {
+			return true
+		}
+		if version, ok := opts.Installed[rel.Name]; ok && rel.satisfiedBy(version) {
+			return true
+		}
+		if pkg := selected[rel.Name]; pkg != nil && rel.satisfiedBy(pkg.Version) {
+			return true
+		}
+		// Virtual package yang disediakan package terpilih
+		for _, pkg := range selected {
+			for _, p := range pkg.Provides {
+				if p.Name == rel.Name && (rel.Op == "" || (p.Op == "=" && rel.satisfiedBy(p.Version))) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	// choose memilih kandidat untuk rel yang dibutuhkan requiredBy. Versi
+	// yang berbeda dari package yang sudah dipilih adalah konflik, bukan
+	// pengganti.
+	choose := func(requiredBy string, rel AptRelation) error {
+		pkg := idx.candidate(rel)
+		if pkg == nil {
+			return &UnsatisfiableError{Package: requiredBy, Relation: rel.String()}
+		}
+		if !opts.IncludeRequired && (pkg.Essential || pkg.Priority == "required") {
+			return nil
+		}
+		if exclude[pkg.Name] {
+			return nil
+		}
+		if _, ok := opts.Installed[pkg.Name]; ok && rel.Op == "" {
+			return nil
+		}
+		if prev := selected[pkg.Name]; prev != nil {
+			if prev.Version != pkg.Version {
+				return &VersionConflictError{Package: requiredBy, Relation: rel.String(), Selected: prev.Name + "=" + prev.Version}
+			}
+			return nil
+		}
+		selected[pkg.Name] = pkg
+		queue = append(queue, pkg)
+		return nil
+	}
+
+	// resolveName memilih package untuk name beserta dependency transitifnya
+	resolveName := func(name string) error {
+		queue = queue[:0]
+		if err := choose("", AptRelation{Name: name}); err != nil {
+			return err
+		}
+		for len(queue) > 0 {
+			pkg := queue[0]
+			queue = queue[1:]
+			for _, alternatives := range pkg.Depends {
+				done := false
+				for _, rel := range alternatives {
+					if satisfied(rel) {
+						done = true
+						break
+					}
+				}
+				var conflict error
+				for _, rel := range alternatives {
+					if done {
+						break
+					}
+					err := choose(pkg.Name, rel)
+					var versionErr *VersionConflictError
+					if errors.As(err, &versionErr) && conflict == nil {
+						conflict = err
+					}
+					done = err == nil
+				}
+				if done {
+					continue
+				}
+				if conflict != nil {
+					return conflict
+				}
+				return &UnsatisfiableError{Package: pkg.Name, Relation: formatAlternatives(alternatives)}
+			}
+		}
+		return nil
+	}
+
+	var failures []ResolveFailure
+	for _, name := range names {
+		// Nama yang sudah terinstall (atau disediakan package terpilih) tidak
+		// perlu dicari di index
+		if satisfied(AptRelation{Name: name}) {
+			continue
+		}
+		before := maps.Clone(selected)
+		if err := resolveName(name); err != nil {
+			selected = before
+			failures = append(failures, ResolveFailure{Name: name, Err: err})
+		}
+	}
+
+	result := make([]*AptPackage, 0, len(selected))
+	for _, pkg := range selected {
+		result = append(result, pkg)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	if len(failures) > 0 {
+		return result, &ResolveError{Failures: failures}
+	}
+	return result, nil
+}
+
+// candidate mengembalikan package dengan versi tertinggi yang memenuhi rel,
+// atau package yang menyediakan rel sebagai virtual package
+func (idx *PackageIndex) candidate(rel AptRelation) *AptPackage {
+	var best *AptPackage
+	for _, pkg := range idx.packages[rel.Name] {
+		if rel.satisfiedBy(pkg.Version) && (best == nil || CompareDebVersions(pkg.Version, best.Version) > 0) {
+			best = pkg
+		}
+	}
+	if best != nil {
+		return best
+	}
+	for _, pkg := range idx.provides[rel.Name] {
+		for _, p := range pkg.Provides {
+			if p.Name != rel.Name {
+				continue
+			}
+			if rel.Op == "" || (p.Op == "=" && rel.satisfiedBy(p.Version)) {
+				return pkg
+			}
+		}
+	}
+	return nil
+}
+
+// stripAptRestrictions membuang blok "[...]" dan "<...>" di luar tanda kurung
+// versi, contoh "foo (>= 1) [amd64] <!nocheck>" menjadi "foo (>= 1)"
+func stripAptRestrictions(value string) string {
+	var b strings.Builder
+	inParen := false
+	var skipUntil byte
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case skipUntil != 0:
+			if c == skipUntil {
+				skipUntil = 0
+			}
+			continue
+		case c == '(':
+			inParen = true
+		case c == ')':
+			inParen = false
+		case !inParen && c == '[':
+			skipUntil = ']'
+			continue
+		case !inParen && c == '<':
+			skipUntil = '>'
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// formatAlternatives menulis grup alternatif seperti di field Depends
+func formatAlternatives(alternatives []AptRelation) string {
+	parts := make([]string, len(alternatives))
+	for i, rel := range alternatives {
+		parts[i] = rel.String()
+	}
+	return strings.Join(parts, " | ")
+}
+
+// installedPackages mengembalikan package berstatus "installed" dari index
+// file status dpkg (nama ke versi)
+func (idx *PackageIndex) installedPackages() map[string]string {
+	installed := map[string]string{}
+	for name, pkgs := range idx.packages {
+		for _, pkg := range pkgs {
+			if strings.HasSuffix(pkg.Status, " installed") {
+				installed[name] = pkg.Version
+			}
+		}
+	}
+	return installed
+}
+
+// CompareDebVersions membandingkan dua versi Debian ([epoch:]upstream[-revision])
+// dengan algoritma dpkg. Hasil negatif jika a < b, nol jika sama, positif jika a > b.
+func CompareDebVersions(a, b string) int {
+	epochA, upstreamA, revisionA := splitDebVersion(a)
+	epochB, upstreamB, revisionB := splitDebVersion(b)
+	if epochA != epochB {
+		if epochA < epochB {
+			return -1
+		}
+		return 1
+	}
+	if c := compareDebPart(upstreamA, upstreamB); c != 0 {
+		return c
+	}
+	return compareDebPart(revisionA, revisionB)
+}
+
+// splitDebVersion memecah versi menjadi epoch, upstream dan revision
+func splitDebVersion(version string) (int, string, string) {
+	epoch := 0
+	if i := strings.IndexByte(version, ':'); i >= 0 {
+		if n, err := strconv.Atoi(version[:i]); err == nil {
+			epoch = n
+			version = version[i+1:]
+		}
+	}
+	revision := ""
+	if i := strings.LastIndexByte(version, '-'); i >= 0 {
+		revision = version[i+1:]
+		version = version[:i]
+	}
+	return epoch, version, revision
+}
+
+// compareDebPart membandingkan upstream atau revision seperti verrevcmp dpkg:
+// bagian non-digit dibandingkan per karakter ("~" paling kecil, huruf sebelum
+// simbol), bagian digit dibandingkan sebagai angka
+func compareDebPart(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			ac, bc := debOrder(a, i), debOrder(b, j)
+			if ac != bc {
+				return ac - bc
+			}
+			i++
+			j++
+		}
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+	return 0
+}
+
+// debOrder adalah bobot karakter untuk compareDebPart; posisi di luar string bernilai 0
+func debOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := s[i]
+	switch {
+	case isDigit(c):
+		return 0
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return int(c)
+	case c == '~':
+		return -1
+	}
+	return int(c) + 256
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
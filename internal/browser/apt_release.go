@@ -0,0 +1,176 @@
+package browser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// releaseLimit membatasi ukuran file Release/InRelease yang dibaca
+const releaseLimit = 16 << 20
+
+// releaseFile adalah satu entry di bagian SHA256 file Release
+type releaseFile struct {
+	SHA256 string
+	Size   int64
+}
+
+// SignatureError dikembalikan jika tanda tangan InRelease tidak dapat
+// diverifikasi dengan AptSource.Keyring
+type SignatureError struct {
+	URL     string
+	Keyring string
+	Output  string
+}
+
+func (e *SignatureError) Error() string {
+	return fmt.Sprintf("signature of %s not verified with %s: %s", e.URL, e.Keyring, e.Output)
+}
+
+// fetchRelease mengunduh daftar SHA256 index untuk suite. Jika Keyring
+// diset, InRelease diunduh dan tanda tangannya diverifikasi dengan gpgv;
+// jika tidak, Release diunduh tanpa verifikasi tanda tangan.
+func (dm *DependencyManager) fetchRelease(ctx context.Context, source AptSource) (map[string]releaseFile, error) {
+	dir := fmt.Sprintf("%s/dists/%s/", source.BaseURL, source.Suite)
+	if source.Keyring == "" {
+		data, err := dm.fetchRepoFile(ctx, dir+"Release", releaseLimit)
+		if err != nil {
+			return nil, err
+		}
+		return parseRelease(bytes.NewReader(data))
+	}
+
+	url := dir + "InRelease"
+	data, err := dm.fetchRepoFile(ctx, url, releaseLimit)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := dm.verifyInRelease(ctx, url, source.Keyring, data)
+	if err != nil {
+		return nil, err
+	}
+	dm.logger("dependencies_apt_release_verified", url)
+	return parseRelease(bytes.NewReader(signed))
+}
+
+// verifyInRelease memverifikasi InRelease dengan gpgv dan mengembalikan isi
+// yang ditandatangani. Hanya isi dari gpgv yang dipakai agar teks di luar
+// blok bertanda tangan tidak ikut dibaca.
+func (dm *DependencyManager) verifyInRelease(ctx context.Context, url, keyring string, data []byte) ([]byte, error) {
+	if _, err := os.Stat(keyring); err != nil {
+		return nil, fmt.Errorf("apt keyring: %w", err)
+	}
+	gpgv := dm.GpgvPath
+	if gpgv == "" {
+		gpgv = "gpgv"
+	}
+
+	tmp, err := os.CreateTemp("", "InRelease-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, gpgv, "--keyring", keyring, "--output", "-", tmp.Name())
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, &SignatureError{URL: url, Keyring: keyring, Output: strings.TrimSpace(stderr.String())}
+		}
+		return nil, fmt.Errorf("failed to run gpgv: %w", err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// fetchRepoFile mengunduh file metadata repository (APT, RPM, APK), maksimal
+// limit byte
+func (dm *DependencyManager) fetchRepoFile(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := dm.downloader.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: status %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", url, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s: larger than %d bytes", url, limit)
+	}
+	return data, nil
+}
+
+// parseRelease membaca bagian SHA256 file Release ("hash size path" per
+// baris), dengan key path relatif terhadap dists/<suite>/
+func parseRelease(r io.Reader) (map[string]releaseFile, error) {
+	files := map[string]releaseFile{}
+	scanner := bufio.NewScanner(r)
+	inSHA256 := false
+	for scanner.Scan() {
+		text := scanner.Text()
+		if text == "" || (text[0] != ' ' && text[0] != '\t') {
+			name, _, _ := strings.Cut(text, ":")
+			inSHA256 = strings.EqualFold(strings.TrimSpace(name), "SHA256")
+			continue
+		}
+		if !inSHA256 {
+			continue
+		}
+		parts := strings.Fields(text)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid SHA256 entry %q", strings.TrimSpace(text))
+		}
+		size, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || len(parts[0]) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid SHA256 entry %q", strings.TrimSpace(text))
+		}
+		files[parts[2]] = releaseFile{SHA256: strings.ToLower(parts[0]), Size: size}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read release file: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("release file has no SHA256 entries")
+	}
+	return files, nil
+}
+
+// verifyReleaseFile membandingkan data dengan ukuran dan SHA256 dari Release
+func verifyReleaseFile(url string, data []byte, want releaseFile) error {
+	sum := sha256.Sum256(data)
+	actual := hex.EncodeToString(sum[:])
+	if int64(len(data)) != want.Size || actual != want.SHA256 {
+		return &ChecksumMismatchError{URL: url, Expected: want.SHA256, Actual: actual}
+	}
+	return nil
+}
@@ -0,0 +1,549 @@
+package browser_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ulikunitz/xz"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// buildAr menulis arsip ar (format .deb) dengan member sesuai urutan
+func buildAr(members ...[2]string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("!<arch>\n")
+	for _, m := range members {
+		fmt.Fprintf(&buf, "%-16s%-12s%-6s%-6s%-8s%-10d`\n", m[0], "0", "0", "0", "100644", len(m[1]))
+		buf.WriteString(m[1])
+		if len(m[1])%2 == 1 {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+// buildControlTar membuat control.tar.gz berisi file control untuk name dan version
+func buildControlTar(t *testing.T, name, version string) []byte {
+	t.Helper()
+	control := fmt.Sprintf("Package: %s\nVersion: %s\nArchitecture: amd64\nDescription: test package\n", name, version)
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	tw.WriteHeader(&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0o755})
+	tw.WriteHeader(&tar.Header{Name: "./control", Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(control))})
+	tw.Write([]byte(control))
+	tw.Close()
+	gw.Close()
+	return buf.Bytes()
+}
+
+// buildDeb membuat .deb berisi file library (isi "payload") di usr/lib
+func buildDeb(t *testing.T, name, version string, libs ...string) []byte {
+	t.Helper()
+	control := buildControlTar(t, name, version)
+	headers := []*tar.Header{{Name: "./usr/lib/x86_64-linux-gnu/", Typeflag: tar.TypeDir}}
+	for _, lib := range libs {
+		headers = append(headers, &tar.Header{Name: "./usr/lib/x86_64-linux-gnu/" + lib, Typeflag: tar.TypeReg})
+	}
+	data := buildTarXz(t, headers)
+	return buildAr(
+		[2]string{"debian-binary", "2.0\n"},
+		[2]string{"control.tar.gz", string(control)},
+		[2]string{"data.tar.xz", string(data)},
+	)
+}
+
+// aptRepo adalah repository APT palsu: Release dan index Packages.xz untuk
+// suite "testing" serta file .deb di pool/
+type aptRepo struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	files    map[string][]byte
+	requests map[string]int
+}
+
+const (
+	aptReleasePath  = "/dists/testing/Release"
+	aptPackagesPath = "/dists/testing/main/binary-amd64/Packages.xz"
+)
+
+// aptFixturePackage adalah satu package di aptRepo
+type aptFixturePackage struct {
+	name, version, depends, provides, priority string
+	libs                                       []string
+	// badSHA menulis SHA256 yang salah di index
+	badSHA bool
+}
+
+func newAptRepo(t *testing.T, pkgs []aptFixturePackage) *aptRepo {
+	t.Helper()
+	repo := &aptRepo{files: map[string][]byte{}, requests: map[string]int{}}
+
+	var index strings.Builder
+	for _, p := range pkgs {
+		deb := buildDeb(t, p.name, p.version, p.libs...)
+		// Nama file di pool tidak memuat epoch
+		_, version, ok := strings.Cut(p.version, ":")
+		if !ok {
+			version = p.version
+		}
+		filename := fmt.Sprintf("pool/main/%s_%s_amd64.deb", p.name, version)
+		repo.files["/"+filename] = deb
+		sum := sha256.Sum256(deb)
+		digest := hex.EncodeToString(sum[:])
+		if p.badSHA {
+			digest = strings.Repeat("0", 64)
+		}
+		fmt.Fprintf(&index, "Package: %s\nVersion: %s\nArchitecture: amd64\n", p.name, p.version)
+		if p.priority != "" {
+			fmt.Fprintf(&index, "Priority: %s\n", p.priority)
+		}
+		if p.depends != "" {
+			fmt.Fprintf(&index, "Depends: %s\n", p.depends)
+		}
+		if p.provides != "" {
+			fmt.Fprintf(&index, "Provides: %s\n", p.provides)
+		}
+		fmt.Fprintf(&index, "Description: test package\n multi-line description\nFilename: %s\nSize: %d\nSHA256: %s\n\n", filename, len(deb), digest)
+	}
+
+	var packagesXz bytes.Buffer
+	xw, err := xz.NewWriter(&packagesXz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xw.Write([]byte(index.String()))
+	xw.Close()
+	repo.files[aptPackagesPath] = packagesXz.Bytes()
+	sum := sha256.Sum256(packagesXz.Bytes())
+	repo.files[aptReleasePath] = []byte(fmt.Sprintf("Suite: testing\nSHA256:\n %x %d main/binary-amd64/Packages.xz\n", sum, packagesXz.Len()))
+
+	repo.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		repo.mu.Lock()
+		repo.requests[r.URL.Path]++
+		data, ok := repo.files[r.URL.Path]
+		repo.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(repo.srv.Close)
+	return repo
+}
+
+func (r *aptRepo) source() browser.AptSource {
+	return browser.AptSource{BaseURL: r.srv.URL, Suite: "testing", Arch: "amd64", Exclude: []string{"libc6"}}
+}
+
+func (r *aptRepo) debRequests() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.debRequestsLocked()
+}
+
+func (r *aptRepo) debRequestsLocked() int {
+	n := 0
+	for path, count := range r.requests {
+		if strings.HasSuffix(path, ".deb") {
+			n += count
+		}
+	}
+	return n
+}
+
+// newAptDependencyManager membuat DependencyManager untuk host Debian dengan
+// repo sebagai sumber APT dan file status dpkg berisi status
+func newAptDependencyManager(t *testing.T, repo *aptRepo, status string) (*browser.DependencyManager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	statusFile := filepath.Join(dir, "status")
+	if err := os.WriteFile(statusFile, []byte(status), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	libDir := filepath.Join(dir, "libs")
+	dm := browser.NewDependencyManager(libDir, nil)
+	dm.AptSources = map[string]browser.AptSource{"debian": repo.source()}
+	dm.StatusFile = statusFile
+	dm.OSReleaseFile = writeOSRelease(t, "ID=debian\nVERSION_CODENAME=bookworm\n")
+	return dm, libDir
+}
+
+var chromeDepsFixture = []aptFixturePackage{
+	{name: "libnss3", version: "2:3.87-1", depends: "libc6 (>= 2.34), libnspr4 (>= 2:4.34), libsqlite3-0 (>= 3.5.9)", libs: []string{"libnss3.so"}},
+	{name: "libnspr4", version: "2:4.33-1", libs: []string{"libnspr4.so.old"}},
+	{name: "libnspr4", version: "2:4.35-1", depends: "libc6:any (>= 2.14)", libs: []string{"libnspr4.so"}},
+	{name: "libsqlite3-0", version: "3.40.1-2", libs: []string{"libsqlite3.so.0"}},
+	{name: "libatk1.0-0", version: "2.46.0-5", depends: "libglib2.0-0 (>= 2.55.2) | libglib-compat, atk-data-virtual [amd64]", libs: []string{"libatk-1.0.so.0"}},
+	{name: "libglib2.0-0", version: "2.74.6-2", depends: "libc6 (>= 2.34), zlib1g", libs: []string{"libglib-2.0.so.0"}},
+	{name: "libatk-data", version: "2.46.0-5", provides: "atk-data-virtual"},
+	{name: "zlib1g", version: "1:1.2.13-1", priority: "required", libs: []string{"libz.so.1"}},
+	{name: "libc6", version: "2.36-9", priority: "required", libs: []string{"libc.so.6"}},
+}
+
+// Test resolusi transitif: versi tertinggi yang memenuhi, alternatif,
+// virtual package, package required/Exclude/terinstall dilewati
+func TestPackageIndexResolve(t *testing.T) {
+	var index strings.Builder
+	for _, p := range chromeDepsFixture {
+		fmt.Fprintf(&index, "Package: %s\nVersion: %s\n", p.name, p.version)
+		if p.priority != "" {
+			fmt.Fprintf(&index, "Priority: %s\n", p.priority)
+		}
+		if p.depends != "" {
+			fmt.Fprintf(&index, "Depends: %s\n", p.depends)
+		}
+		if p.provides != "" {
+			fmt.Fprintf(&index, "Provides: %s\n", p.provides)
+		}
+		index.WriteString("\n")
+	}
+	idx, err := browser.ParsePackageIndex(strings.NewReader(index.String()))
+	if err != nil {
+		t.Fatalf("ParsePackageIndex: %v", err)
+	}
+	if idx.Len() != len(chromeDepsFixture) {
+		t.Fatalf("Len = %d, want %d", idx.Len(), len(chromeDepsFixture))
+	}
+
+	pkgs, err := idx.Resolve([]string{"libnss3", "libatk1.0-0"}, browser.ResolveOptions{
+		Installed: map[string]string{"libsqlite3-0": "3.40.1-2"},
+		Exclude:   []string{"libc6"},
+	})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	var got []string
+	for _, pkg := range pkgs {
+		got = append(got, pkg.Name+"="+pkg.Version)
+	}
+	want := "libatk-data=2.46.0-5 libatk1.0-0=2.46.0-5 libglib2.0-0=2.74.6-2 libnspr4=2:4.35-1 libnss3=2:3.87-1"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Resolve = %s\nwant      %s", strings.Join(got, " "), want)
+	}
+
+	// Versi terinstall yang terlalu lama tidak memenuhi batasan
+	pkgs, err = idx.Resolve([]string{"libnss3"}, browser.ResolveOptions{
+		Installed: map[string]string{"libsqlite3-0": "3.5.8-1", "libnspr4": "2:4.35-1"},
+		Exclude:   []string{"libc6"},
+	})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(pkgs) != 2 || pkgs[0].Name != "libnss3" || pkgs[1].Name != "libsqlite3-0" {
+		t.Errorf("Resolve with old sqlite = %v", pkgs)
+	}
+
+	var unsat *browser.UnsatisfiableError
+	if _, err := idx.Resolve([]string{"libmissing"}, browser.ResolveOptions{}); !errors.As(err, &unsat) {
+		t.Errorf("expected UnsatisfiableError, got %v", err)
+	}
+
+	// Nama yang tidak ada di index tidak menggagalkan nama lain; nama yang
+	// sudah terinstall tidak dicari di index
+	pkgs, err = idx.Resolve([]string{"libmissing", "libnspr4", "libinstalled"}, browser.ResolveOptions{
+		Installed: map[string]string{"libinstalled": "1.0-1"},
+		Exclude:   []string{"libc6"},
+	})
+	var resolveErr *browser.ResolveError
+	if !errors.As(err, &resolveErr) || len(resolveErr.Failures) != 1 || resolveErr.Failures[0].Name != "libmissing" {
+		t.Fatalf("expected only libmissing to fail, got %v", err)
+	}
+	if missing := resolveErr.Missing(); len(missing) != 1 || missing[0] != "libmissing" {
+		t.Errorf("Missing = %v, want [libmissing]", missing)
+	}
+	if len(pkgs) != 1 || pkgs[0].Name != "libnspr4" {
+		t.Errorf("Resolve with missing name = %v", pkgs)
+	}
+}
+
+// Test dua nama yang membutuhkan versi berbeda dari package yang sama
+// dilaporkan sebagai konflik, bukan saling menimpa
+func TestResolveVersionConflict(t *testing.T) {
+	idx, err := browser.ParsePackageIndex(strings.NewReader(`Package: libfoo
+Version: 1.0-1
+
+Package: libfoo
+Version: 2.0-1
+
+Package: liba
+Version: 1
+Depends: libfoo (>= 2.0)
+
+Package: libb
+Version: 1
+Depends: libfoo (<< 2.0)
+`))
+	if err != nil {
+		t.Fatalf("ParsePackageIndex: %v", err)
+	}
+
+	pkgs, err := idx.Resolve([]string{"liba", "libb"}, browser.ResolveOptions{})
+	var conflict *browser.VersionConflictError
+	if !errors.As(err, &conflict) || conflict.Package != "libb" || conflict.Selected != "libfoo=2.0-1" {
+		t.Fatalf("expected conflict for libb, got %v", err)
+	}
+	var resolveErr *browser.ResolveError
+	if !errors.As(err, &resolveErr) || len(resolveErr.Failures) != 1 || resolveErr.Failures[0].Name != "libb" {
+		t.Errorf("expected only libb to fail, got %v", err)
+	}
+	if len(resolveErr.Missing()) != 0 {
+		t.Errorf("Missing = %v, want none", resolveErr.Missing())
+	}
+	var got []string
+	for _, pkg := range pkgs {
+		got = append(got, pkg.Name+"="+pkg.Version)
+	}
+	if strings.Join(got, " ") != "liba=1 libfoo=2.0-1" {
+		t.Errorf("Resolve = %v", got)
+	}
+}
+
+// Test urutan versi Debian sesuai dpkg
+func TestCompareDebVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0-1", "1.0-2", -1},
+		{"1:1.0", "2.0", 1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~rc1", "1.0~~", 1},
+		{"1.0a", "1.0", 1},
+		{"1.0+dfsg", "1.0a", 1},
+		{"2.4.104-1", "2.4.113-2~ubuntu0.22.04.1", -1},
+		{"3.98-0ubuntu0.22.04.2", "3.98-0ubuntu0.22.04.10", -1},
+		{"007", "7", 0},
+	}
+	for _, tt := range tests {
+		got := browser.CompareDebVersions(tt.a, tt.b)
+		if (got < 0 && tt.want >= 0) || (got > 0 && tt.want <= 0) || (got == 0 && tt.want != 0) {
+			t.Errorf("CompareDebVersions(%q, %q) = %d, want sign %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// Test DependencyManager mengunduh dari repo APT, memverifikasi SHA256,
+// mengekstrak dan tidak mengunduh ulang pada Setup berikutnya
+func TestDependencyManagerSetupFromApt(t *testing.T) {
+	repo := newAptRepo(t, chromeDepsFixture)
+	status := "Package: libsqlite3-0\nStatus: install ok installed\nVersion: 3.40.1-2\n\n" +
+		"Package: libglib2.0-0\nStatus: deinstall ok config-files\nVersion: 2.74.6-2\n"
+	dm, libDir := newAptDependencyManager(t, repo, status)
+
+	deps := []browser.Dependency{
+		{Name: "libnss3", LibraryName: "libnss3.so"},
+		{Name: "libatk1.0-0", LibraryName: "libatk-1.0.so"},
+	}
+	if err := dm.SetupContext(context.Background(), deps); err != nil {
+		t.Fatalf("SetupContext: %v", err)
+	}
+
+	libs := filepath.Join(libDir, "usr", "lib", "x86_64-linux-gnu")
+	for _, lib := range []string{"libnss3.so", "libnspr4.so", "libatk-1.0.so.0", "libglib-2.0.so.0"} {
+		if _, err := os.Stat(filepath.Join(libs, lib)); err != nil {
+			t.Errorf("%s not extracted: %v", lib, err)
+		}
+	}
+	for _, lib := range []string{"libsqlite3.so.0", "libc.so.6", "libz.so.1", "libnspr4.so.old"} {
+		if _, err := os.Stat(filepath.Join(libs, lib)); err == nil {
+			t.Errorf("%s should not be extracted", lib)
+		}
+	}
+	if n := repo.debRequests(); n != 5 {
+		t.Errorf("downloaded %d debs, want 5", n)
+	}
+
+	// Library root sudah ada, package transitif tercatat: tidak ada download baru
+	if err := dm.SetupContext(context.Background(), deps); err != nil {
+		t.Fatalf("second SetupContext: %v", err)
+	}
+	if n := repo.debRequests(); n != 5 {
+		t.Errorf("second setup downloaded again: %d requests", n)
+	}
+}
+
+// Test dependency yang tidak ada di rilis (misalnya libffi7 di bookworm)
+// diambil dari URL-nya, sedangkan dependency lain tetap dari index
+func TestDependencyManagerAptFallsBackForMissingName(t *testing.T) {
+	repo := newAptRepo(t, []aptFixturePackage{
+		{name: "libnss3", version: "2:3.87-1", libs: []string{"libnss3.so"}},
+	})
+	repo.files["/legacy/libffi7.deb"] = buildDeb(t, "libffi7", "3.3-6", "libffi.so.7")
+	dm, libDir := newAptDependencyManager(t, repo, "")
+
+	err := dm.SetupContext(context.Background(), []browser.Dependency{
+		{Name: "libnss3", LibraryName: "libnss3.so", DebianURL: repo.srv.URL + "/legacy/libnss3.deb"},
+		{Name: "libffi7", LibraryName: "libffi.so.7", DebianURL: repo.srv.URL + "/legacy/libffi7.deb"},
+	})
+	if err != nil {
+		t.Fatalf("SetupContext: %v", err)
+	}
+	libs := filepath.Join(libDir, "usr", "lib", "x86_64-linux-gnu")
+	if _, err := os.Stat(filepath.Join(libs, "libnss3.so")); err != nil {
+		t.Errorf("libnss3 should be installed from the index: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(libs, "libffi.so.7")); err != nil {
+		t.Errorf("libffi7 should be installed from its url: %v", err)
+	}
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if n := repo.requests["/legacy/libnss3.deb"]; n != 0 {
+		t.Errorf("libnss3 url requested %d times, want only the pool", n)
+	}
+	if n := repo.requests["/legacy/libffi7.deb"]; n != 1 {
+		t.Errorf("libffi7 url requested %d times, want 1", n)
+	}
+}
+
+// Test .deb yang tidak cocok dengan SHA256 di index ditolak tanpa fallback
+func TestDependencyManagerAptChecksumMismatch(t *testing.T) {
+	repo := newAptRepo(t, []aptFixturePackage{
+		{name: "libnss3", version: "2:3.87-1", libs: []string{"libnss3.so"}, badSHA: true},
+	})
+	dm, libDir := newAptDependencyManager(t, repo, "")
+
+	err := dm.SetupContext(context.Background(), []browser.Dependency{
+		{Name: "libnss3", LibraryName: "libnss3.so", DebianURL: repo.srv.URL + "/pool/main/libnss3_3.87-1_amd64.deb"},
+	})
+	var mismatch *browser.ChecksumMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected ChecksumMismatchError, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(libDir, "usr", "lib", "x86_64-linux-gnu", "libnss3.so")); err == nil {
+		t.Error("package with bad checksum must not be extracted")
+	}
+}
+
+// Test index yang tidak cocok dengan SHA256 di Release ditolak sebelum ada
+// .deb yang diunduh
+func TestDependencyManagerAptIndexMismatch(t *testing.T) {
+	repo := newAptRepo(t, []aptFixturePackage{
+		{name: "libnss3", version: "2:3.87-1", libs: []string{"libnss3.so"}},
+	})
+	repo.files[aptPackagesPath] = append(repo.files[aptPackagesPath], 0)
+	dm, _ := newAptDependencyManager(t, repo, "")
+
+	err := dm.SetupContext(context.Background(), []browser.Dependency{
+		{Name: "libnss3", LibraryName: "libnss3.so", DebianURL: repo.srv.URL + "/legacy/libnss3.deb"},
+	})
+	var mismatch *browser.ChecksumMismatchError
+	if !errors.As(err, &mismatch) || !strings.HasSuffix(mismatch.URL, aptPackagesPath) {
+		t.Fatalf("expected ChecksumMismatchError for Packages.xz, got %v", err)
+	}
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if n := repo.requests["/legacy/libnss3.deb"]; n != 0 || repo.debRequestsLocked() != 0 {
+		t.Errorf("tampered index must not lead to downloads")
+	}
+}
+
+// newGPGKey membuat kunci penandatangan di GNUPGHOME sementara dan
+// mengembalikan GNUPGHOME beserta keyring biner berisi public key-nya
+func newGPGKey(t *testing.T) (home, keyring string) {
+	t.Helper()
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not available")
+	}
+	if _, err := exec.LookPath("gpgv"); err != nil {
+		t.Skip("gpgv not available")
+	}
+	dir := t.TempDir()
+	home = filepath.Join(dir, "gnupg")
+	if err := os.Mkdir(home, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd := exec.Command("gpgconf", "--kill", "gpg-agent")
+		cmd.Env = append(os.Environ(), "GNUPGHOME="+home)
+		cmd.Run()
+	})
+	keyring = filepath.Join(dir, "keyring.gpg")
+	runGPG(t, home, "--quick-gen-key", "Test Repo <repo@example.invalid>", "ed25519", "sign", "never")
+	runGPG(t, home, "--export", "-o", keyring)
+	return home, keyring
+}
+
+func runGPG(t *testing.T, home string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("gpg", append([]string{"--batch", "--yes", "--passphrase", ""}, args...)...)
+	cmd.Env = append(os.Environ(), "GNUPGHOME="+home)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("gpg %v: %v\n%s", args, err, out)
+	}
+}
+
+// signRelease menulis InRelease (Release yang di-clearsign dengan kunci di
+// home) ke repo
+func (r *aptRepo) signRelease(t *testing.T, home string) {
+	t.Helper()
+	dir := t.TempDir()
+	release := filepath.Join(dir, "Release")
+	if err := os.WriteFile(release, r.files[aptReleasePath], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	inRelease := filepath.Join(dir, "InRelease")
+	runGPG(t, home, "--clearsign", "-o", inRelease, release)
+	data, err := os.ReadFile(inRelease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.files["/dists/testing/InRelease"] = data
+}
+
+// Test InRelease diverifikasi dengan Keyring: tanda tangan yang benar
+// diterima, kunci lain ditolak tanpa fallback ke URL dependency
+func TestDependencyManagerAptSignedRelease(t *testing.T) {
+	home, keyring := newGPGKey(t)
+	_, otherKeyring := newGPGKey(t)
+	deps := []browser.Dependency{
+		{Name: "libnss3", LibraryName: "libnss3.so", DebianURL: "http://127.0.0.1:1/legacy/libnss3.deb"},
+	}
+
+	repo := newAptRepo(t, []aptFixturePackage{
+		{name: "libnss3", version: "2:3.87-1", libs: []string{"libnss3.so"}},
+	})
+	repo.signRelease(t, home)
+
+	dm, _ := newAptDependencyManager(t, repo, "")
+	source := repo.source()
+	source.Keyring = otherKeyring
+	dm.AptSources = map[string]browser.AptSource{"debian": source}
+	var signature *browser.SignatureError
+	if err := dm.SetupContext(context.Background(), deps); !errors.As(err, &signature) {
+		t.Fatalf("expected SignatureError, got %v", err)
+	}
+
+	dm, libDir := newAptDependencyManager(t, repo, "")
+	source.Keyring = keyring
+	dm.AptSources = map[string]browser.AptSource{"debian": source}
+	if err := dm.SetupContext(context.Background(), deps); err != nil {
+		t.Fatalf("SetupContext: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(libDir, "usr", "lib", "x86_64-linux-gnu", "libnss3.so")); err != nil {
+		t.Errorf("libnss3 not extracted: %v", err)
+	}
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if n := repo.requests[aptReleasePath]; n != 0 {
+		t.Errorf("unsigned Release requested %d times with a keyring", n)
+	}
+}
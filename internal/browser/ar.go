@@ -0,0 +1,238 @@
+package browser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// arMagic adalah signature di awal setiap arsip ar (termasuk .deb)
+const arMagic = "!<arch>\n"
+
+// arHeaderSize adalah ukuran header member ar; header diakhiri "`\n"
+const arHeaderSize = 60
+
+// arNameLimit membatasi ukuran tabel nama panjang GNU dan nama BSD yang
+// dimuat ke memori (Size di header bisa sampai ~10 GB)
+const arNameLimit = 1 << 20
+
+// ErrArHeader dikembalikan jika magic atau header member ar tidak valid
+var ErrArHeader = errors.New("ar: invalid header")
+
+// ArHeader adalah metadata satu member arsip ar
+type ArHeader struct {
+	Name    string
+	ModTime time.Time
+	UID     int
+	GID     int
+	Mode    int64
+	Size    int64
+}
+
+// ArReader membaca arsip ar secara streaming, mirip archive/tar.Reader:
+// Next pindah ke member berikutnya dan Read membaca isi member saat ini.
+// Nama panjang GNU (tabel "//") dan BSD ("#1/<n>") didukung; tabel simbol
+// ("/" dan "/SYM64/") dilewati.
+type ArReader struct {
+	r io.Reader
+	// remaining adalah sisa byte isi member saat ini, pad byte padding
+	// setelahnya (member selalu rata 2 byte)
+	remaining int64
+	pad       int64
+	// longNames adalah isi tabel nama panjang GNU ("//")
+	longNames []byte
+	err       error
+}
+
+// NewArReader membuat ArReader dan memvalidasi magic "!<arch>\n"
+func NewArReader(r io.Reader) (*ArReader, error) {
+	magic := make([]byte, len(arMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: missing magic", ErrArHeader)
+		}
+		return nil, err
+	}
+	if string(magic) != arMagic {
+		return nil, fmt.Errorf("%w: bad magic %q", ErrArHeader, magic)
+	}
+	return &ArReader{r: r}, nil
+}
+
+// Next melewati sisa member saat ini dan mengembalikan header member
+// berikutnya. io.EOF dikembalikan jika arsip sudah habis.
+func (ar *ArReader) Next() (*ArHeader, error) {
+	if ar.err != nil {
+		return nil, ar.err
+	}
+	for {
+		hdr, err := ar.next()
+		if err != nil {
+			ar.err = err
+			return nil, err
+		}
+		if hdr != nil {
+			return hdr, nil
+		}
+	}
+}
+
+// next membaca satu header; nil tanpa error berarti member internal
+// (tabel simbol atau tabel nama panjang) yang sudah dilewati
+func (ar *ArReader) next() (*ArHeader, error) {
+	if err := ar.skip(ar.remaining + ar.pad); err != nil {
+		return nil, err
+	}
+	ar.remaining, ar.pad = 0, 0
+
+	buf := make([]byte, arHeaderSize)
+	if _, err := io.ReadFull(ar.r, buf); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: truncated header", ErrArHeader)
+		}
+		return nil, err
+	}
+	if string(buf[58:60]) != "`\n" {
+		return nil, fmt.Errorf("%w: bad terminator %q", ErrArHeader, buf[58:60])
+	}
+
+	hdr := &ArHeader{}
+	var err error
+	field := func(name string, b []byte, base int) int64 {
+		s := strings.TrimSpace(string(b))
+		if s == "" || err != nil {
+			return 0
+		}
+		v, perr := strconv.ParseInt(s, base, 64)
+		if perr != nil || v < 0 {
+			err = fmt.Errorf("%w: bad %s %q", ErrArHeader, name, s)
+		}
+		return v
+	}
+	mtime := field("mtime", buf[16:28], 10)
+	hdr.UID = int(field("uid", buf[28:34], 10))
+	hdr.GID = int(field("gid", buf[34:40], 10))
+	hdr.Mode = field("mode", buf[40:48], 8)
+	hdr.Size = field("size", buf[48:58], 10)
+	if err != nil {
+		return nil, err
+	}
+	hdr.ModTime = time.Unix(mtime, 0)
+	ar.remaining = hdr.Size
+	ar.pad = hdr.Size % 2
+
+	name := strings.TrimRight(string(buf[0:16]), " ")
+	switch {
+	case name == "/" || name == "/SYM64/" || name == "__.SYMDEF" || name == "__.SYMDEF SORTED":
+		return nil, nil
+	case name == "//":
+		if hdr.Size > arNameLimit {
+			return nil, fmt.Errorf("%w: long name table of %d bytes exceeds %d", ErrArHeader, hdr.Size, arNameLimit)
+		}
+		table := make([]byte, hdr.Size)
+		if _, err := io.ReadFull(ar, table); err != nil {
+			return nil, fmt.Errorf("%w: truncated long name table", ErrArHeader)
+		}
+		ar.longNames = table
+		return nil, nil
+	case strings.HasPrefix(name, "#1/"):
+		// BSD: nama disimpan di awal isi member dan termasuk dalam Size
+		n, perr := strconv.ParseInt(name[3:], 10, 64)
+		if perr != nil || n < 0 || n > hdr.Size || n > arNameLimit {
+			return nil, fmt.Errorf("%w: bad BSD name length %q", ErrArHeader, name)
+		}
+		longName := make([]byte, n)
+		if _, err := io.ReadFull(ar, longName); err != nil {
+			return nil, fmt.Errorf("%w: truncated BSD name", ErrArHeader)
+		}
+		hdr.Name = string(bytes.TrimRight(longName, "\x00"))
+		hdr.Size -= n
+	case len(name) > 1 && name[0] == '/':
+		// GNU: "/<offset>" menunjuk ke tabel nama panjang, nama diakhiri "/\n"
+		off, perr := strconv.Atoi(name[1:])
+		if perr != nil || off < 0 || off >= len(ar.longNames) {
+			return nil, fmt.Errorf("%w: bad GNU long name reference %q", ErrArHeader, name)
+		}
+		entry := ar.longNames[off:]
+		if end := bytes.IndexByte(entry, '\n'); end >= 0 {
+			entry = entry[:end]
+		}
+		hdr.Name = strings.TrimSuffix(string(entry), "/")
+	default:
+		// GNU mengakhiri nama pendek dengan "/"
+		hdr.Name = strings.TrimSuffix(name, "/")
+	}
+	return hdr, nil
+}
+
+// Read membaca isi member saat ini; io.EOF di akhir member
+func (ar *ArReader) Read(p []byte) (int, error) {
+	if ar.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > ar.remaining {
+		p = p[:ar.remaining]
+	}
+	n, err := ar.r.Read(p)
+	ar.remaining -= int64(n)
+	if errors.Is(err, io.EOF) && ar.remaining > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// skip membuang n byte dari reader dasar. Seek pada *os.File berhasil
+// walaupun melewati akhir file, jadi posisi akhir dibandingkan dengan ukuran
+// file agar member yang terpotong tetap terdeteksi.
+func (ar *ArReader) skip(n int64) error {
+	if n == 0 {
+		return nil
+	}
+	if seeker, ok := ar.r.(io.Seeker); ok {
+		if copied, ok, err := seekSkip(seeker, n); ok {
+			return ar.skipResult(copied, n, err)
+		}
+	}
+	copied, err := io.CopyN(io.Discard, ar.r, n)
+	return ar.skipResult(copied, n, err)
+}
+
+// seekSkip maju n byte dengan Seek tanpa melewati akhir data. ok false
+// berarti seeker tidak bisa dipakai dan skip harus membaca.
+func seekSkip(seeker io.Seeker, n int64) (skipped int64, ok bool, err error) {
+	cur, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false, nil
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, false, nil
+	}
+	target := min(cur+n, end)
+	if _, err := seeker.Seek(target, io.SeekStart); err != nil {
+		return 0, true, err
+	}
+	if target < cur+n {
+		return target - cur, true, io.EOF
+	}
+	return n, true, nil
+}
+
+// skipResult menerjemahkan hasil skip: copied dari n byte berhasil dibuang
+func (ar *ArReader) skipResult(copied, n int64, err error) error {
+	if copied == n {
+		return nil
+	}
+	// Padding member terakhir boleh tidak ada
+	if errors.Is(err, io.EOF) && copied == n-1 && ar.pad == 1 {
+		return io.EOF
+	}
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,200 @@
+package browser_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// arMember menulis satu header ar mentah (nama apa adanya) diikuti data dan padding
+func arMember(name, data string) string {
+	member := fmt.Sprintf("%-16s%-12d%-6d%-6d%-8s%-10d`\n", name, 1700000000, 1000, 1000, "100644", len(data)) + data
+	if len(data)%2 == 1 {
+		member += "\n"
+	}
+	return member
+}
+
+// readAllMembers membaca semua member dan mengembalikan "nama=isi"
+func readAllMembers(t *testing.T, r io.Reader) []string {
+	t.Helper()
+	ar, err := browser.NewArReader(r)
+	if err != nil {
+		t.Fatalf("NewArReader: %v", err)
+	}
+	var got []string
+	for {
+		hdr, err := ar.Next()
+		if err == io.EOF {
+			return got
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		data, err := io.ReadAll(ar)
+		if err != nil {
+			t.Fatalf("read %s: %v", hdr.Name, err)
+		}
+		if int64(len(data)) != hdr.Size {
+			t.Errorf("%s: read %d bytes, header size %d", hdr.Name, len(data), hdr.Size)
+		}
+		got = append(got, hdr.Name+"="+string(data))
+	}
+}
+
+// Test nama GNU ("name/", tabel "//"), nama BSD ("#1/n"), tabel simbol
+// dilewati dan padding member berukuran ganjil
+func TestArReaderNames(t *testing.T) {
+	gnuTable := "data.tar.xz-with-a-very-long-name/\ncontrol.tar.zst-long-name/\n"
+	archive := "!<arch>\n" +
+		arMember("/", "symbol-table") +
+		arMember("//", gnuTable) +
+		arMember("debian-binary/", "2.0\n") +
+		arMember("/35", "odd") +
+		arMember("/0", "even") +
+		arMember("#1/24", "bsd-long-member-name.txtbsd") +
+		arMember("short", "")
+
+	want := "debian-binary=2.0\n control.tar.zst-long-name=odd data.tar.xz-with-a-very-long-name=even bsd-long-member-name.txt=bsd short="
+	// Reader tanpa Seek dan reader yang hanya mengembalikan 1 byte per Read
+	for name, r := range map[string]io.Reader{
+		"bytes":    strings.NewReader(archive),
+		"one_byte": iotest.OneByteReader(strings.NewReader(archive)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := strings.Join(readAllMembers(t, r), " "); got != want {
+				t.Errorf("members = %q\nwant      %q", got, want)
+			}
+		})
+	}
+}
+
+// Test member yang tidak dibaca sampai habis dilewati oleh Next
+func TestArReaderSkipsUnreadData(t *testing.T) {
+	archive := "!<arch>\n" + arMember("first", "12345") + arMember("second", "abc")
+	ar, err := browser.NewArReader(bytes.NewBufferString(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hdr, _ := ar.Next()
+	if hdr.Name != "first" || hdr.UID != 1000 || hdr.Mode != 0o100644 || hdr.ModTime.Unix() != 1700000000 {
+		t.Errorf("header = %+v", hdr)
+	}
+	buf := make([]byte, 2)
+	io.ReadFull(ar, buf)
+
+	hdr, err = ar.Next()
+	if err != nil || hdr.Name != "second" {
+		t.Fatalf("Next = %+v, %v", hdr, err)
+	}
+	if data, _ := io.ReadAll(ar); string(data) != "abc" {
+		t.Errorf("second = %q", data)
+	}
+	if _, err := ar.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+// Test magic, terminator header, field angka dan data terpotong divalidasi
+func TestArReaderRejectsInvalid(t *testing.T) {
+	valid := arMember("member", "data")
+	cases := map[string]string{
+		"bad_magic":      "!<arkh>\n" + valid,
+		"short_magic":    "!<ar",
+		"bad_terminator": "!<arch>\n" + strings.Replace(valid, "`\n", "~\n", 1),
+		"bad_size":       "!<arch>\n" + strings.Replace(valid, "4         `", "4x        `", 1),
+		"truncated_head": "!<arch>\n" + valid[:30],
+		"gnu_no_table":   "!<arch>\n" + arMember("/4", "data"),
+		"bsd_too_long":   "!<arch>\n" + arMember("#1/99", "data"),
+	}
+	for name, archive := range cases {
+		t.Run(name, func(t *testing.T) {
+			ar, err := browser.NewArReader(strings.NewReader(archive))
+			if err == nil {
+				_, err = ar.Next()
+			}
+			if !errors.Is(err, browser.ErrArHeader) {
+				t.Errorf("expected ErrArHeader, got %v", err)
+			}
+		})
+	}
+
+	// Isi member lebih pendek dari Size
+	ar, _ := browser.NewArReader(strings.NewReader("!<arch>\n" + valid[:len(valid)-2]))
+	ar.Next()
+	if _, err := io.ReadAll(ar); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated data: expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+// arRawHeader menulis header ar dengan Size apa adanya, tanpa data
+func arRawHeader(name string, size int64) string {
+	return fmt.Sprintf("%-16s%-12d%-6d%-6d%-8s%-10d`\n", name, 1700000000, 1000, 1000, "100644", size)
+}
+
+// Test Size dari header tidak dipercaya: tabel nama raksasa ditolak sebelum
+// dialokasikan dan member terpotong terdeteksi walaupun reader bisa Seek
+func TestArReaderRejectsOversizedAndTruncatedMembers(t *testing.T) {
+	ar, err := browser.NewArReader(strings.NewReader("!<arch>\n" + arRawHeader("//", 9999999999)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ar.Next(); !errors.Is(err, browser.ErrArHeader) {
+		t.Errorf("oversized long name table: expected ErrArHeader, got %v", err)
+	}
+
+	archive := "!<arch>\n" + arRawHeader("first", 1000) + "only a few bytes"
+	path := filepath.Join(t.TempDir(), "truncated.ar")
+	if err := os.WriteFile(path, []byte(archive), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for name, r := range map[string]io.Reader{
+		"file":    file,
+		"strings": strings.NewReader(archive),
+		"stream":  iotest.OneByteReader(strings.NewReader(archive)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ar, err := browser.NewArReader(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ar.Next(); err != nil {
+				t.Fatalf("first Next: %v", err)
+			}
+			if _, err := ar.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("skipping truncated member: expected io.ErrUnexpectedEOF, got %v", err)
+			}
+		})
+	}
+}
+
+// Test metadata control dibaca dari .deb
+func TestReadDebControl(t *testing.T) {
+	deb := buildDeb(t, "libnss3", "2:3.87-1", "libnss3.so")
+	pkg, err := browser.ReadDebControl(iotest.HalfReader(bytes.NewReader(deb)))
+	if err != nil {
+		t.Fatalf("ReadDebControl: %v", err)
+	}
+	if pkg.Name != "libnss3" || pkg.Version != "2:3.87-1" || pkg.Architecture != "amd64" {
+		t.Errorf("control = %+v", pkg)
+	}
+
+	notDeb := []byte("!<arch>\n" + arMember("control.tar.gz", "x"))
+	if _, err := browser.ReadDebControl(bytes.NewReader(notDeb)); err == nil {
+		t.Error("expected error for archive without debian-binary")
+	}
+}
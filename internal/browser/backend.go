@@ -0,0 +1,104 @@
+package browser
+
+import (
+	"context"
+	"strings"
+)
+
+// Format package dependency yang didukung
+const (
+	PackageDeb = "deb"
+	PackageRPM = "rpm"
+	PackageAPK = "apk"
+)
+
+// PackageInfo adalah metadata package yang dibaca saat ekstraksi
+type PackageInfo struct {
+	Name         string
+	Version      string
+	Architecture string
+}
+
+// PackageBackend mengekstrak dependency dalam satu format package distro.
+// Backend dipilih dari ID/ID_LIKE di /etc/os-release sehingga library tidak
+// dicampur antar distro (misal .deb glibc Debian di Fedora).
+type PackageBackend interface {
+	// Format mengembalikan format package ("deb", "rpm" atau "apk")
+	Format() string
+
+	// PackageURL mengembalikan URL package dep untuk distro osType, atau
+	// kosong jika dep tidak punya package untuk format ini
+	PackageURL(dep Dependency, osType string) string
+
+	// Extract mengekstrak file package di path ke root
+	Extract(ctx context.Context, path, root string) (PackageInfo, error)
+}
+
+// osFamilies memetakan ID distro (dari ID atau ID_LIKE) ke osType
+var osFamilies = map[string]string{
+	"debian": "debian",
+	"ubuntu": "ubuntu",
+	"fedora": "fedora",
+	"rhel":   "rhel",
+	"centos": "rhel",
+	"alpine": "alpine",
+}
+
+// osPackageFormats memetakan osType ke format package-nya
+var osPackageFormats = map[string]string{
+	"debian": PackageDeb,
+	"ubuntu": PackageDeb,
+	"fedora": PackageRPM,
+	"rhel":   PackageRPM,
+	"alpine": PackageAPK,
+}
+
+// detectOSType menentukan osType dari isi os-release: ID dicek lebih dulu,
+// lalu setiap ID_LIKE sesuai urutan (contoh Rocky "rhel centos fedora"
+// menjadi "rhel"). ID yang tidak dikenali dikembalikan apa adanya.
+func detectOSType(release map[string]string) string {
+	id := strings.ToLower(release["ID"])
+	candidates := append([]string{id}, strings.Fields(strings.ToLower(release["ID_LIKE"]))...)
+	for _, candidate := range candidates {
+		if family, ok := osFamilies[candidate]; ok {
+			return family
+		}
+	}
+	if id == "" {
+		// Tanpa os-release (container minimal) tetap memakai Debian
+		return "debian"
+	}
+	return id
+}
+
+// osRelease membaca dm.OSReleaseFile (default /etc/os-release)
+func (dm *DependencyManager) osRelease() map[string]string {
+	path := dm.OSReleaseFile
+	if path == "" {
+		path = osReleasePath
+	}
+	return readOSRelease(path)
+}
+
+// detectOS mendeteksi jenis OS dari os-release
+func (dm *DependencyManager) detectOS() string {
+	return detectOSType(dm.osRelease())
+}
+
+// backendFor mengembalikan backend untuk osType, atau nil jika distro
+// tersebut tidak didukung
+func (dm *DependencyManager) backendFor(osType string) PackageBackend {
+	switch osPackageFormats[osType] {
+	case PackageDeb:
+		dpkg := dm.DpkgPath
+		if dpkg == "" {
+			dpkg = "dpkg"
+		}
+		return &debBackend{dpkg: dpkg, logger: dm.logger}
+	case PackageRPM:
+		return &rpmBackend{logger: dm.logger}
+	case PackageAPK:
+		return &apkBackend{logger: dm.logger}
+	}
+	return nil
+}
@@ -0,0 +1,390 @@
+package browser_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// writeOSRelease menulis file os-release palsu dan mengembalikan path-nya
+func writeOSRelease(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "os-release")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// setupWithBackend menjalankan SetupContext dengan os-release palsu dan
+// mengembalikan log (key=value) serta libDir
+func setupWithBackend(t *testing.T, osRelease string, deps []browser.Dependency) ([]string, string) {
+	t.Helper()
+	libDir := filepath.Join(t.TempDir(), "libs")
+	var logs []string
+	dm := browser.NewDependencyManager(libDir, func(key, value string) {
+		logs = append(logs, key+"="+value)
+	})
+	dm.OSReleaseFile = writeOSRelease(t, osRelease)
+	if err := dm.SetupContext(context.Background(), deps); err != nil {
+		t.Fatalf("SetupContext: %v", err)
+	}
+	return logs, libDir
+}
+
+// hasLog mengecek apakah entry log key=value ada
+func hasLog(logs []string, entry string) bool {
+	for _, log := range logs {
+		if log == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// Test backend dipilih dari ID lalu ID_LIKE; distro non-Debian tidak memakai
+// URL .deb (dependency di sini hanya punya package .deb)
+func TestDependencyBackendSelection(t *testing.T) {
+	tests := []struct {
+		name, osRelease, osType, backend string
+	}{
+		{"debian", "ID=debian\nVERSION_CODENAME=bookworm\n", "debian", "deb"},
+		{"mint", "ID=linuxmint\nID_LIKE=\"ubuntu debian\"\n", "ubuntu", "deb"},
+		{"fedora", "ID=fedora\nVERSION_ID=40\n", "fedora", "rpm"},
+		{"rocky", "ID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\n", "rhel", "rpm"},
+		{"alpine", "ID=alpine\nVERSION_ID=3.20.0\n", "alpine", "apk"},
+		{"missing", "", "debian", "deb"},
+		{"arch", "ID=arch\n", "arch", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, _ := setupWithBackend(t, tt.osRelease, []browser.Dependency{
+				{Name: "libnss3", LibraryName: "libnss3.so"},
+			})
+			if !hasLog(logs, "dependencies_os_detected="+tt.osType) {
+				t.Errorf("os type not %s: %v", tt.osType, logs)
+			}
+			if tt.backend == "" {
+				if !hasLog(logs, "dependencies_unsupported_os="+tt.osType) {
+					t.Errorf("expected unsupported os log: %v", logs)
+				}
+				return
+			}
+			if !hasLog(logs, "dependencies_backend="+tt.backend) {
+				t.Errorf("backend not %s: %v", tt.backend, logs)
+			}
+			if !hasLog(logs, "dependencies_no_package=libnss3 ("+tt.backend+")") {
+				t.Errorf("expected no %s package for libnss3: %v", tt.backend, logs)
+			}
+		})
+	}
+}
+
+// rpmEntry adalah entry cpio untuk buildRPM
+type rpmEntry struct {
+	name  string
+	mode  uint32
+	ino   uint32
+	nlink uint32
+	data  string
+}
+
+// rpmHeader menulis struktur header RPM berisi tag string dan int32
+func rpmHeader(strs map[int]string, ints map[int]uint32) []byte {
+	var index, store bytes.Buffer
+	addEntry := func(tag, typ, offset int) {
+		binary.Write(&index, binary.BigEndian, []uint32{uint32(tag), uint32(typ), uint32(offset), 1})
+	}
+	for tag, value := range ints {
+		for store.Len()%4 != 0 {
+			store.WriteByte(0)
+		}
+		addEntry(tag, 4, store.Len())
+		binary.Write(&store, binary.BigEndian, value)
+	}
+	for tag, value := range strs {
+		addEntry(tag, 6, store.Len())
+		store.WriteString(value + "\x00")
+	}
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, []uint32{0x8eade801, 0, uint32(index.Len() / 16), uint32(store.Len())})
+	buf.Write(index.Bytes())
+	buf.Write(store.Bytes())
+	return buf.Bytes()
+}
+
+// buildRPM membuat .rpm dengan payload cpio newc terkompresi zstd
+func buildRPM(t *testing.T, entries []rpmEntry) []byte {
+	t.Helper()
+	var cpio bytes.Buffer
+	pad := func() {
+		for cpio.Len()%4 != 0 {
+			cpio.WriteByte(0)
+		}
+	}
+	for _, e := range append(entries, rpmEntry{name: "TRAILER!!!", nlink: 1}) {
+		fmt.Fprintf(&cpio, "070701%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x",
+			e.ino, e.mode, 0, 0, e.nlink, 1700000000, len(e.data), 0, 0, 0, 0, len(e.name)+1, 0)
+		cpio.WriteString(e.name + "\x00")
+		pad()
+		cpio.WriteString(e.data)
+		pad()
+	}
+	var payload bytes.Buffer
+	zw, err := zstd.NewWriter(&payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw.Write(cpio.Bytes())
+	zw.Close()
+
+	lead := make([]byte, 96)
+	binary.BigEndian.PutUint32(lead, 0xedabeedb)
+	lead[4] = 3
+	// Signature 36 byte (bukan kelipatan 8) untuk menguji padding
+	signature := rpmHeader(map[int]string{1000: "sig"}, nil)
+	header := rpmHeader(
+		map[int]string{1000: "nss", 1001: "3.90", 1002: "1.el9", 1022: "x86_64", 1124: "cpio"},
+		map[int]uint32{1003: 1},
+	)
+
+	var rpm bytes.Buffer
+	rpm.Write(lead)
+	rpm.Write(signature)
+	rpm.Write(make([]byte, (8-len(signature)%8)%8))
+	rpm.Write(header)
+	rpm.Write(payload.Bytes())
+	return rpm.Bytes()
+}
+
+// servePackages menyajikan file package berdasarkan path
+func servePackages(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// Test payload cpio RPM diekstrak (file, symlink, hardlink) dan RHELURL
+// dipakai di turunan RHEL
+func TestRPMBackendExtract(t *testing.T) {
+	rpm := buildRPM(t, []rpmEntry{
+		{name: "./usr/lib64", mode: 0o040755, ino: 1, nlink: 2},
+		{name: "./usr/lib64/libnss3.so", mode: 0o100755, ino: 2, nlink: 1, data: "payload"},
+		{name: "./usr/lib64/libnss3.so.0", mode: 0o120777, ino: 3, nlink: 1, data: "libnss3.so"},
+		{name: "./usr/lib64/libsoftokn3.so", mode: 0o100755, ino: 4, nlink: 2},
+		{name: "./usr/lib64/nss/libsoftokn3.so", mode: 0o100755, ino: 4, nlink: 2, data: "softokn"},
+	})
+	url := servePackages(t, map[string][]byte{"/el9/nss.rpm": rpm})
+
+	logs, libDir := setupWithBackend(t, "ID=rocky\nID_LIKE=\"rhel centos fedora\"\n", []browser.Dependency{{
+		Name:        "libnss3",
+		LibraryName: "libnss3.so",
+		DebianURL:   url + "/debian/libnss3.deb",
+		RPM:         &browser.RPMPackage{Name: "nss", FedoraURL: url + "/fc40/nss.rpm", RHELURL: url + "/el9/nss.rpm"},
+	}})
+	if !hasLog(logs, "dependencies_package=nss=1:3.90-1.el9 (rpm)") || !hasLog(logs, "dependencies_rpm_payload=zstd") {
+		t.Errorf("unexpected logs: %v", logs)
+	}
+
+	lib64 := filepath.Join(libDir, "usr", "lib64")
+	if data, err := os.ReadFile(filepath.Join(lib64, "libnss3.so")); err != nil || string(data) != "payload" {
+		t.Errorf("libnss3.so = %q, %v", data, err)
+	}
+	if link, err := os.Readlink(filepath.Join(lib64, "libnss3.so.0")); err != nil || link != "libnss3.so" {
+		t.Errorf("libnss3.so.0 -> %q, %v", link, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(lib64, "libsoftokn3.so")); err != nil || string(data) != "softokn" {
+		t.Errorf("hardlink libsoftokn3.so = %q, %v", data, err)
+	}
+}
+
+// Test entry cpio yang keluar dari libDir ditolak
+func TestRPMBackendRejectsUnsafeEntry(t *testing.T) {
+	rpm := buildRPM(t, []rpmEntry{
+		{name: "../../escaped", mode: 0o100644, ino: 1, nlink: 1, data: "payload"},
+	})
+	url := servePackages(t, map[string][]byte{"/nss.rpm": rpm})
+
+	logs, libDir := setupWithBackend(t, "ID=fedora\n", []browser.Dependency{{
+		Name: "libnss3", LibraryName: "libnss3.so", RPM: &browser.RPMPackage{FedoraURL: url + "/nss.rpm"},
+	}})
+	found := false
+	for _, log := range logs {
+		found = found || (strings.HasPrefix(log, "dependencies_error=") && strings.Contains(log, "unsafe archive entry"))
+	}
+	if !found {
+		t.Errorf("expected unsafe entry error: %v", logs)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(filepath.Dir(libDir)), "escaped")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("file was written outside the lib directory")
+	}
+}
+
+// apkSegment menulis satu stream gzip berisi entry tar; end-of-archive hanya
+// ditulis untuk segmen terakhir seperti abuild
+func apkSegment(t *testing.T, files map[string]string, last bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	// Direktori ditulis sebelum isinya
+	sort.Strings(names)
+	for _, name := range names {
+		typ := byte(tar.TypeReg)
+		if strings.HasSuffix(name, "/") {
+			typ = tar.TypeDir
+		}
+		tw.WriteHeader(&tar.Header{Name: name, Typeflag: typ, Mode: 0o755, Size: int64(len(files[name]))})
+		tw.Write([]byte(files[name]))
+	}
+	if last {
+		tw.Close()
+	} else {
+		tw.Flush()
+	}
+	gw.Close()
+	return buf.Bytes()
+}
+
+// Test .apk (signature + control + data) diekstrak tanpa file metadata
+func TestAPKBackendExtract(t *testing.T) {
+	var apk []byte
+	apk = append(apk, apkSegment(t, map[string]string{".SIGN.RSA.alpine-devel.rsa.pub": "signature"}, false)...)
+	apk = append(apk, apkSegment(t, map[string]string{
+		".PKGINFO":        "# Generated by abuild\npkgname = nss\npkgver = 3.101-r0\narch = x86_64\ndepend = so:libc.musl-x86_64.so.1\n",
+		".post-deinstall": "#!/bin/sh\n",
+	}, false)...)
+	apk = append(apk, apkSegment(t, map[string]string{
+		"usr/":                 "",
+		"usr/lib/":             "",
+		"usr/lib/libnss3.so":   "payload",
+		"usr/lib/libsmime3.so": "payload",
+	}, true)...)
+	url := servePackages(t, map[string][]byte{"/nss.apk": apk})
+
+	logs, libDir := setupWithBackend(t, "ID=alpine\n", []browser.Dependency{{
+		Name: "libnss3", LibraryName: "libnss3.so", APK: &browser.APKPackage{Name: "nss", URL: url + "/nss.apk"},
+	}})
+	if !hasLog(logs, "dependencies_package=nss=3.101-r0 (apk)") {
+		t.Errorf("unexpected logs: %v", logs)
+	}
+	if data, err := os.ReadFile(filepath.Join(libDir, "usr", "lib", "libnss3.so")); err != nil || string(data) != "payload" {
+		t.Errorf("libnss3.so = %q, %v", data, err)
+	}
+	for _, meta := range []string{".PKGINFO", ".SIGN.RSA.alpine-devel.rsa.pub", ".post-deinstall"} {
+		if _, err := os.Stat(filepath.Join(libDir, meta)); err == nil {
+			t.Errorf("%s should not be extracted", meta)
+		}
+	}
+}
+
+// Test nama RPM diresolve dari repodata (repomd.xml + primary.xml.gz) dan
+// package diverifikasi dengan SHA256 di primary; URL tidak dipakai
+func TestDependencyManagerResolvesRPMFromRepository(t *testing.T) {
+	rpm := buildRPM(t, []rpmEntry{
+		{name: "./usr/lib64/libnss3.so", mode: 0o100755, ino: 1, nlink: 1, data: "payload"},
+	})
+	rpmSum := sha256.Sum256(rpm)
+	var primary bytes.Buffer
+	gw := gzip.NewWriter(&primary)
+	fmt.Fprintf(gw, `<?xml version="1.0" encoding="UTF-8"?>
+<metadata xmlns="http://linux.duke.edu/metadata/common" packages="1">
+<package type="rpm"><name>nss</name><arch>noarch</arch><version epoch="0" ver="3.90" rel="1.fc40"/>
+<checksum type="sha256" pkgid="YES">%s</checksum><size package="%d"/><location href="Packages/n/nss.rpm"/></package>
+</metadata>`, hex.EncodeToString(rpmSum[:]), len(rpm))
+	gw.Close()
+	primarySum := sha256.Sum256(primary.Bytes())
+	repomd := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<repomd xmlns="http://linux.duke.edu/metadata/repo">
+<data type="primary"><checksum type="sha256">%s</checksum><location href="repodata/primary.xml.gz"/></data>
+</repomd>`, hex.EncodeToString(primarySum[:]))
+
+	url := servePackages(t, map[string][]byte{
+		"/fedora/40/repodata/repomd.xml":     []byte(repomd),
+		"/fedora/40/repodata/primary.xml.gz": primary.Bytes(),
+		"/fedora/40/Packages/n/nss.rpm":      rpm,
+	})
+
+	libDir := filepath.Join(t.TempDir(), "libs")
+	var logs []string
+	dm := browser.NewDependencyManager(libDir, func(key, value string) {
+		logs = append(logs, key+"="+value)
+	})
+	dm.OSReleaseFile = writeOSRelease(t, "ID=fedora\nVERSION_ID=40\n")
+	dm.RPMSources = map[string]browser.RPMSource{"fedora": {Repos: []string{url + "/fedora/$releasever"}}}
+	err := dm.SetupContext(context.Background(), []browser.Dependency{{
+		Name: "libnss3", LibraryName: "libnss3.so", RPM: &browser.RPMPackage{Name: "nss"},
+	}})
+	if err != nil {
+		t.Fatalf("SetupContext: %v", err)
+	}
+	if !hasLog(logs, "dependencies_installed=nss=3.90-1.fc40") {
+		t.Errorf("unexpected logs: %v", logs)
+	}
+	if data, err := os.ReadFile(filepath.Join(libDir, "usr", "lib64", "libnss3.so")); err != nil || string(data) != "payload" {
+		t.Errorf("libnss3.so = %q, %v", data, err)
+	}
+}
+
+// Test nama APK diresolve dari APKINDEX.tar.gz branch VERSION_ID; URL di
+// APKPackage tidak dipakai
+func TestDependencyManagerResolvesAPKFromRepository(t *testing.T) {
+	apk := append(apkSegment(t, map[string]string{".PKGINFO": "pkgname = nss\npkgver = 3.101-r0\n"}, false),
+		apkSegment(t, map[string]string{"usr/": "", "usr/lib/": "", "usr/lib/libnss3.so": "payload"}, true)...)
+	index := apkSegment(t, map[string]string{
+		"APKINDEX": fmt.Sprintf("P:nspr\nV:4.35-r4\nS:10\n\nP:nss\nV:3.101-r0\nS:%d\nT:Mozilla Network Security Services\n\n", len(apk)),
+	}, true)
+	url := servePackages(t, map[string][]byte{
+		"/alpine/v3.20/main/x86_64/APKINDEX.tar.gz":  index,
+		"/alpine/v3.20/main/x86_64/nss-3.101-r0.apk": apk,
+	})
+
+	libDir := filepath.Join(t.TempDir(), "libs")
+	var logs []string
+	dm := browser.NewDependencyManager(libDir, func(key, value string) {
+		logs = append(logs, key+"="+value)
+	})
+	dm.OSReleaseFile = writeOSRelease(t, "ID=alpine\nVERSION_ID=3.20.3\n")
+	dm.APKSources = map[string]browser.APKSource{"alpine": {Repos: []string{url + "/alpine/$branch/main"}, Arch: "x86_64"}}
+	err := dm.SetupContext(context.Background(), []browser.Dependency{{
+		Name: "libnss3", LibraryName: "libnss3.so", APK: &browser.APKPackage{Name: "nss", URL: url + "/missing.apk"},
+	}})
+	if err != nil {
+		t.Fatalf("SetupContext: %v", err)
+	}
+	if !hasLog(logs, "dependencies_url="+url+"/alpine/v3.20/main/x86_64/nss-3.101-r0.apk") || !hasLog(logs, "dependencies_installed=nss=3.101-r0") {
+		t.Errorf("unexpected logs: %v", logs)
+	}
+	if data, err := os.ReadFile(filepath.Join(libDir, "usr", "lib", "libnss3.so")); err != nil || string(data) != "payload" {
+		t.Errorf("libnss3.so = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,121 @@
+package browser
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Blocker menggagalkan request berdasarkan tipe resource (contoh gambar,
+// media, font) dan daftar blokir iklan/tracker. Satu Blocker boleh dipasang
+// ke beberapa page sekaligus; counter-nya digabung.
+type Blocker struct {
+	// ResourceTypes berisi tipe resource CDP yang selalu diblokir,
+	// contoh "Image", "Media", "Font" (tidak case-sensitive)
+	ResourceTypes []string
+	// Lists berisi daftar blokir dari LoadBlockList atau ParseBlockList
+	Lists []*BlockList
+
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+// BlockerStats berisi jumlah request yang diblokir per kategori.
+// Kategori "type:<tipe>" untuk tipe resource dan "list:<nama>" untuk daftar blokir.
+type BlockerStats struct {
+	Total      int64
+	ByCategory map[string]int64
+}
+
+// String mengembalikan ringkasan untuk log, contoh "total=3 list:easylist=1 type:image=2"
+func (s BlockerStats) String() string {
+	categories := make([]string, 0, len(s.ByCategory))
+	for category := range s.ByCategory {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	parts := []string{fmt.Sprintf("total=%d", s.Total)}
+	for _, category := range categories {
+		parts = append(parts, fmt.Sprintf("%s=%d", category, s.ByCategory[category]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Match mengembalikan kategori jika request ke rawURL dengan tipe
+// resourceType harus diblokir
+func (b *Blocker) Match(rawURL, resourceType string) (string, bool) {
+	if containsFold(b.ResourceTypes, resourceType) {
+		return "type:" + strings.ToLower(resourceType), true
+	}
+	if len(b.Lists) == 0 {
+		return "", false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	for _, list := range b.Lists {
+		if list.Match(u, resourceType) {
+			return "list:" + list.Name, true
+		}
+	}
+	return "", false
+}
+
+// Stats mengembalikan salinan counter request yang diblokir
+func (b *Blocker) Stats() BlockerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stats := BlockerStats{ByCategory: make(map[string]int64, len(b.counts))}
+	for category, n := range b.counts {
+		stats.ByCategory[category] = n
+		stats.Total += n
+	}
+	return stats
+}
+
+// AttachBlocker memasang blocker ke browser tempat page berada lewat request
+// interception, sehingga berlaku untuk semua page browser tersebut. Hanya
+// satu interception per browser: error dikembalikan jika browser sudah
+// memakai Policy, NetworkGuard, Blocker atau Replay dari LaunchOptions atau
+// attach lain. Panggil Stop pada router yang dikembalikan untuk melepasnya.
+func (cm *ChromiumManager) AttachBlocker(page *rod.Page, b *Blocker) (*rod.HijackRouter, error) {
+	router, err := startInterception(page.Browser(), []requestHandler{b.handler()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach blocker: %w", err)
+	}
+	cm.logger("blocker_attached", b.describe())
+	return router, nil
+}
+
+// handler mengembalikan requestHandler yang menggagalkan request yang cocok
+func (b *Blocker) handler() requestHandler {
+	return func(h *rod.Hijack) bool {
+		category, blocked := b.Match(h.Request.URL().String(), string(h.Request.Type()))
+		if !blocked {
+			return false
+		}
+		b.mu.Lock()
+		if b.counts == nil {
+			b.counts = map[string]int64{}
+		}
+		b.counts[category]++
+		b.mu.Unlock()
+		h.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+		return true
+	}
+}
+
+// describe meringkas konfigurasi blocker untuk log
+func (b *Blocker) describe() string {
+	parts := []string{"types=" + strings.Join(b.ResourceTypes, ",")}
+	for _, list := range b.Lists {
+		parts = append(parts, fmt.Sprintf("list:%s=%d", list.Name, list.Len()))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,184 @@
+package browser_test
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+const testEasyList = `[Adblock Plus 2.0]
+! Title: test list
+||ads.example.com^
+||tracker.test^$script
+/banner/*.gif
+|https://cdn.test/pixel.js|
+@@||ads.example.com/allowed/
+##.ad-banner
+example.com#@#.sponsor
+||thirdparty.test^$third-party
+/^https?:\/\/regex\.test/
+`
+
+const testHostsFile = `# hosts file
+127.0.0.1 localhost
+::1 ip6-localhost
+0.0.0.0 doubleclick.test
+0.0.0.0 metrics.test stats.test # analytics
+`
+
+func TestBlockListMatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "easylist.txt")
+	if err := os.WriteFile(path, []byte(testEasyList), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	easyList, err := browser.LoadBlockList("easylist", path)
+	if err != nil {
+		t.Fatalf("LoadBlockList failed: %v", err)
+	}
+	if easyList.Skipped != 2 {
+		t.Errorf("Skipped = %d, want 2 (third-party and regex rules)", easyList.Skipped)
+	}
+	hosts, err := browser.ParseBlockList("hosts", strings.NewReader(testHostsFile))
+	if err != nil {
+		t.Fatalf("ParseBlockList failed: %v", err)
+	}
+	if hosts.Len() != 3 {
+		t.Errorf("hosts Len() = %d, want 3", hosts.Len())
+	}
+
+	tests := []struct {
+		list         *browser.BlockList
+		url          string
+		resourceType string
+		blocked      bool
+	}{
+		{easyList, "https://ads.example.com/x.js", "Script", true},
+		{easyList, "https://sub.ads.example.com/x.js", "Script", true},
+		{easyList, "https://notads.example.com/x.js", "Script", false},
+		{easyList, "https://ads.example.com/allowed/x.js", "Script", false},
+		{easyList, "https://tracker.test/t.js", "Script", true},
+		{easyList, "https://tracker.test/t.png", "Image", false},
+		{easyList, "https://site.test/banner/top.gif", "Image", true},
+		{easyList, "https://site.test/banner/top.png", "Image", false},
+		{easyList, "https://cdn.test/pixel.js", "Script", true},
+		{easyList, "https://cdn.test/pixel.js?v=2", "Script", false},
+		{easyList, "https://thirdparty.test/", "Script", false},
+		{hosts, "http://doubleclick.test/ad", "Image", true},
+		{hosts, "http://www.metrics.test/", "XHR", true},
+		{hosts, "http://stats.test/", "Ping", true},
+		{hosts, "http://localhost/", "Document", false},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		if got := tt.list.Match(u, tt.resourceType); got != tt.blocked {
+			t.Errorf("%s.Match(%s, %s) = %v, want %v", tt.list.Name, tt.url, tt.resourceType, got, tt.blocked)
+		}
+	}
+}
+
+func TestAttachBlockerCountsByCategory(t *testing.T) {
+	hosts, err := browser.ParseBlockList("hosts", strings.NewReader(testHostsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocker := &browser.Blocker{
+		ResourceTypes: []string{"image", "Font"},
+		Lists:         []*browser.BlockList{hosts},
+	}
+
+	cdp := newFakeCDP(t)
+	mgr := installFakeChrome(t, cdp.Script())
+	b, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{Sandbox: browser.SandboxOff})
+	if err != nil {
+		t.Fatalf("GetBrowserWithOptions failed: %v", err)
+	}
+	defer b.Close()
+	page, err := b.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		t.Fatalf("Page failed: %v", err)
+	}
+
+	router, err := mgr.AttachBlocker(page, blocker)
+	if err != nil {
+		t.Fatalf("AttachBlocker failed: %v", err)
+	}
+	defer router.Stop()
+
+	// Interception berlaku untuk seluruh browser (event di session browser)
+	session := ""
+	cdp.Emit("Fetch.requestPaused", session, pausedRequest("r1", "https://site.test/logo.png", "Image"))
+	cdp.Emit("Fetch.requestPaused", session, pausedRequest("r2", "https://site.test/a.png", "Image"))
+	cdp.Emit("Fetch.requestPaused", session, pausedRequest("r3", "https://site.test/font.woff2", "Font"))
+	cdp.Emit("Fetch.requestPaused", session, pausedRequest("r4", "https://doubleclick.test/ad.js", "Script"))
+	cdp.Emit("Fetch.requestPaused", session, pausedRequest("r5", "https://site.test/app.js", "Script"))
+
+	waitFor(t, "blocked requests to fail", func() bool { return cdp.Calls("Fetch.failRequest") == 4 })
+	waitFor(t, "allowed request to continue", func() bool { return cdp.Calls("Fetch.continueRequest") == 1 })
+
+	stats := blocker.Stats()
+	want := map[string]int64{"type:image": 2, "type:font": 1, "list:hosts": 1}
+	if stats.Total != 4 {
+		t.Errorf("Total = %d, want 4", stats.Total)
+	}
+	for category, n := range want {
+		if stats.ByCategory[category] != n {
+			t.Errorf("ByCategory[%s] = %d, want %d (%s)", category, stats.ByCategory[category], n, stats)
+		}
+	}
+}
+
+// Test hanya satu interception per browser: attach kedua dan attach ke
+// browser yang sudah memakai interception dari LaunchOptions ditolak
+func TestAttachBlockerRejectsSecondInterception(t *testing.T) {
+	cdp := newFakeCDP(t)
+	mgr := installFakeChrome(t, cdp.Script())
+	blocker := &browser.Blocker{ResourceTypes: []string{"Image"}}
+
+	b, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{Sandbox: browser.SandboxOff})
+	if err != nil {
+		t.Fatalf("GetBrowserWithOptions failed: %v", err)
+	}
+	defer b.Close()
+	page, err := b.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		t.Fatalf("Page failed: %v", err)
+	}
+	router, err := mgr.AttachBlocker(page, blocker)
+	if err != nil {
+		t.Fatalf("AttachBlocker failed: %v", err)
+	}
+	if _, err := mgr.AttachBlocker(page, blocker); err == nil {
+		t.Error("second AttachBlocker on the same browser should fail")
+	}
+	// Setelah Stop, interception boleh dipasang lagi
+	router.Stop()
+	router, err = mgr.AttachBlocker(page, blocker)
+	if err != nil {
+		t.Fatalf("AttachBlocker after Stop failed: %v", err)
+	}
+	router.Stop()
+
+	guarded, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{
+		Sandbox: browser.SandboxOff,
+		Policy:  &browser.Policy{},
+	})
+	if err != nil {
+		t.Fatalf("GetBrowserWithOptions failed: %v", err)
+	}
+	defer guarded.Close()
+	guardedPage, err := guarded.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		t.Fatalf("Page failed: %v", err)
+	}
+	if _, err := mgr.AttachBlocker(guardedPage, blocker); err == nil {
+		t.Error("AttachBlocker should fail when launch options already intercept requests")
+	}
+}
@@ -0,0 +1,265 @@
+package browser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// easyListTypes memetakan opsi tipe EasyList ke tipe resource CDP
+var easyListTypes = map[string][]string{
+	"image":          {"Image"},
+	"script":         {"Script"},
+	"stylesheet":     {"Stylesheet"},
+	"font":           {"Font"},
+	"media":          {"Media"},
+	"xmlhttprequest": {"XHR", "Fetch"},
+	"websocket":      {"WebSocket"},
+	"ping":           {"Ping"},
+	"other":          {"Other"},
+}
+
+// hostsFileIgnored adalah entry standar hosts file yang bukan domain iklan
+var hostsFileIgnored = map[string]bool{
+	"localhost":             true,
+	"localhost.localdomain": true,
+	"local":                 true,
+	"broadcasthost":         true,
+	"ip6-localhost":         true,
+	"ip6-loopback":          true,
+	"0.0.0.0":               true,
+}
+
+// BlockList adalah daftar blokir iklan/tracker dalam format EasyList
+// (subset: "||domain^", pola dengan "*", "|" dan "^", exception "@@" dan
+// opsi tipe resource) atau hosts file ("0.0.0.0 domain"). Rule kosmetik dan
+// rule dengan opsi yang tidak didukung (contoh third-party, domain=) dilewati
+// agar tidak memblokir lebih dari yang dimaksud.
+type BlockList struct {
+	// Name dipakai sebagai kategori counter, contoh "easylist"
+	Name string
+
+	hosts      map[string]bool
+	rules      []listRule
+	exceptions []listRule
+	// Skipped adalah jumlah rule yang tidak didukung dan dilewati
+	Skipped int
+}
+
+// listRule adalah satu rule URL EasyList
+type listRule struct {
+	pattern *regexp.Regexp
+	// types membatasi rule ke tipe resource tertentu; kosong berarti semua
+	types map[string]bool
+	// notTypes mengecualikan tipe resource ("~image")
+	notTypes map[string]bool
+}
+
+// LoadBlockList membaca daftar blokir dari file
+func LoadBlockList(name, path string) (*BlockList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open block list: %w", err)
+	}
+	defer f.Close()
+	list, err := ParseBlockList(name, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse block list %s: %w", path, err)
+	}
+	return list, nil
+}
+
+// ParseBlockList membaca daftar blokir EasyList atau hosts file dari r.
+// Kedua format boleh tercampur; format ditentukan per baris.
+func ParseBlockList(name string, r io.Reader) (*BlockList, error) {
+	list := &BlockList{Name: name, hosts: map[string]bool{}}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "!") || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+			continue
+		}
+		if list.parseHostsLine(line) {
+			continue
+		}
+		list.parseEasyListLine(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Len mengembalikan jumlah rule blokir (host dan pola URL)
+func (l *BlockList) Len() int {
+	return len(l.hosts) + len(l.rules)
+}
+
+// Match mengecek apakah URL dengan tipe resourceType diblokir oleh daftar ini
+func (l *BlockList) Match(u *url.URL, resourceType string) bool {
+	rawURL := u.String()
+	for _, rule := range l.exceptions {
+		if rule.matches(rawURL, resourceType) {
+			return false
+		}
+	}
+	for host := strings.ToLower(u.Hostname()); host != ""; {
+		if l.hosts[host] {
+			return true
+		}
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			break
+		}
+		host = host[i+1:]
+	}
+	for _, rule := range l.rules {
+		if rule.matches(rawURL, resourceType) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHostsLine mem-parse baris hosts file, contoh "0.0.0.0 ads.example.com".
+// Mengembalikan false jika baris bukan format hosts file.
+func (l *BlockList) parseHostsLine(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return false
+	}
+	if _, err := netip.ParseAddr(fields[0]); err != nil {
+		return false
+	}
+	for _, host := range fields[1:] {
+		if strings.HasPrefix(host, "#") {
+			break
+		}
+		host = strings.ToLower(host)
+		if !hostsFileIgnored[host] {
+			l.hosts[host] = true
+		}
+	}
+	return true
+}
+
+// parseEasyListLine mem-parse satu rule EasyList
+func (l *BlockList) parseEasyListLine(line string) {
+	// Rule kosmetik (element hiding) tidak berlaku untuk request
+	if strings.Contains(line, "##") || strings.Contains(line, "#@#") || strings.Contains(line, "#?#") || strings.Contains(line, "#$#") {
+		return
+	}
+	exception := strings.HasPrefix(line, "@@")
+	line = strings.TrimPrefix(line, "@@")
+
+	rule := listRule{}
+	if i := strings.LastIndexByte(line, '$'); i >= 0 {
+		if !rule.parseOptions(line[i+1:]) {
+			l.Skipped++
+			return
+		}
+		line = line[:i]
+	}
+	// Rule regex (/.../) tidak didukung
+	if line == "" || (len(line) > 1 && strings.HasPrefix(line, "/") && strings.HasSuffix(line, "/")) {
+		l.Skipped++
+		return
+	}
+
+	// "||domain^" tanpa opsi dimasukkan ke map host agar lookup cepat
+	if !exception && rule.types == nil && rule.notTypes == nil && strings.HasPrefix(line, "||") && strings.HasSuffix(line, "^") {
+		host := strings.ToLower(line[2 : len(line)-1])
+		if host != "" && !strings.ContainsAny(host, "/*^|") {
+			l.hosts[host] = true
+			return
+		}
+	}
+
+	pattern, err := regexp.Compile(easyListRegexp(line))
+	if err != nil {
+		l.Skipped++
+		return
+	}
+	rule.pattern = pattern
+	if exception {
+		l.exceptions = append(l.exceptions, rule)
+	} else {
+		l.rules = append(l.rules, rule)
+	}
+}
+
+// parseOptions mem-parse opsi setelah "$". Mengembalikan false jika ada opsi
+// yang tidak didukung.
+func (r *listRule) parseOptions(options string) bool {
+	for _, option := range strings.Split(options, ",") {
+		option = strings.ToLower(strings.TrimSpace(option))
+		negate := strings.HasPrefix(option, "~")
+		types, ok := easyListTypes[strings.TrimPrefix(option, "~")]
+		if !ok {
+			return false
+		}
+		for _, t := range types {
+			if negate {
+				if r.notTypes == nil {
+					r.notTypes = map[string]bool{}
+				}
+				r.notTypes[t] = true
+			} else {
+				if r.types == nil {
+					r.types = map[string]bool{}
+				}
+				r.types[t] = true
+			}
+		}
+	}
+	return true
+}
+
+func (r listRule) matches(rawURL, resourceType string) bool {
+	if r.types != nil && !r.types[resourceType] {
+		return false
+	}
+	if r.notTypes[resourceType] {
+		return false
+	}
+	return r.pattern.MatchString(rawURL)
+}
+
+// easyListRegexp mengubah pola URL EasyList menjadi regexp
+func easyListRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString("(?i)")
+	switch {
+	case strings.HasPrefix(pattern, "||"):
+		// Awal domain atau subdomain
+		b.WriteString(`^[a-z][a-z0-9+.-]*://([^/?#]*\.)?`)
+		pattern = pattern[2:]
+	case strings.HasPrefix(pattern, "|"):
+		b.WriteString("^")
+		pattern = pattern[1:]
+	}
+	end := ""
+	if strings.HasSuffix(pattern, "|") {
+		end = "$"
+		pattern = pattern[:len(pattern)-1]
+	}
+	for _, c := range pattern {
+		switch c {
+		case '*':
+			b.WriteString(".*")
+		case '^':
+			// Separator: karakter selain huruf, angka dan _-.% atau akhir URL
+			b.WriteString(`(?:[^a-zA-Z0-9_.%-]|$)`)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString(end)
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package browser
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ChecksumMismatchError dikembalikan jika SHA-256 arsip yang didownload
+// tidak sama dengan digest yang diharapkan. Bedakan dengan error jaringan
+// menggunakan errors.As.
+type ChecksumMismatchError struct {
+	URL      string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected sha256 %s, got %s", e.URL, e.Expected, e.Actual)
+}
+
+// expectedChecksum mengembalikan digest SHA-256 yang diharapkan untuk DownloadURL.
+// Config.SHA256 diprioritaskan; jika kosong, digest diambil dari Config.ChecksumURL.
+// String kosong berarti tidak ada verifikasi.
+func (cm *ChromiumManager) expectedChecksum(ctx context.Context) (string, error) {
+	if cm.config.SHA256 != "" {
+		digest, err := parseChecksum(cm.config.SHA256)
+		if err != nil {
+			return "", fmt.Errorf("invalid SHA256 in config: %w", err)
+		}
+		return digest, nil
+	}
+
+	if cm.config.ChecksumURL == "" {
+		return "", nil
+	}
+
+	cm.logger("chrome_checksum_url", cm.config.ChecksumURL)
+	digest, err := fetchChecksum(ctx, cm.config.ChecksumURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch checksum: %w", err)
+	}
+	return digest, nil
+}
+
+// fetchChecksum mengunduh file digest (format sha256sum atau hex saja)
+func fetchChecksum(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	// File digest cukup kecil, batasi untuk jaga-jaga
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", err
+	}
+
+	return parseChecksum(string(data))
+}
+
+// parseChecksum mengambil digest hex dari baris pertama
+// ("<hex>" atau "<hex>  <filename>" seperti output sha256sum)
+func parseChecksum(data string) (string, error) {
+	fields := strings.Fields(data)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum")
+	}
+
+	digest := strings.ToLower(strings.TrimPrefix(fields[0], "sha256:"))
+	raw, err := hex.DecodeString(digest)
+	if err != nil || len(raw) != 32 {
+		return "", fmt.Errorf("not a sha256 hex digest: %q", fields[0])
+	}
+
+	return digest, nil
+}
@@ -0,0 +1,98 @@
+package browser_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// buildChromeZip membuat arsip ZIP kecil dengan struktur Chrome for Testing
+func buildChromeZip(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("chrome-linux64/chrome")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("#!/bin/sh\n"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newTestManager membuat manager dengan HOME sementara dan mengembalikan root directory-nya
+func newTestManager(t *testing.T, config browser.Config) (*browser.ChromiumManager, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	rootDir := filepath.Join(home, ".local", "share", config.InstallDirName)
+	return browser.NewChromiumManagerWithConfig(config, nil), rootDir
+}
+
+func TestSetupRejectsChecksumMismatch(t *testing.T) {
+	archive := buildChromeZip(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer srv.Close()
+
+	mgr, rootDir := newTestManager(t, browser.Config{
+		DownloadURL:    srv.URL + "/chrome-linux64.zip",
+		InstallDirName: "chrome-test",
+		Version:        "1.0.0.0",
+		SHA256:         "0000000000000000000000000000000000000000000000000000000000000000",
+	})
+
+	err := mgr.Setup()
+	var mismatch *browser.ChecksumMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected ChecksumMismatchError, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(rootDir, "custom_url", "1.0.0.0")); !os.IsNotExist(err) {
+		t.Error("archive should not be extracted on checksum mismatch")
+	}
+}
+
+func TestSetupVerifiesChecksumFromURL(t *testing.T) {
+	archive := buildChromeZip(t)
+	sum := sha256.Sum256(archive)
+	digest := hex.EncodeToString(sum[:])
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/chrome-linux64.zip", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+	mux.HandleFunc("/chrome-linux64.zip.sha256", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(digest + "  chrome-linux64.zip\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mgr, rootDir := newTestManager(t, browser.Config{
+		DownloadURL:    srv.URL + "/chrome-linux64.zip",
+		ChecksumURL:    srv.URL + "/chrome-linux64.zip.sha256",
+		InstallDirName: "chrome-test",
+		Version:        "1.0.0.0",
+	})
+
+	if err := mgr.Setup(); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(rootDir, "custom_url", "1.0.0.0", ".version"))
+	if err != nil || string(data) != "1.0.0.0" {
+		t.Errorf("expected .version 1.0.0.0, got %q (%v)", data, err)
+	}
+}
@@ -3,25 +3,32 @@ package browser
 import (
 	"archive/tar"
 	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
 	"github.com/ulikunitz/xz"
 )
 
 // ChromiumManager mengelola instalasi dan konfigurasi Chrome
 type ChromiumManager struct {
+	rootDir     string
 	installDir  string
 	execPath    string
 	versionFile string
 	logger      func(key, value string)
 	config      Config
+	release     Release
+	downloader  *Downloader
 }
 
 // NewChromiumManager membuat instance baru ChromiumManager
@@ -29,18 +36,50 @@ func NewChromiumManager(logger func(key, value string)) *ChromiumManager {
 	return NewChromiumManagerWithConfig(DefaultConfig(), logger)
 }
 
-// Setup mengecek dan mengunduh Chrome jika belum ada atau versi berbeda
+// Setup mengecek dan mengunduh Chrome jika belum ada atau versi berbeda.
+// Setiap versi diinstall di subdirektori sendiri
+// (<InstallDirName>/<distribution>/<version>/) sehingga beberapa versi dan
+// distribusi bisa dipakai berdampingan.
 func (cm *ChromiumManager) Setup() error {
+	return cm.SetupContext(context.Background())
+}
+
+// SetupContext sama dengan Setup, tetapi menunggu lock, resolusi versi, download
+// dan ekstraksi dibatalkan saat ctx selesai
+func (cm *ChromiumManager) SetupContext(ctx context.Context) error {
+	cm.logger("chrome_root_dir", cm.rootDir)
+
+	// Ambil lock instalasi agar proses lain tidak menginstall bersamaan.
+	// Proses yang menunggu akan memakai instalasi dari pemegang lock.
+	lock, err := cm.acquireLock(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to setup chrome: %w", err)
+	}
+	defer lock.release()
+
+	// Resolve channel/milestone/versi ke versi exact dan URL download
+	if err := cm.resolveVersion(ctx); err != nil {
+		return fmt.Errorf("failed to setup chrome: %w", err)
+	}
+	cm.installDir = filepath.Join(cm.rootDir, filepath.FromSlash(installKey(cm.distributionName(), cm.release.Version)))
 	cm.logger("chrome_install_dir", cm.installDir)
-	cm.logger("chrome_target_version", cm.config.Version)
+	cm.logger("chrome_target_version", cm.release.Version)
+
+	// Bersihkan sisa staging dari instalasi yang terputus sebelumnya
+	cm.cleanupStaging()
+	cm.migrateLegacyInstall()
 
 	// Cek apakah sudah terinstall dengan versi yang sama
 	if cm.isInstalledWithCorrectVersion() {
 		cm.logger("chrome_status", "already_installed_correct_version")
-		cm.logger("chrome_version", cm.config.Version)
+		cm.logger("chrome_version", cm.release.Version)
+		cm.recordInstall(false)
 
 		// Setup dependencies meskipun Chrome sudah ada
-		if err := cm.setupDependencies(); err != nil {
+		if err := cm.setupDependencies(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			cm.logger("chrome_dependencies_error", err.Error())
 			// Continue - dependencies mungkin sudah ada
 		}
@@ -48,25 +87,24 @@ func (cm *ChromiumManager) Setup() error {
 		return nil
 	}
 
-	// Cek apakah ada instalasi lama dengan versi berbeda
+	// Cek apakah ada instalasi versi ini yang belum lengkap
 	if cm.isInstalled() {
-		cm.logger("chrome_status", "found_different_version_will_reinstall")
+		cm.logger("chrome_status", "found_incomplete_installation_will_reinstall")
 	} else {
 		cm.logger("chrome_status", "not_found_downloading")
 	}
 
-	// Download dan ekstrak Chrome
-	if err := cm.downloadAndExtract(); err != nil {
+	// Download dan ekstrak Chrome (.version ditulis di staging sebelum rename)
+	if err := cm.downloadAndExtract(ctx); err != nil {
 		return fmt.Errorf("failed to setup chrome: %w", err)
 	}
-
-	// Simpan versi yang terinstall
-	if err := cm.saveVersion(); err != nil {
-		cm.logger("chrome_version_save_error", err.Error())
-	}
+	cm.recordInstall(true)
 
 	// Setup dependencies setelah Chrome terinstall
-	if err := cm.setupDependencies(); err != nil {
+	if err := cm.setupDependencies(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		cm.logger("chrome_dependencies_warning", err.Error())
 		// Continue - Chrome mungkin bisa jalan tanpa semua deps
 	}
@@ -75,8 +113,38 @@ func (cm *ChromiumManager) Setup() error {
 	return nil
 }
 
+// acquireLock mengambil lock instalasi di root directory dengan timeout dari Config
+func (cm *ChromiumManager) acquireLock(ctx context.Context) (*installLock, error) {
+	if err := os.MkdirAll(cm.rootDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create install directory: %w", err)
+	}
+
+	lockTimeout := cm.config.LockTimeout
+	if lockTimeout <= 0 {
+		lockTimeout = defaultLockTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+
+	return acquireInstallLock(ctx, filepath.Join(cm.rootDir, ".lock"), cm.logger)
+}
+
+// versionDirName mengubah versi menjadi nama direktori yang aman
+func versionDirName(version string) string {
+	if version == "" {
+		return "default"
+	}
+	return strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(version)
+}
+
+// installKey mengembalikan "<distribution>/<version>" yang dipakai sebagai
+// key manifest dan path direktori instalasi relatif terhadap root
+func installKey(distribution, version string) string {
+	return versionDirName(distribution) + "/" + versionDirName(version)
+}
+
 // setupDependencies mengecek dan mengunduh dependencies yang diperlukan
-func (cm *ChromiumManager) setupDependencies() error {
+func (cm *ChromiumManager) setupDependencies(ctx context.Context) error {
 	if len(cm.config.Dependencies) == 0 {
 		cm.logger("chrome_dependencies", "none_configured")
 		return nil
@@ -85,10 +153,15 @@ func (cm *ChromiumManager) setupDependencies() error {
 	cm.logger("chrome_dependencies", "checking")
 
 	// Buat direktori untuk dependencies
-	libDir := filepath.Join(cm.installDir, "libs")
+	// (dipakai bersama oleh semua versi Chrome)
+	libDir := filepath.Join(cm.rootDir, "libs")
 
 	depManager := NewDependencyManager(libDir, cm.logger)
-	if err := depManager.Setup(cm.config.Dependencies); err != nil {
+	depManager.downloader = cm.downloader
+	depManager.AptSources = cm.config.AptSources
+	depManager.RPMSources = cm.config.RPMSources
+	depManager.APKSources = cm.config.APKSources
+	if err := depManager.SetupContext(ctx, cm.config.Dependencies); err != nil {
 		return fmt.Errorf("failed to setup dependencies: %w", err)
 	}
 
@@ -114,232 +187,186 @@ func (cm *ChromiumManager) isInstalledWithCorrectVersion() bool {
 	installedVersion := strings.TrimSpace(string(data))
 	cm.logger("chrome_installed_version", installedVersion)
 
-	if installedVersion != cm.config.Version {
-		cm.logger("chrome_version_mismatch", fmt.Sprintf("installed=%s, required=%s", installedVersion, cm.config.Version))
+	if installedVersion != cm.release.Version {
+		cm.logger("chrome_version_mismatch", fmt.Sprintf("installed=%s, required=%s", installedVersion, cm.release.Version))
 		return false
 	}
 
 	return true
 }
 
-// saveVersion menyimpan informasi versi yang terinstall
-func (cm *ChromiumManager) saveVersion() error {
-	versionFile := filepath.Join(cm.installDir, ".version")
-	return os.WriteFile(versionFile, []byte(cm.config.Version), 0644)
+// saveVersion menyimpan informasi versi yang terinstall ke dir
+func (cm *ChromiumManager) saveVersion(dir string) error {
+	versionFile := filepath.Join(dir, ".version")
+	return os.WriteFile(versionFile, []byte(cm.release.Version), 0644)
 }
 
 // isInstalled mengecek apakah Chrome sudah terinstall
 func (cm *ChromiumManager) isInstalled() bool {
-	// Cari executable chrome di direktori instalasi
-	chromePath := filepath.Join(cm.installDir, "chrome")
+	chromePath := cm.findExecutable(cm.installDir)
+	if chromePath == "" {
+		return false
+	}
 
+	cm.execPath = chromePath
+	cm.logger("chrome_executable", chromePath)
+	return true
+}
+
+// findExecutable mencari executable rilis saat ini di dir. Jika path
+// executable tidak diketahui (DownloadURL manual), dir dipindai.
+func (cm *ChromiumManager) findExecutable(dir string) string {
+	if cm.release.Executable != "" {
+		chromePath := filepath.Join(dir, filepath.FromSlash(cm.release.Executable))
+		if _, err := os.Stat(chromePath); err == nil {
+			return chromePath
+		}
+		return ""
+	}
+	return findChromeExecutable(dir)
+}
+
+// findChromeExecutable mencari executable chrome di dir atau satu level subdirektori
+func findChromeExecutable(dir string) string {
+	// Cari executable chrome di direktori instalasi
+	chromePath := filepath.Join(dir, "chrome")
 	if _, err := os.Stat(chromePath); err == nil {
-		cm.execPath = chromePath
-		cm.logger("chrome_executable", chromePath)
-		return true
+		return chromePath
 	}
 
 	// Cari di subdirektori (Chrome for Testing zip biasanya punya folder chrome-linux64)
-	entries, err := os.ReadDir(cm.installDir)
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return false
+		return ""
 	}
 
 	for _, entry := range entries {
 		if entry.IsDir() {
-			chromePath := filepath.Join(cm.installDir, entry.Name(), "chrome")
+			chromePath := filepath.Join(dir, entry.Name(), "chrome")
 			if _, err := os.Stat(chromePath); err == nil {
-				cm.execPath = chromePath
-				cm.logger("chrome_executable", chromePath)
-				return true
+				return chromePath
 			}
 		}
 	}
 
-	return false
+	return ""
 }
 
 // downloadAndExtract mengunduh dan mengekstrak Chrome
-func (cm *ChromiumManager) downloadAndExtract() error {
-	// Buat direktori instalasi
-	if err := os.MkdirAll(cm.installDir, 0o755); err != nil {
+func (cm *ChromiumManager) downloadAndExtract(ctx context.Context) error {
+	// Buat parent direktori instalasi (staging dan download ada di sebelahnya)
+	if err := os.MkdirAll(filepath.Dir(cm.installDir), 0o755); err != nil {
 		return fmt.Errorf("failed to create install directory: %w", err)
 	}
 
-	// Download file
-	cm.logger("chrome_download", "starting")
-	cm.logger("chrome_url", cm.config.DownloadURL)
-	resp, err := http.Get(cm.config.DownloadURL)
+	// Tentukan digest yang diharapkan sebelum download dimulai
+	expected, err := cm.expectedChecksum(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to download chrome: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download chrome: status %d", resp.StatusCode)
+		return err
 	}
 
-	cm.logger("chrome_download", "extracting")
+	// Download ke file sementara sambil menghitung SHA-256
+	// (arsip tidak boleh diekstrak sebelum digest terverifikasi)
+	tmpFile := cm.installDir + ".download"
+	cm.logger("chrome_download", "starting")
+	cm.logger("chrome_url", cm.release.DownloadURL)
+	cm.logger("chrome_temp_file", tmpFile)
 
-	// Deteksi format berdasarkan URL
-	if strings.HasSuffix(cm.config.DownloadURL, ".zip") {
-		// Chrome for Testing menggunakan ZIP
-		return cm.extractZip(resp.Body)
-	} else if strings.HasSuffix(cm.config.DownloadURL, ".tar.xz") {
-		// Ungoogled Chromium menggunakan TAR.XZ
-		return cm.extractTarXz(resp.Body)
+	hasher := sha256.New()
+	if err := cm.downloader.DownloadContext(ctx, cm.release.DownloadURL, tmpFile, hasher); err != nil {
+		return fmt.Errorf("failed to download chrome: %w", err)
+	}
+	defer os.Remove(tmpFile)
+
+	actual := hex.EncodeToString(hasher.Sum(nil))
+	cm.logger("chrome_download_sha256", actual)
+
+	if expected == "" {
+		cm.logger("chrome_checksum", "skipped_no_expected_digest")
+	} else if actual != expected {
+		cm.logger("chrome_checksum", "mismatch")
+		return &ChecksumMismatchError{
+			URL:      cm.release.DownloadURL,
+			Expected: expected,
+			Actual:   actual,
+		}
+	} else {
+		cm.logger("chrome_checksum", "verified")
 	}
 
-	return fmt.Errorf("unsupported file format: %s", cm.config.DownloadURL)
+	cm.logger("chrome_download", "extracting")
+	return cm.installFromArchive(ctx, tmpFile)
 }
 
-// extractZip mengekstrak file ZIP (untuk Chrome for Testing)
-func (cm *ChromiumManager) extractZip(r io.Reader) error {
+// extractZip mengekstrak file ZIP (untuk Chrome for Testing) ke destDir
+func (cm *ChromiumManager) extractZip(ctx context.Context, zipPath, destDir string) (extractResult, error) {
 	cm.logger("chrome_extract", "format_zip")
-
-	// Simpan ke file sementara (ZIP memerlukan random access)
-	tmpFile := filepath.Join(cm.installDir, ".chrome-download.zip")
-	f, err := os.Create(tmpFile)
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
-	}
-
-	cm.logger("chrome_temp_file", tmpFile)
-
-	// Copy data
-	if _, err := io.Copy(f, r); err != nil {
-		f.Close()
-		os.Remove(tmpFile)
-		return fmt.Errorf("failed to save zip: %w", err)
-	}
-	f.Close()
-
 	cm.logger("chrome_extract", "opening_zip")
 
-	// Buka ZIP
-	zipReader, err := zip.OpenReader(tmpFile)
+	// Buka ZIP (ZIP memerlukan random access)
+	zipReader, err := zip.OpenReader(zipPath)
 	if err != nil {
-		os.Remove(tmpFile)
-		return fmt.Errorf("failed to open zip: %w", err)
+		return extractResult{}, fmt.Errorf("failed to open zip: %w", err)
 	}
 	defer zipReader.Close()
 
-	// Ekstrak semua file
-	for _, file := range zipReader.File {
-		target := filepath.Join(cm.installDir, file.Name)
-
-		if file.FileInfo().IsDir() {
-			os.MkdirAll(target, 0o755)
-			continue
-		}
-
-		// Buat parent directory
-		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
-			continue
-		}
-
-		// Ekstrak file
-		outFile, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, file.Mode())
-		if err != nil {
-			continue
-		}
-
-		rc, err := file.Open()
-		if err != nil {
-			outFile.Close()
-			continue
-		}
-
-		_, err = io.Copy(outFile, rc)
-		rc.Close()
-		outFile.Close()
-
-		if err != nil {
-			continue
-		}
+	// Ekstrak semua file (path traversal ditolak oleh extractZipTo)
+	result, err := extractZipTo(ctx, &zipReader.Reader, destDir)
+	if err != nil {
+		return result, err
 	}
 
-	// Hapus file temporary
-	os.Remove(tmpFile)
 	cm.logger("chrome_extract", "success")
-
-	return nil
+	return result, nil
 }
 
-// extractTarXz mengekstrak file tar.xz menggunakan pure Go (tanpa dependency eksternal)
-func (cm *ChromiumManager) extractTarXz(r io.Reader) error {
+// extractTarXz mengekstrak file tar.xz ke destDir menggunakan pure Go (tanpa dependency eksternal)
+func (cm *ChromiumManager) extractTarXz(ctx context.Context, r io.Reader, destDir string) (extractResult, error) {
 	cm.logger("chrome_extract", "format_tar_xz")
 
 	// Decompress xz
 	xzReader, err := xz.NewReader(r)
 	if err != nil {
-		return fmt.Errorf("failed to create xz reader: %w", err)
+		return extractResult{}, fmt.Errorf("failed to create xz reader: %w", err)
 	}
 
-	// Extract tar
-	tarReader := tar.NewReader(xzReader)
-
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("failed to read tar: %w", err)
-		}
-
-		target := filepath.Join(cm.installDir, header.Name)
-
-		switch header.Typeflag {
-		case tar.TypeDir:
-			// Create directory
-			if err := os.MkdirAll(target, 0o755); err != nil {
-				return fmt.Errorf("failed to create directory %s: %w", target, err)
-			}
-			cm.logger("chromium_extract_dir", header.Name)
-
-		case tar.TypeReg:
-			// Create file
-			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
-				return fmt.Errorf("failed to create parent directory for %s: %w", target, err)
-			}
-
-			outFile, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(header.Mode))
-			if err != nil {
-				return fmt.Errorf("failed to create file %s: %w", target, err)
-			}
-
-			if _, err := io.Copy(outFile, tarReader); err != nil {
-				outFile.Close()
-				return fmt.Errorf("failed to write file %s: %w", target, err)
-			}
-
-			outFile.Close()
-
-			// Log progress setiap 100 file
-			if header.Name[len(header.Name)-1] == 'e' {
-				cm.logger("chromium_extract_file", header.Name)
-			}
-
-		case tar.TypeSymlink:
-			// Create symlink
-			if err := os.Symlink(header.Linkname, target); err != nil {
-				// Ignore jika symlink sudah ada
-				if !os.IsExist(err) {
-					return fmt.Errorf("failed to create symlink %s: %w", target, err)
-				}
-			}
+	// Extract tar (path traversal ditolak oleh extractTarTo)
+	result, err := extractTarTo(ctx, tar.NewReader(xzReader), destDir, func(name string) {
+		// Log sebagian file saja agar log tidak terlalu ramai
+		if strings.HasSuffix(name, "e") {
+			cm.logger("chromium_extract_file", name)
 		}
+	})
+	if err != nil {
+		return result, err
 	}
 
 	cm.logger("chromium_extract", "success")
-	return nil
+	return result, nil
 }
 
 // GetBrowser membuat dan mengembalikan instance browser Rod
 func (cm *ChromiumManager) GetBrowser() (*rod.Browser, error) {
+	return cm.GetBrowserContext(context.Background())
+}
+
+// GetBrowserContext sama dengan GetBrowser, tetapi setup, launch dan koneksi
+// dibatalkan saat ctx selesai. ctx hanya membatasi launch: browser yang
+// dikembalikan hidup sampai Close, gunakan rod.Browser.Context untuk
+// membatasi operasi berikutnya.
+func (cm *ChromiumManager) GetBrowserContext(ctx context.Context) (*rod.Browser, error) {
+	return cm.GetBrowserWithOptions(ctx, DefaultLaunchOptions())
+}
+
+// GetBrowserWithOptions sama dengan GetBrowserContext, tetapi flag Chrome
+// diatur lewat opts (lihat DefaultLaunchOptions dan preset lainnya)
+func (cm *ChromiumManager) GetBrowserWithOptions(ctx context.Context, opts LaunchOptions) (*rod.Browser, error) {
+	if err := opts.validate(); err != nil {
+		return nil, fmt.Errorf("invalid launch options: %w", err)
+	}
+
 	// Setup Chrome jika belum (akan download jika perlu atau skip jika sudah ada dengan versi sama)
-	if err := cm.Setup(); err != nil {
+	if err := cm.SetupContext(ctx); err != nil {
 		return nil, fmt.Errorf("failed to setup chrome: %w", err)
 	}
 
@@ -348,55 +375,198 @@ func (cm *ChromiumManager) GetBrowser() (*rod.Browser, error) {
 		return nil, fmt.Errorf("chrome executable not found after setup")
 	}
 
+	// Seperti Supervise dan NewPool, ctx hanya untuk launch; lifetime
+	// dibatalkan saat Chrome keluar (setelah Close) agar tidak ada yang bocor
+	lifetime, cancel := context.WithCancel(context.Background())
+	browser, process, err := cm.launch(ctx, lifetime, opts)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	go func() {
+		<-process.Exited()
+		cancel()
+	}()
+	return browser, nil
+}
+
+// launch menjalankan Chrome yang sudah di-setup dan menghubungkan Rod ke
+// DevTools. ctx membatasi proses launch, sedangkan browser dan prosesnya
+// hidup sampai lifetime selesai atau browser ditutup.
+func (cm *ChromiumManager) launch(ctx, lifetime context.Context, opts LaunchOptions) (*rod.Browser, *chromeProcess, error) {
 	// Gunakan Chrome yang sudah didownload
-	cm.logger("browser_using", "chrome_for_testing")
+	cm.logger("browser_using", cm.distributionName())
 	cm.logger("browser_executable", cm.execPath)
-	cm.logger("browser_version", cm.config.Version)
+	cm.logger("browser_version", cm.release.Version)
 
-	// Set LD_LIBRARY_PATH untuk dependencies yang didownload
-	libDirs := []string{
-		filepath.Join(cm.installDir, "libs", "usr", "lib", "x86_64-linux-gnu"),
-		filepath.Join(cm.installDir, "libs", "usr", "lib64"),
-		filepath.Join(cm.installDir, "libs", "usr", "lib"),
-		filepath.Join(cm.installDir, "libs", "lib", "x86_64-linux-gnu"),
-		filepath.Join(cm.installDir, "libs", "lib64"),
-		filepath.Join(cm.installDir, "libs", "lib"),
-		filepath.Join(cm.installDir, "lib"),
+	sandbox, err := cm.resolveSandbox(opts.Sandbox)
+	if err != nil {
+		cm.logger("browser_sandbox_error", err.Error())
+		return nil, nil, err
 	}
 
-	var existingPaths []string
-	for _, dir := range libDirs {
-		if _, err := os.Stat(dir); err == nil {
-			existingPaths = append(existingPaths, dir)
+	// Simpan output Chrome agar error launch/connect bisa didiagnosa
+	stderr := &stderrBuffer{}
+	l := launcher.New()
+	opts.apply(l, sandbox)
+	cm.logger("browser_launch_options", opts.String())
+
+	// Dengan NetworkGuard atau Policy semua koneksi Chrome (termasuk ke
+	// loopback dan WebSocket) lewat GuardProxy; LaunchOptions.Proxy menjadi
+	// upstream-nya
+	var guardProxy *GuardProxy
+	if opts.NetworkGuard != nil || opts.Policy != nil {
+		guardProxy, err = startGuardProxy(opts.NetworkGuard, opts.Policy, opts.Proxy, cm.logger)
+		if err != nil {
+			return nil, nil, err
 		}
+		l.Proxy(guardProxy.URL())
+		l.Set("proxy-bypass-list", "<-loopback>")
+		cm.logger("browser_network_guard_proxy", guardProxy.Addr())
 	}
 
-	if len(existingPaths) > 0 {
-		newLDPath := strings.Join(existingPaths, ":")
-		currentLD := os.Getenv("LD_LIBRARY_PATH")
-		if currentLD != "" {
-			newLDPath = newLDPath + ":" + currentLD
+	// LD_LIBRARY_PATH untuk dependencies yang didownload hanya dipasang di
+	// environment Chrome, tidak di proses ini
+	env := os.Environ()
+	if ldPath := cm.libraryPath(); ldPath != "" {
+		if currentLD := os.Getenv("LD_LIBRARY_PATH"); currentLD != "" {
+			ldPath = ldPath + ":" + currentLD
 		}
-		os.Setenv("LD_LIBRARY_PATH", newLDPath)
-		cm.logger("browser_ld_library_path", newLDPath)
+		env = append(env, "LD_LIBRARY_PATH="+ldPath)
+		cm.logger("browser_ld_library_path", ldPath)
 	}
+	env = append(env, opts.Env...)
 
-	u := launcher.New().
-		Bin(cm.execPath).
-		Headless(true).
-		NoSandbox(true).
-		Set("disable-gpu").
-		Set("disable-dev-shm-usage").
-		MustLaunch()
+	// Profil sementara dari launcher dihapus setelah Chrome keluar
+	var tempDataDir string
+	if opts.UserDataDir == "" {
+		tempDataDir = l.Get(flags.UserDataDir)
+	}
+
+	// Tandai versi sedang dipakai agar PruneVersions di proses lain tidak
+	// menghapusnya selama Chrome berjalan
+	var inUse *os.File
+	if cm.installDir != "" {
+		inUse, err = lockInUse(cm.installDir)
+		if err != nil {
+			if guardProxy != nil {
+				guardProxy.Close()
+			}
+			return nil, nil, err
+		}
+	}
+
+	process, u, err := startChrome(ctx, lifetime, cm.execPath, l, env, stderr, tempDataDir)
+	if inUse != nil {
+		if err != nil {
+			inUse.Close()
+		} else {
+			go func() {
+				<-process.Exited()
+				inUse.Close()
+			}()
+		}
+	}
+	if guardProxy != nil {
+		if err != nil {
+			guardProxy.Close()
+		} else {
+			go func() {
+				<-process.Exited()
+				guardProxy.Close()
+			}()
+		}
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		err = launchFailure(cm.execPath, stderr.String(), err)
+		cm.logger("browser_launch_error", err.Error())
+		return nil, nil, err
+	}
 
 	// Buat browser
-	browser := rod.New().ControlURL(u).MustConnect()
+	browser := rod.New().Context(lifetime).ControlURL(u)
+	if err := browser.Connect(); err != nil {
+		process.Kill()
+		err = &ConnectError{URL: u, Stderr: stderr.String(), Err: err}
+		cm.logger("browser_connect_error", err.Error())
+		return nil, nil, err
+	}
 
+	if handlers := opts.requestHandlers(cm.logger); len(handlers) > 0 {
+		if _, err := startInterception(browser, handlers); err != nil {
+			browser.Close()
+			process.Kill()
+			return nil, nil, fmt.Errorf("failed to enable request interception: %w", err)
+		}
+		cm.logger("browser_interception", "enabled")
+	}
+	if opts.HAR != nil {
+		if err := startHARAutoRecord(browser, process, *opts.HAR, cm.logger); err != nil {
+			browser.Close()
+			process.Kill()
+			return nil, nil, err
+		}
+		cm.logger("browser_har_dir", opts.HAR.Dir)
+	}
+
+	cm.logger("browser_pid", strconv.Itoa(process.PID()))
 	cm.logger("browser_status", "connected")
-	return browser, nil
+	return browser, process, nil
+}
+
+// libraryPath mengembalikan direktori library dependencies yang ada,
+// dipisah ":" untuk LD_LIBRARY_PATH
+func (cm *ChromiumManager) libraryPath() string {
+	libDirs := append(cm.dependencyLibDirs(), filepath.Join(cm.installDir, "lib"))
+
+	var existingPaths []string
+	for _, dir := range libDirs {
+		if _, err := os.Stat(dir); err == nil {
+			existingPaths = append(existingPaths, dir)
+		}
+	}
+	return strings.Join(existingPaths, ":")
+}
+
+// dependencyLibDirs mengembalikan direktori di pohon libs/ tempat library
+// dependencies diekstrak, sesuai urutan di LD_LIBRARY_PATH
+func (cm *ChromiumManager) dependencyLibDirs() []string {
+	libs := cm.LibDir()
+	return []string{
+		filepath.Join(libs, "usr", "lib", "x86_64-linux-gnu"),
+		filepath.Join(libs, "usr", "lib64"),
+		filepath.Join(libs, "usr", "lib"),
+		filepath.Join(libs, "lib", "x86_64-linux-gnu"),
+		filepath.Join(libs, "lib64"),
+		filepath.Join(libs, "lib"),
+	}
+}
+
+// distributionName mengembalikan nama distribusi yang dipakai
+func (cm *ChromiumManager) distributionName() string {
+	if cm.config.DownloadURL != "" {
+		return "custom_url"
+	}
+	if cm.config.Distribution == "" {
+		return DistChromeForTesting
+	}
+	return cm.config.Distribution
 }
 
 // GetExecutablePath mengembalikan path ke executable Chrome
 func (cm *ChromiumManager) GetExecutablePath() string {
 	return cm.execPath
 }
+
+// RootDir mengembalikan direktori root instalasi (semua versi dan libs/)
+func (cm *ChromiumManager) RootDir() string {
+	return cm.rootDir
+}
+
+// LibDir mengembalikan direktori library dependencies yang diekstrak
+func (cm *ChromiumManager) LibDir() string {
+	return filepath.Join(cm.rootDir, "libs")
+}
@@ -3,38 +3,110 @@ package browser
 import (
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // Config berisi konfigurasi untuk Chrome
 type Config struct {
-	// URL untuk download Chrome for Testing
+	// URL download manual. Jika kosong, diambil dari hasil resolusi Distribution.
 	DownloadURL string
 
 	// Nama direktori instalasi
 	InstallDirName string
 
-	// Versi Chrome
+	// Direktori root instalasi. Kosong = ~/.local/share/<InstallDirName>
+	RootDir string
+
+	// Versi Chrome (exact, misal "131.0.6778.204")
 	Version string
 
+	// Distribusi browser (lihat DistributionNames), kosong = chrome-for-testing
+	Distribution string
+
+	// Channel Chrome for Testing (Stable/Beta/Dev/Canary), kosong = Stable
+	// jika Milestone dan Version juga kosong.
+	// Jika DownloadURL kosong, versi dan URL di-resolve dari distribusi.
+	Channel string
+
+	// Milestone Chrome (misal "131"), dipakai jika Channel kosong
+	Milestone string
+
+	// Base URL endpoint metadata distribusi, kosong = default distribusi
+	VersionsBaseURL string
+
+	// SHA-256 (hex) yang diharapkan untuk file di DownloadURL.
+	// Jika diisi, arsip yang tidak cocok tidak akan diekstrak.
+	SHA256 string
+
+	// URL file digest (format sha256sum), dipakai jika SHA256 kosong
+	ChecksumURL string
+
 	// Dependencies yang akan didownload (.deb packages)
 	Dependencies []Dependency
+
+	// Repository APT per OS ("debian", "ubuntu") untuk meresolve Dependencies
+	// beserta dependency transitifnya. Kosong = hanya URL di Dependency.
+	AptSources map[string]AptSource
+
+	// Repository RPM dan Alpine per OS untuk meresolve RPMPackage.Name dan
+	// APKPackage.Name. Kosong = hanya URL di Dependency.
+	RPMSources map[string]RPMSource
+	APKSources map[string]APKSource
+
+	// Batas waktu per download (Chrome atau satu .deb), 0 = default downloader
+	DownloadTimeout time.Duration
+
+	// Jumlah maksimal percobaan per download, 0 = default downloader
+	DownloadAttempts int
+
+	// Batas waktu menunggu lock instalasi yang dipegang proses lain,
+	// 0 = defaultLockTimeout
+	LockTimeout time.Duration
 }
 
+// defaultLockTimeout adalah batas waktu default menunggu lock instalasi
+const defaultLockTimeout = 30 * time.Minute
+
 // Dependency berisi info dependency yang perlu didownload
 type Dependency struct {
 	Name        string // Nama package (untuk logging)
 	DebianURL   string // URL untuk Debian-based
 	UbuntuURL   string // URL untuk Ubuntu-based (fallback ke DebianURL jika kosong)
 	LibraryName string // Nama library file (untuk checking)
+
+	// RPM adalah package untuk Fedora/RHEL dan turunannya (nil jika tidak ada)
+	RPM *RPMPackage
+	// APK adalah package untuk Alpine Linux (nil jika tidak ada)
+	APK *APKPackage
+}
+
+// RPMPackage berisi lokasi package RPM sebuah Dependency
+type RPMPackage struct {
+	Name      string // Nama package RPM di RPMSources, contoh "nss"
+	FedoraURL string // URL untuk Fedora
+	RHELURL   string // URL untuk RHEL/CentOS/Rocky/Alma (fallback ke FedoraURL jika kosong)
+}
+
+// APKPackage berisi lokasi package Alpine sebuah Dependency
+type APKPackage struct {
+	Name string // Nama package APK di APKSources, contoh "nss"
+	URL  string // URL file .apk, dipakai jika Name tidak ada di index
 }
 
 // DefaultConfig mengembalikan konfigurasi default untuk Chrome for Testing
 func DefaultConfig() Config {
 	return Config{
-		// Chrome for Testing - sudah include banyak dependencies
-		DownloadURL:    "https://storage.googleapis.com/chrome-for-testing-public/131.0.6778.204/linux64/chrome-linux64.zip",
+		// Chrome for Testing - sudah include banyak dependencies.
+		// Versi dan URL di-resolve dari channel Stable (di-cache lokal).
+		// Channel dibiarkan kosong: Chrome for Testing memakai Stable, sedangkan
+		// distribusi lain yang hanya mendukung Version memakai rilis terbaru.
 		InstallDirName: "chrome-for-testing",
-		Version:        "131.0.6778.204",
+		Distribution:   DistChromeForTesting,
+		// Dependencies diresolve dari index repository distro host; URL di
+		// bawah hanya fallback jika index tidak bisa diambil
+		AptSources: defaultAptSources(),
+		RPMSources: defaultRPMSources(),
+		APKSources: defaultAPKSources(),
 		Dependencies: []Dependency{
 			// Dependencies umum yang dibutuhkan Chrome (Debian 11 Bullseye versions)
 			{
@@ -42,54 +114,72 @@ func DefaultConfig() Config {
 				DebianURL:   "https://ftp.debian.org/debian/pool/main/n/nss/libnss3_3.110-1_amd64.deb",
 				UbuntuURL:   "http://security.ubuntu.com/ubuntu/pool/main/n/nss/libnss3_3.98-0ubuntu0.22.04.2_amd64.deb",
 				LibraryName: "libnss3.so",
+				RPM:         &RPMPackage{Name: "nss"},
+				APK:         &APKPackage{Name: "nss"},
 			},
 			{
 				Name:        "libnspr4",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/n/nspr/libnspr4_4.35-1_amd64.deb",
 				UbuntuURL:   "http://security.ubuntu.com/ubuntu/pool/main/n/nspr/libnspr4_4.32-3ubuntu0.22.04.1_amd64.deb",
 				LibraryName: "libnspr4.so",
+				RPM:         &RPMPackage{Name: "nspr"},
+				APK:         &APKPackage{Name: "nspr"},
 			},
 			{
 				Name:        "libatk-bridge2.0-0",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/a/at-spi2-atk/libatk-bridge2.0-0_2.38.0-1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/a/at-spi2-core/libatk-bridge2.0-0_2.38.0-3_amd64.deb",
 				LibraryName: "libatk-bridge-2.0.so",
+				RPM:         &RPMPackage{Name: "at-spi2-atk"},
+				APK:         &APKPackage{Name: "libatk-bridge-2.0"},
 			},
 			{
 				Name:        "libatk1.0-0",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/a/atk1.0/libatk1.0-0_2.36.0-2_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/a/atk1.0/libatk1.0-0_2.36.0-3build1_amd64.deb",
 				LibraryName: "libatk-1.0.so",
+				RPM:         &RPMPackage{Name: "atk"},
+				APK:         &APKPackage{Name: "libatk-1.0"},
 			},
 			{
 				Name:        "libatspi2.0-0",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/a/at-spi2-core/libatspi2.0-0_2.38.0-4+deb11u1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/a/at-spi2-core/libatspi2.0-0_2.38.0-3_amd64.deb",
 				LibraryName: "libatspi.so",
+				RPM:         &RPMPackage{Name: "at-spi2-core"},
+				APK:         &APKPackage{Name: "at-spi2-core"},
 			},
 			{
 				Name:        "libcups2",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/c/cups/libcups2_2.3.3op2-3+deb11u8_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/c/cups/libcups2_2.4.1-1ubuntu4.4_amd64.deb",
 				LibraryName: "libcups.so",
+				RPM:         &RPMPackage{Name: "cups-libs"},
+				APK:         &APKPackage{Name: "cups-libs"},
 			},
 			{
 				Name:        "libdrm2",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/libd/libdrm/libdrm2_2.4.104-1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libd/libdrm/libdrm2_2.4.113-2~ubuntu0.22.04.1_amd64.deb",
 				LibraryName: "libdrm.so",
+				RPM:         &RPMPackage{Name: "libdrm"},
+				APK:         &APKPackage{Name: "libdrm"},
 			},
 			{
 				Name:        "libgbm1",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/m/mesa/libgbm1_20.3.5-1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/m/mesa/libgbm1_23.2.1-1ubuntu3.1~22.04.2_amd64.deb",
 				LibraryName: "libgbm.so",
+				RPM:         &RPMPackage{Name: "mesa-libgbm"},
+				APK:         &APKPackage{Name: "mesa-gbm"},
 			},
 			{
 				Name:        "libasound2",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/a/alsa-lib/libasound2_1.2.4-1.1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/a/alsa-lib/libasound2_1.2.6.1-1ubuntu1.1_amd64.deb",
 				LibraryName: "libasound.so",
+				RPM:         &RPMPackage{Name: "alsa-lib"},
+				APK:         &APKPackage{Name: "alsa-lib"},
 			},
 			// X11 Libraries yang dibutuhkan Chrome
 			{
@@ -97,151 +187,201 @@ func DefaultConfig() Config {
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/libx/libxcomposite/libxcomposite1_0.4.5-1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libx/libxcomposite/libxcomposite1_0.4.5-1_amd64.deb",
 				LibraryName: "libXcomposite.so",
+				RPM:         &RPMPackage{Name: "libXcomposite"},
+				APK:         &APKPackage{Name: "libxcomposite"},
 			},
 			{
 				Name:        "libxdamage1",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/libx/libxdamage/libxdamage1_1.1.5-2_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libx/libxdamage/libxdamage1_1.1.5-2_amd64.deb",
 				LibraryName: "libXdamage.so",
+				RPM:         &RPMPackage{Name: "libXdamage"},
+				APK:         &APKPackage{Name: "libxdamage"},
 			},
 			{
 				Name:        "libxext6",
 				DebianURL:   "https://ftp.debian.org/debian/pool/main/libx/libxext/libxext6_1.3.3-1.1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libx/libxext/libxext6_1.3.4-1build1_amd64.deb",
 				LibraryName: "libXext.so",
+				RPM:         &RPMPackage{Name: "libXext"},
+				APK:         &APKPackage{Name: "libxext"},
 			},
 			{
 				Name:        "libxfixes3",
 				DebianURL:   "https://ftp.debian.org/debian/pool/main/libx/libxfixes/libxfixes3_6.0.0-2_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libx/libxfixes/libxfixes3_6.0.0-1_amd64.deb",
 				LibraryName: "libXfixes.so",
+				RPM:         &RPMPackage{Name: "libXfixes"},
+				APK:         &APKPackage{Name: "libxfixes"},
 			},
 			{
 				Name:        "libxrandr2",
 				DebianURL:   "https://ftp.debian.org/debian/pool/main/libx/libxrandr/libxrandr2_1.5.1-1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libx/libxrandr/libxrandr2_1.5.2-2build1_amd64.deb",
 				LibraryName: "libXrandr.so",
+				RPM:         &RPMPackage{Name: "libXrandr"},
+				APK:         &APKPackage{Name: "libxrandr"},
 			},
 			{
 				Name:        "libxtst6",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/libx/libxtst/libxtst6_1.2.3-1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libx/libxtst/libxtst6_1.2.3-1build4_amd64.deb",
 				LibraryName: "libXtst.so",
+				RPM:         &RPMPackage{Name: "libXtst"},
+				APK:         &APKPackage{Name: "libxtst"},
 			},
 			{
 				Name:        "libxss1",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/libx/libxss/libxss1_1.2.3-1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libx/libxss/libxss1_1.2.3-1build2_amd64.deb",
 				LibraryName: "libXss.so",
+				RPM:         &RPMPackage{Name: "libXScrnSaver"},
+				APK:         &APKPackage{Name: "libxscrnsaver"},
 			},
 			{
 				Name:        "libcairo2",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/c/cairo/libcairo2_1.16.0-5_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/c/cairo/libcairo2_1.16.0-5ubuntu2_amd64.deb",
 				LibraryName: "libcairo.so",
+				RPM:         &RPMPackage{Name: "cairo"},
+				APK:         &APKPackage{Name: "cairo"},
 			},
 			{
 				Name:        "libpango-1.0-0",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/p/pango1.0/libpango-1.0-0_1.46.2-3_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/p/pango1.0/libpango-1.0-0_1.50.6+ds-2_amd64.deb",
 				LibraryName: "libpango-1.0.so",
+				RPM:         &RPMPackage{Name: "pango"},
+				APK:         &APKPackage{Name: "pango"},
 			},
 			{
 				Name:        "libglib2.0-0",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/g/glib2.0/libglib2.0-0_2.66.8-1+deb11u4_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/g/glib2.0/libglib2.0-0_2.72.1-1_amd64.deb",
 				LibraryName: "libglib-2.0.so",
+				RPM:         &RPMPackage{Name: "glib2"},
+				APK:         &APKPackage{Name: "glib"},
 			},
 			{
 				Name:        "libavahi-common3",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/a/avahi/libavahi-common3_0.8-5+deb11u2_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/a/avahi/libavahi-common3_0.8-5ubuntu5_amd64.deb",
 				LibraryName: "libavahi-common.so",
+				RPM:         &RPMPackage{Name: "avahi-libs"},
+				APK:         &APKPackage{Name: "avahi-libs"},
 			},
 			{
 				Name:        "libavahi-client3",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/a/avahi/libavahi-client3_0.8-5+deb11u2_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/a/avahi/libavahi-client3_0.8-5ubuntu5_amd64.deb",
 				LibraryName: "libavahi-client.so",
+				RPM:         &RPMPackage{Name: "avahi-libs"},
+				APK:         &APKPackage{Name: "avahi-libs"},
 			},
 			{
 				Name:        "libpcre3",
 				DebianURL:   "https://ftp.debian.org/debian/pool/main/p/pcre3/libpcre3_8.39-13_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/p/pcre3/libpcre3_2%3a8.39-13ubuntu0.22.04.1_amd64.deb",
 				LibraryName: "libpcre.so",
+				RPM:         &RPMPackage{Name: "pcre"},
+				APK:         &APKPackage{Name: "pcre"},
 			},
 			// Additional system libraries
 			{
-				Name:        "libffi7",
-				DebianURL:   "http://ftp.debian.org/debian/pool/main/libf/libffi/libffi7_3.3-6_amd64.deb",
+				Name:        "libffi8",
+				DebianURL:   "http://ftp.debian.org/debian/pool/main/libf/libffi/libffi8_3.4.4-1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libf/libffi/libffi8_3.4.2-4_amd64.deb",
 				LibraryName: "libffi.so",
+				RPM:         &RPMPackage{Name: "libffi"},
+				APK:         &APKPackage{Name: "libffi"},
 			},
 			{
 				Name:        "libdbus-1-3",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/d/dbus/libdbus-1-3_1.12.28-0+deb11u1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/d/dbus/libdbus-1-3_1.12.20-2ubuntu4_amd64.deb",
 				LibraryName: "libdbus-1.so",
+				RPM:         &RPMPackage{Name: "dbus-libs"},
+				APK:         &APKPackage{Name: "dbus-libs"},
 			},
 			{
 				Name:        "libexpat1",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/e/expat/libexpat1_2.2.10-2+deb11u5_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/e/expat/libexpat1_2.4.7-1ubuntu0.2_amd64.deb",
 				LibraryName: "libexpat.so",
+				RPM:         &RPMPackage{Name: "expat"},
+				APK:         &APKPackage{Name: "libexpat"},
 			},
 			{
 				Name:        "libx11-6",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/libx/libx11/libx11-6_1.7.2-1+deb11u2_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libx/libx11/libx11-6_1.7.5-1_amd64.deb",
 				LibraryName: "libX11.so",
+				RPM:         &RPMPackage{Name: "libX11"},
+				APK:         &APKPackage{Name: "libx11"},
 			},
 			{
 				Name:        "libxcb1",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/libx/libxcb/libxcb1_1.14-3_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libx/libxcb/libxcb1_1.14-3ubuntu3_amd64.deb",
 				LibraryName: "libxcb.so",
+				RPM:         &RPMPackage{Name: "libxcb"},
+				APK:         &APKPackage{Name: "libxcb"},
 			},
 			{
 				Name:        "libxau6",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/libx/libxau/libxau6_1.0.9-1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libx/libxau/libxau6_1.0.9-1build5_amd64.deb",
 				LibraryName: "libXau.so",
+				RPM:         &RPMPackage{Name: "libXau"},
+				APK:         &APKPackage{Name: "libxau"},
 			},
 			{
 				Name:        "libxdmcp6",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/libx/libxdmcp/libxdmcp6_1.1.2-3_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libx/libxdmcp/libxdmcp6_1.1.3-0ubuntu5_amd64.deb",
 				LibraryName: "libXdmcp.so",
+				RPM:         &RPMPackage{Name: "libXdmcp"},
+				APK:         &APKPackage{Name: "libxdmcp"},
 			},
 			{
 				Name:        "libgdk-pixbuf2.0-0",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/g/gdk-pixbuf/libgdk-pixbuf2.0-0_2.42.2+dfsg-1+deb11u1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/g/gdk-pixbuf/libgdk-pixbuf-2.0-0_2.42.8+dfsg-1ubuntu0.2_amd64.deb",
 				LibraryName: "libgdk_pixbuf-2.0.so",
+				RPM:         &RPMPackage{Name: "gdk-pixbuf2"},
+				APK:         &APKPackage{Name: "gdk-pixbuf"},
 			},
 			{
 				Name:        "libpangocairo-1.0-0",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/p/pango1.0/libpangocairo-1.0-0_1.46.2-3_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/p/pango1.0/libpangocairo-1.0-0_1.50.6+ds-2_amd64.deb",
 				LibraryName: "libpangocairo-1.0.so",
+				RPM:         &RPMPackage{Name: "pango"},
+				APK:         &APKPackage{Name: "pango"},
 			},
 			{
 				Name:        "libgtk-3-0",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/g/gtk+3.0/libgtk-3-0_3.24.24-4+deb11u3_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/g/gtk+3.0/libgtk-3-0_3.24.33-1ubuntu2_amd64.deb",
 				LibraryName: "libgtk-3.so",
+				RPM:         &RPMPackage{Name: "gtk3"},
+				APK:         &APKPackage{Name: "gtk+3.0"},
 			},
 			{
 				Name:        "libxrender1",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/libx/libxrender/libxrender1_0.9.10-1_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libx/libxrender/libxrender1_0.9.10-1build4_amd64.deb",
 				LibraryName: "libXrender.so",
+				RPM:         &RPMPackage{Name: "libXrender"},
+				APK:         &APKPackage{Name: "libxrender"},
 			},
 			{
 				Name:        "libxkbcommon0",
 				DebianURL:   "http://ftp.debian.org/debian/pool/main/libx/libxkbcommon/libxkbcommon0_1.0.3-2_amd64.deb",
 				UbuntuURL:   "http://archive.ubuntu.com/ubuntu/pool/main/libx/libxkbcommon/libxkbcommon0_1.4.0-1_amd64.deb",
 				LibraryName: "libxkbcommon.so",
+				RPM:         &RPMPackage{Name: "libxkbcommon"},
+				APK:         &APKPackage{Name: "libxkbcommon"},
 			},
 		},
 	}
@@ -253,12 +393,24 @@ func NewChromiumManagerWithConfig(config Config, logger func(key, value string))
 		logger = func(key, value string) {}
 	}
 
-	homeDir, _ := os.UserHomeDir()
-	installDir := filepath.Join(homeDir, ".local", "share", config.InstallDirName)
+	rootDir := config.RootDir
+	if rootDir == "" {
+		homeDir, _ := os.UserHomeDir()
+		rootDir = filepath.Join(homeDir, ".local", "share", config.InstallDirName)
+	}
+
+	downloader := NewDownloader(logger)
+	if config.DownloadTimeout > 0 {
+		downloader.Timeout = config.DownloadTimeout
+	}
+	if config.DownloadAttempts > 0 {
+		downloader.MaxAttempts = config.DownloadAttempts
+	}
 
 	return &ChromiumManager{
-		installDir: installDir,
+		rootDir:    rootDir,
 		logger:     logger,
 		config:     config,
+		downloader: downloader,
 	}
 }
@@ -6,39 +6,26 @@ import (
 	"go-rod-testing-browser-restrict/internal/browser"
 )
 
-// Test untuk memastikan config default memiliki URL yang valid
+// Test untuk memastikan config default me-resolve versi dari channel Stable
 func TestDefaultConfig(t *testing.T) {
 	config := browser.DefaultConfig()
 
-	if config.DownloadURL == "" {
-		t.Error("DownloadURL should not be empty")
-	}
-
 	if config.InstallDirName == "" {
 		t.Error("InstallDirName should not be empty")
 	}
 
-	if config.Version == "" {
-		t.Error("Version should not be empty")
+	// Channel kosong = Stable untuk Chrome for Testing, dan tidak ditolak oleh
+	// distribusi yang hanya mendukung Version
+	if config.Channel != "" {
+		t.Errorf("Channel should be empty, got: %s", config.Channel)
 	}
 
-	// Pastikan URL menggunakan v142.7444.229 bukan v142.0.7444.229
-	expectedTag := "v142.7444.229"
-	if !contains(config.DownloadURL, expectedTag) {
-		t.Errorf("DownloadURL should contain %s, got: %s", expectedTag, config.DownloadURL)
+	// Versi dan URL tidak di-hardcode, diisi saat Setup
+	if config.DownloadURL != "" || config.Version != "" {
+		t.Errorf("DownloadURL and Version should be resolved at setup, got: %s %s", config.DownloadURL, config.Version)
 	}
-}
-
-func contains(str, substr string) bool {
-	return len(str) >= len(substr) && (str == substr ||
-		(len(str) > len(substr) && containsHelper(str, substr)))
-}
 
-func containsHelper(str, substr string) bool {
-	for i := 0; i <= len(str)-len(substr); i++ {
-		if str[i:i+len(substr)] == substr {
-			return true
-		}
+	if len(config.Dependencies) == 0 {
+		t.Error("Dependencies should not be empty")
 	}
-	return false
 }
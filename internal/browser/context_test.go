@@ -0,0 +1,52 @@
+package browser_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// SetupContext harus berhenti saat context dibatalkan walaupun server
+// masih mengirim archive dengan lambat.
+func TestSetupContextCancelsDownload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1048576")
+		w.WriteHeader(http.StatusOK)
+		for i := 0; i < 100; i++ {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(50 * time.Millisecond):
+			}
+			w.Write(make([]byte, 1024))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer srv.Close()
+
+	mgr, _ := newTestManager(t, browser.Config{
+		DownloadURL:    srv.URL + "/chrome-linux64.zip",
+		InstallDirName: "chrome-test",
+		Version:        "1.0.0.0",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := mgr.SetupContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("SetupContext returned too late: %v", elapsed)
+	}
+	if mgr.GetExecutablePath() != "" {
+		t.Error("cancelled setup must not report an executable")
+	}
+}
@@ -0,0 +1,206 @@
+package browser
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// debControlLimit membatasi ukuran file control yang dibaca dari control.tar
+const debControlLimit = 1 << 20
+
+// debBackend mengekstrak .deb dengan "dpkg -x"; jika dpkg tidak tersedia,
+// .deb diekstrak dengan pure Go
+type debBackend struct {
+	dpkg   string
+	logger func(key, value string)
+}
+
+func (b *debBackend) Format() string {
+	return PackageDeb
+}
+
+// PackageURL memakai UbuntuURL di Ubuntu (fallback ke DebianURL)
+func (b *debBackend) PackageURL(dep Dependency, osType string) string {
+	if osType == "ubuntu" && dep.UbuntuURL != "" {
+		return dep.UbuntuURL
+	}
+	return dep.DebianURL
+}
+
+func (b *debBackend) Extract(ctx context.Context, debPath, root string) (PackageInfo, error) {
+	file, err := os.Open(debPath)
+	if err != nil {
+		return PackageInfo{}, fmt.Errorf("failed to read deb: %w", err)
+	}
+	defer file.Close()
+
+	// Metadata dibaca sebelum ekstraksi agar .deb yang rusak ditolak sebelum
+	// ada file yang ditulis ke root
+	pkg, err := ReadDebControl(file)
+	if err != nil {
+		return PackageInfo{}, err
+	}
+
+	// Gunakan dpkg -x jika tersedia (lebih reliable)
+	cmd := exec.CommandContext(ctx, b.dpkg, "-x", debPath, root)
+	if err := cmd.Run(); err == nil {
+		return debPackageInfo(pkg), nil
+	}
+	if ctx.Err() != nil {
+		return PackageInfo{}, ctx.Err()
+	}
+
+	// Jika dpkg tidak tersedia, ekstrak manual: .deb dibaca streaming tanpa
+	// dimuat ke memori
+	b.logger("dependencies_dpkg_not_found", "trying manual extraction")
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return PackageInfo{}, fmt.Errorf("failed to read deb: %w", err)
+	}
+	pkg, err = readDeb(file, func(data io.Reader) error {
+		format, err := extractCompressedTar(ctx, data, root)
+		b.logger("dependencies_data_tar", format)
+		return err
+	})
+	if err != nil {
+		return PackageInfo{}, err
+	}
+	return debPackageInfo(pkg), nil
+}
+
+// debPackageInfo mengambil metadata umum dari field control
+func debPackageInfo(pkg *AptPackage) PackageInfo {
+	return PackageInfo{Name: pkg.Name, Version: pkg.Version, Architecture: pkg.Architecture}
+}
+
+// extractCompressedTar mengekstrak tar ke root; kompresi (gz, xz, zst, bz2
+// atau tanpa kompresi) dideteksi dari magic bytes, bukan dari nama file.
+// Format kompresi yang terdeteksi dikembalikan untuk logging.
+func extractCompressedTar(ctx context.Context, r io.Reader, root string) (string, error) {
+	decompressed, format, closeReader, err := decompressReader(r)
+	if err != nil {
+		return format, err
+	}
+	defer closeReader()
+
+	// Path traversal ditolak oleh extractTarTo
+	_, err = extractTarTo(ctx, tar.NewReader(decompressed), root, nil)
+	return format, err
+}
+
+// ReadDebControl membaca metadata package (file control di control.tar)
+// dari .deb tanpa membaca data.tar
+func ReadDebControl(r io.Reader) (*AptPackage, error) {
+	return readDeb(r, nil)
+}
+
+// readDeb membaca .deb secara streaming: debian-binary divalidasi, control.tar
+// diparse dan data.tar diberikan ke extract. Jika extract nil, pembacaan
+// berhenti setelah control.tar.
+func readDeb(r io.Reader, extract func(data io.Reader) error) (*AptPackage, error) {
+	ar, err := NewArReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deb: %w", err)
+	}
+
+	var pkg *AptPackage
+	first := true
+	for {
+		hdr, err := ar.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read deb: %w", err)
+		}
+
+		if first {
+			if err := checkDebianBinary(hdr, ar); err != nil {
+				return nil, err
+			}
+			first = false
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(hdr.Name, "control.tar"):
+			if pkg, err = readDebControlTar(ar); err != nil {
+				return nil, err
+			}
+			if extract == nil {
+				return pkg, nil
+			}
+		case strings.HasPrefix(hdr.Name, "data.tar"):
+			if extract == nil {
+				continue
+			}
+			if pkg == nil {
+				return nil, fmt.Errorf("data.tar before control.tar in deb package")
+			}
+			return pkg, extract(ar)
+		}
+	}
+
+	if first {
+		return nil, fmt.Errorf("empty deb package")
+	}
+	if pkg == nil {
+		return nil, fmt.Errorf("control.tar not found in deb package")
+	}
+	return nil, fmt.Errorf("data.tar not found in deb package")
+}
+
+// checkDebianBinary memastikan member pertama adalah debian-binary versi 2.x
+func checkDebianBinary(hdr *ArHeader, r io.Reader) error {
+	if hdr.Name != "debian-binary" {
+		return fmt.Errorf("not a deb package: first member is %q", hdr.Name)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, 64))
+	if err != nil {
+		return fmt.Errorf("failed to read debian-binary: %w", err)
+	}
+	if !strings.HasPrefix(string(data), "2.") {
+		return fmt.Errorf("unsupported deb format %q", strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// readDebControlTar mencari file control di control.tar (kompresi apa pun)
+// dan memparse field-nya
+func readDebControlTar(r io.Reader) (*AptPackage, error) {
+	decompressed, _, closeReader, err := decompressReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read control.tar: %w", err)
+	}
+	defer closeReader()
+
+	tarReader := tar.NewReader(decompressed)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("control file not found in control.tar")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read control.tar: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || path.Clean(strings.TrimPrefix(header.Name, "./")) != "control" {
+			continue
+		}
+
+		idx := NewPackageIndex()
+		if err := idx.parse(io.LimitReader(tarReader, debControlLimit)); err != nil {
+			return nil, fmt.Errorf("invalid control file: %w", err)
+		}
+		for _, pkgs := range idx.packages {
+			if len(pkgs) == 1 && idx.Len() == 1 {
+				return pkgs[0], nil
+			}
+		}
+		return nil, fmt.Errorf("control file must contain exactly one package")
+	}
+}
@@ -1,36 +1,65 @@
 package browser
 
 import (
-	"archive/tar"
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
-
-	"github.com/ulikunitz/xz"
 )
 
 // DependencyManager mengelola download dan ekstraksi dependencies
 type DependencyManager struct {
-	libDir string
-	logger func(key, value string)
+	// AptSources berisi repository APT per OS ("debian", "ubuntu"). Jika OS
+	// yang terdeteksi ada di sini, dependency diresolve dari index repository;
+	// URL di Dependency hanya dipakai jika index gagal diambil.
+	AptSources map[string]AptSource
+	// RPMSources dan APKSources berisi repository per OS ("fedora", "rhel",
+	// "alpine") untuk meresolve RPMPackage.Name dan APKPackage.Name; URL di
+	// package hanya dipakai jika nama tidak ditemukan di index.
+	RPMSources map[string]RPMSource
+	APKSources map[string]APKSource
+	// StatusFile adalah file status dpkg untuk package yang sudah terinstall
+	// di sistem; kosong berarti /var/lib/dpkg/status
+	StatusFile string
+	// DpkgPath adalah perintah dpkg untuk "dpkg -x"; kosong berarti "dpkg"
+	// dari PATH. Jika gagal dijalankan, .deb diekstrak dengan pure Go.
+	DpkgPath string
+	// GpgvPath adalah perintah gpgv untuk memverifikasi InRelease jika
+	// AptSource.Keyring diset; kosong berarti "gpgv" dari PATH
+	GpgvPath string
+	// OSReleaseFile dipakai untuk memilih backend package (deb, rpm, apk);
+	// kosong berarti /etc/os-release
+	OSReleaseFile string
+
+	libDir     string
+	logger     func(key, value string)
+	downloader *Downloader
 }
 
 // NewDependencyManager membuat instance baru DependencyManager
 func NewDependencyManager(libDir string, logger func(key, value string)) *DependencyManager {
+	if logger == nil {
+		logger = func(key, value string) {}
+	}
+
 	return &DependencyManager{
-		libDir: libDir,
-		logger: logger,
+		libDir:     libDir,
+		logger:     logger,
+		downloader: NewDownloader(logger),
 	}
 }
 
 // Setup mengecek dan mengunduh dependencies jika belum ada
 func (dm *DependencyManager) Setup(dependencies []Dependency) error {
+	return dm.SetupContext(context.Background(), dependencies)
+}
+
+// SetupContext sama dengan Setup, tetapi download dan ekstraksi dibatalkan saat ctx selesai
+func (dm *DependencyManager) SetupContext(ctx context.Context, dependencies []Dependency) error {
 	dm.logger("dependencies_lib_dir", dm.libDir)
 
 	// Buat direktori lib jika belum ada
@@ -38,20 +67,99 @@ func (dm *DependencyManager) Setup(dependencies []Dependency) error {
 		return fmt.Errorf("failed to create lib directory: %w", err)
 	}
 
-	// Deteksi OS
+	// Deteksi OS dan pilih backend package sesuai distro
 	osType := dm.detectOS()
 	dm.logger("dependencies_os_detected", osType)
 	dm.logger("dependencies_arch", runtime.GOARCH)
 
+	backend := dm.backendFor(osType)
+	if backend == nil {
+		// Lebih baik tidak mengunduh apa pun daripada mencampur library distro lain
+		dm.logger("dependencies_unsupported_os", osType)
+		return nil
+	}
+	dm.logger("dependencies_backend", backend.Format())
+
+	if source, ok := dm.AptSources[osType]; ok && backend.Format() == PackageDeb {
+		fallback, err := dm.setupFromApt(ctx, source, dependencies)
+		var mismatch *ChecksumMismatchError
+		var signature *SignatureError
+		switch {
+		case err == nil && len(fallback) == 0:
+			return nil
+		case err == nil:
+			// Hanya nama yang tidak ada di index yang diambil dari URL
+			names := make([]string, len(fallback))
+			for i, dep := range fallback {
+				names[i] = dep.Name
+			}
+			dm.logger("dependencies_apt_fallback", strings.Join(names, " "))
+			dependencies = fallback
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.As(err, &mismatch), errors.As(err, &signature):
+			// Index atau .deb yang tidak cocok dengan Release/index tidak
+			// boleh diganti dengan URL lain
+			return err
+		default:
+			dm.logger("dependencies_apt_error", err.Error())
+			dm.logger("dependencies_apt_fallback", "using dependency urls")
+		}
+	}
+
+	// RPM/APK: resolve nama package dari index repository, URL hanya fallback
+	resolved, err := dm.resolveRepoPackages(ctx, osType, backend.Format(), dependencies)
+	if err != nil {
+		var mismatch *ChecksumMismatchError
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.As(err, &mismatch):
+			return err
+		}
+		dm.logger("dependencies_repo_error", err.Error())
+		dm.logger("dependencies_repo_fallback", "using dependency urls")
+	}
+
 	// Cek dan download dependencies yang belum ada
 	for _, dep := range dependencies {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		if dm.isLibraryInstalled(dep.LibraryName) {
 			dm.logger("dependencies_skip", fmt.Sprintf("%s (already installed)", dep.Name))
 			continue
 		}
 
+		if pkg, ok := resolved[dep.Name]; ok {
+			dm.logger("dependencies_downloading", dep.Name)
+			if err := dm.installRepoPackage(ctx, backend, dep, pkg); err != nil {
+				var mismatch *ChecksumMismatchError
+				switch {
+				case ctx.Err() != nil:
+					return ctx.Err()
+				case errors.As(err, &mismatch):
+					return err
+				}
+				dm.logger("dependencies_error", fmt.Sprintf("%s: %v", dep.Name, err))
+				continue
+			}
+			dm.logger("dependencies_installed", pkg.Name+"="+pkg.Version)
+			continue
+		}
+
+		url := backend.PackageURL(dep, osType)
+		if url == "" {
+			dm.logger("dependencies_no_package", fmt.Sprintf("%s (%s)", dep.Name, backend.Format()))
+			continue
+		}
+
 		dm.logger("dependencies_downloading", dep.Name)
-		if err := dm.downloadAndExtractDep(dep, osType); err != nil {
+		if err := dm.downloadAndExtractDep(ctx, backend, dep, url); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			dm.logger("dependencies_error", fmt.Sprintf("%s: %v", dep.Name, err))
 			// Continue dengan dependency lain
 			continue
@@ -62,203 +170,84 @@ func (dm *DependencyManager) Setup(dependencies []Dependency) error {
 	return nil
 }
 
-// detectOS mendeteksi jenis OS (debian/ubuntu)
-func (dm *DependencyManager) detectOS() string {
-	// Coba baca /etc/os-release
-	data, err := os.ReadFile("/etc/os-release")
-	if err != nil {
-		return "debian" // default
-	}
-
-	content := string(data)
-	if strings.Contains(strings.ToLower(content), "ubuntu") {
-		return "ubuntu"
-	}
-	if strings.Contains(strings.ToLower(content), "debian") {
-		return "debian"
-	}
-
-	return "debian" // default
+// systemLibraryDirs adalah direktori library sistem yang dicek oleh
+// MissingDependencies selain libDir
+var systemLibraryDirs = []string{
+	"/lib", "/lib64", "/usr/lib", "/usr/lib64",
+	"/lib/x86_64-linux-gnu", "/usr/lib/x86_64-linux-gnu",
+	"/lib/aarch64-linux-gnu", "/usr/lib/aarch64-linux-gnu",
 }
 
-// isLibraryInstalled mengecek apakah library sudah terinstall
-func (dm *DependencyManager) isLibraryInstalled(libName string) bool {
-	// Cek di libDir
-	pattern := filepath.Join(dm.libDir, "**", libName+"*")
-	matches, _ := filepath.Glob(pattern)
-	if len(matches) > 0 {
-		return true
+// MissingDependencies mengembalikan dependency yang library-nya tidak ada
+// di libDir maupun di direktori library sistem
+func (dm *DependencyManager) MissingDependencies(dependencies []Dependency) []Dependency {
+	var missing []Dependency
+	for _, dep := range dependencies {
+		if dm.isLibraryInstalled(dep.LibraryName) || isSystemLibrary(dep.LibraryName) {
+			continue
+		}
+		missing = append(missing, dep)
 	}
+	return missing
+}
 
-	// Cek dengan find command (lebih akurat)
-	libPath := filepath.Join(dm.libDir, "usr", "lib")
-	if _, err := os.Stat(libPath); err == nil {
-		cmd := exec.Command("find", libPath, "-name", libName+"*")
-		output, err := cmd.Output()
-		if err == nil && len(output) > 0 {
+// isSystemLibrary mengecek apakah libName (atau versinya, contoh libnss3.so.1)
+// ada di salah satu systemLibraryDirs
+func isSystemLibrary(libName string) bool {
+	for _, dir := range systemLibraryDirs {
+		matches, _ := filepath.Glob(filepath.Join(dir, libName+"*"))
+		if len(matches) > 0 {
 			return true
 		}
 	}
-
 	return false
 }
 
-// downloadAndExtractDep download dan ekstrak .deb package
-func (dm *DependencyManager) downloadAndExtractDep(dep Dependency, osType string) error {
-	// Pilih URL berdasarkan OS
-	url := dep.DebianURL
-	if osType == "ubuntu" && dep.UbuntuURL != "" {
-		url = dep.UbuntuURL
-	}
+// isLibraryInstalled mengecek apakah library (atau versinya) sudah ada di
+// mana pun di libDir (usr/lib/<triplet>, usr/lib64, lib, ...)
+func (dm *DependencyManager) isLibraryInstalled(libName string) bool {
+	found := false
+	filepath.WalkDir(dm.libDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), libName) {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
 
+// downloadAndExtractDep download package dep dari url lalu mengekstraknya
+// dengan backend
+func (dm *DependencyManager) downloadAndExtractDep(ctx context.Context, backend PackageBackend, dep Dependency, url string) error {
 	dm.logger("dependencies_url", url)
 
-	// Download .deb file
-	resp, err := http.Get(url)
-	if err != nil {
+	// Download package (resume + retry)
+	tmpFile := filepath.Join(dm.libDir, fmt.Sprintf(".%s.%s", dep.Name, backend.Format()))
+	if err := dm.downloader.DownloadContext(ctx, url, tmpFile, nil); err != nil {
 		return fmt.Errorf("failed to download: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download: status %d", resp.StatusCode)
-	}
-
-	// Baca data
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Ekstrak .deb menggunakan pure Go atau dpkg -x
-	return dm.extractDeb(data, dep.Name)
-}
-
-// extractDeb mengekstrak .deb package
-func (dm *DependencyManager) extractDeb(data []byte, name string) error {
-	// Simpan ke file temporary
-	tmpFile := filepath.Join(dm.libDir, fmt.Sprintf(".%s.deb", name))
-	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write temp file: %w", err)
-	}
 	defer os.Remove(tmpFile)
 
-	dm.logger("dependencies_extracting", name)
-
-	// Gunakan dpkg -x jika tersedia (lebih reliable)
-	cmd := exec.Command("dpkg", "-x", tmpFile, dm.libDir)
-	if err := cmd.Run(); err != nil {
-		// Jika dpkg tidak tersedia, coba ekstrak manual
-		dm.logger("dependencies_dpkg_not_found", "trying manual extraction")
-		return dm.extractDebManual(data)
-	}
-
-	return nil
+	return dm.extractPackage(ctx, backend, tmpFile, dep.Name)
 }
 
-// extractDebManual mengekstrak .deb secara manual (pure Go)
-func (dm *DependencyManager) extractDebManual(data []byte) error {
-	// .deb adalah ar archive yang berisi:
-	// - debian-binary
-	// - control.tar.xz (metadata)
-	// - data.tar.xz (actual files)
-
-	// Kita perlu extract data.tar.xz
-	reader := bytes.NewReader(data)
-
-	// Skip ar header (8 bytes: "!<arch>\n")
-	header := make([]byte, 8)
-	if _, err := reader.Read(header); err != nil {
-		return fmt.Errorf("failed to read ar header: %w", err)
-	}
-
-	// Parse ar entries
-	for {
-		// Read ar entry header (60 bytes)
-		entryHeader := make([]byte, 60)
-		n, err := reader.Read(entryHeader)
-		if err == io.EOF {
-			break
-		}
-		if err != nil || n != 60 {
-			break
-		}
-
-		// Parse filename (first 16 bytes)
-		filename := strings.TrimSpace(string(entryHeader[0:16]))
-
-		// Parse file size (bytes 48-58)
-		sizeStr := strings.TrimSpace(string(entryHeader[48:58]))
-		var size int64
-		fmt.Sscanf(sizeStr, "%d", &size)
-
-		// Read file data
-		fileData := make([]byte, size)
-		if _, err := io.ReadFull(reader, fileData); err != nil {
-			return fmt.Errorf("failed to read ar entry: %w", err)
-		}
-
-		// Align to 2 bytes
-		if size%2 == 1 {
-			reader.ReadByte()
-		}
-
-		// Process data.tar.xz
-		if strings.Contains(filename, "data.tar") {
-			return dm.extractDataTar(fileData, filename)
-		}
-	}
-
-	return fmt.Errorf("data.tar not found in deb package")
+// extractDeb mengekstrak .deb package (dpkg -x atau pure Go)
+func (dm *DependencyManager) extractDeb(ctx context.Context, debPath string, name string) error {
+	return dm.extractPackage(ctx, dm.backendFor("debian"), debPath, name)
 }
 
-// extractDataTar ekstrak data.tar.xz atau data.tar.gz
-func (dm *DependencyManager) extractDataTar(data []byte, filename string) error {
-	var tarReader *tar.Reader
-
-	if strings.HasSuffix(filename, ".xz") || strings.Contains(filename, "tar.xz") {
-		// Decompress xz
-		xzReader, err := xz.NewReader(bytes.NewReader(data))
-		if err != nil {
-			return fmt.Errorf("failed to create xz reader: %w", err)
-		}
-		tarReader = tar.NewReader(xzReader)
-	} else if strings.HasSuffix(filename, ".gz") || strings.Contains(filename, "tar.gz") {
-		// TODO: Add gzip support if needed
-		return fmt.Errorf("gzip format not yet supported")
-	} else {
-		// Plain tar
-		tarReader = tar.NewReader(bytes.NewReader(data))
-	}
-
-	// Extract tar
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("failed to read tar: %w", err)
-		}
-
-		target := filepath.Join(dm.libDir, header.Name)
-
-		switch header.Typeflag {
-		case tar.TypeDir:
-			os.MkdirAll(target, 0o755)
-		case tar.TypeReg:
-			os.MkdirAll(filepath.Dir(target), 0o755)
-			outFile, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(header.Mode))
-			if err != nil {
-				continue
-			}
-			io.Copy(outFile, tarReader)
-			outFile.Close()
-		case tar.TypeSymlink:
-			os.Symlink(header.Linkname, target)
-		}
+// extractPackage mengekstrak file package ke libDir dengan backend
+func (dm *DependencyManager) extractPackage(ctx context.Context, backend PackageBackend, path, name string) error {
+	dm.logger("dependencies_extracting", name)
+	info, err := backend.Extract(ctx, path, dm.libDir)
+	if err != nil {
+		return err
 	}
-
+	dm.logger("dependencies_package", fmt.Sprintf("%s=%s (%s)", info.Name, info.Version, backend.Format()))
 	return nil
 }
 
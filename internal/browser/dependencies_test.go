@@ -0,0 +1,173 @@
+package browser_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// dataTarBz2 adalah data.tar (bzip2) berisi usr/lib/x86_64-linux-gnu/libbzip.so.1;
+// compress/bzip2 hanya bisa membaca sehingga fixture disimpan sudah terkompres
+const dataTarBz2 = "QlpoOTFBWSZTWUyPyrUAAGX/gMmAACBAA//gAgAAAPSl3nAICCAAlISimUaNNBob1IzQNTanoJKU2ppmgAmRgRg0fI6iVNSA+wpIQ2j8VQOCWxg4iEIYAxcHo7z1jLqKxmNWCVighhBwhxeXdr1wGV7qvbLidMSFLrhSoUtkNFLddD8hOKOJyOROmjTmPIZhQPJB54GkBDPWJB2LuSKcKEgmR+VagA=="
+
+// buildDataTar membuat data.tar tanpa kompresi berisi satu library
+func buildDataTar(t *testing.T, lib string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "./usr/lib/x86_64-linux-gnu/", Typeflag: tar.TypeDir, Mode: 0o755})
+	tw.WriteHeader(&tar.Header{Name: "./usr/lib/x86_64-linux-gnu/" + lib, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len("payload"))})
+	tw.Write([]byte("payload"))
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// compressTar mengompres tarball dengan format gzip, xz atau zstd
+func compressTar(t *testing.T, format string, tarball []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch format {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "xz":
+		w, err = xz.NewWriter(&buf)
+	case "zstd":
+		w, err = zstd.NewWriter(&buf)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(tarball)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// Test ekstraksi pure Go (tanpa dpkg) untuk semua kompresi data.tar; format
+// dideteksi dari isi, bukan dari nama member
+func TestExtractDebManualCompression(t *testing.T) {
+	bz2, err := base64.StdEncoding.DecodeString(dataTarBz2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		member string
+		lib    string
+		data   func(tarball []byte) []byte
+	}{
+		{"gzip", "data.tar.gz", "libgzip.so.1", func(b []byte) []byte { return compressTar(t, "gzip", b) }},
+		{"xz", "data.tar.xz", "libxz.so.1", func(b []byte) []byte { return compressTar(t, "xz", b) }},
+		{"zstd", "data.tar.zst", "libzstd.so.1", func(b []byte) []byte { return compressTar(t, "zstd", b) }},
+		{"bzip2", "data.tar.bz2", "libbzip.so.1", func([]byte) []byte { return bz2 }},
+		{"plain", "data.tar", "libplain.so.1", func(b []byte) []byte { return b }},
+		// Nama member tidak sesuai isi: tetap dideteksi dari magic bytes
+		{"misnamed", "data.tar.xz", "libmisnamed.so.1", func(b []byte) []byte { return compressTar(t, "zstd", b) }},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			deb := buildAr(
+				[2]string{"debian-binary", "2.0\n"},
+				[2]string{"control.tar.gz", string(buildControlTar(t, "libtest", "1.0-1"))},
+				[2]string{tc.member, string(tc.data(buildDataTar(t, tc.lib)))},
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write(deb)
+			}))
+			defer srv.Close()
+
+			libDir := filepath.Join(t.TempDir(), "libs")
+			var errs []string
+			dm := browser.NewDependencyManager(libDir, func(key, value string) {
+				if key == "dependencies_error" {
+					errs = append(errs, value)
+				}
+			})
+			dm.DpkgPath = filepath.Join(t.TempDir(), "dpkg-missing")
+			dm.OSReleaseFile = writeOSRelease(t, "ID=ubuntu\n")
+
+			err := dm.SetupContext(context.Background(), []browser.Dependency{
+				{Name: "libtest", LibraryName: tc.lib, DebianURL: srv.URL + "/test.deb", UbuntuURL: srv.URL + "/test.deb"},
+			})
+			if err != nil || len(errs) > 0 {
+				t.Fatalf("SetupContext: %v %s", err, strings.Join(errs, "; "))
+			}
+			data, err := os.ReadFile(filepath.Join(libDir, "usr", "lib", "x86_64-linux-gnu", tc.lib))
+			if err != nil || string(data) != "payload" {
+				t.Errorf("extracted %s = %q, %v", tc.lib, data, err)
+			}
+		})
+	}
+}
+
+// Test .deb dengan control yang rusak ditolak sebelum dpkg -x menulis file
+// ke libDir
+func TestExtractDebRejectsInvalidControlBeforeDpkg(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake dpkg is a shell script")
+	}
+	var emptyControl bytes.Buffer
+	gw := gzip.NewWriter(&emptyControl)
+	tw := tar.NewWriter(gw)
+	tw.WriteHeader(&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0o755})
+	tw.Close()
+	gw.Close()
+	deb := buildAr(
+		[2]string{"debian-binary", "2.0\n"},
+		[2]string{"control.tar.gz", emptyControl.String()},
+		[2]string{"data.tar", string(buildDataTar(t, "libtest.so.1"))},
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(deb)
+	}))
+	defer srv.Close()
+
+	// dpkg palsu yang menulis penanda jika dijalankan
+	dpkg := filepath.Join(t.TempDir(), "dpkg")
+	if err := os.WriteFile(dpkg, []byte("#!/bin/sh\nmkdir -p \"$3\" && touch \"$3/dpkg-ran\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	libDir := filepath.Join(t.TempDir(), "libs")
+	var errs []string
+	dm := browser.NewDependencyManager(libDir, func(key, value string) {
+		if key == "dependencies_error" {
+			errs = append(errs, value)
+		}
+	})
+	dm.DpkgPath = dpkg
+	dm.OSReleaseFile = writeOSRelease(t, "ID=ubuntu\n")
+	if err := dm.SetupContext(context.Background(), []browser.Dependency{
+		{Name: "libtest", LibraryName: "libtest.so.1", UbuntuURL: srv.URL + "/test.deb"},
+	}); err != nil {
+		t.Fatalf("SetupContext: %v", err)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0], "control file not found") {
+		t.Errorf("expected control error, got %v", errs)
+	}
+	if _, err := os.Stat(filepath.Join(libDir, "dpkg-ran")); err == nil {
+		t.Error("dpkg -x should not run for a package with invalid control")
+	}
+}
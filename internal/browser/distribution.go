@@ -0,0 +1,148 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArchiveFormat adalah format arsip browser yang didownload
+type ArchiveFormat string
+
+const (
+	FormatZip   ArchiveFormat = "zip"
+	FormatTarXz ArchiveFormat = "tar.xz"
+)
+
+// Nama distribusi bawaan untuk Config.Distribution
+const (
+	DistChromeForTesting = "chrome-for-testing"
+	DistHeadlessShell    = "chrome-headless-shell"
+	DistChromiumSnapshot = "chromium-snapshot"
+	DistUngoogled        = "ungoogled-chromium"
+)
+
+// Release adalah hasil resolusi sebuah distribusi: versi exact, lokasi
+// download, format arsip dan path executable di dalam arsip
+type Release struct {
+	Version     string        `json:"version"`
+	Revision    string        `json:"revision,omitempty"`
+	DownloadURL string        `json:"url"`
+	Format      ArchiveFormat `json:"format"`
+	Executable  string        `json:"executable,omitempty"`
+}
+
+// Distribution adalah sumber build browser (Chrome for Testing, Chromium
+// snapshot, dll). Resolve menerjemahkan Channel/Milestone/Version di Config
+// menjadi Release untuk platform saat ini.
+type Distribution interface {
+	// Name mengembalikan nama distribusi (dipakai di log dan cache)
+	Name() string
+
+	// Resolve mengembalikan rilis yang sesuai dengan config
+	Resolve(ctx context.Context, config Config) (Release, error)
+}
+
+// metadataTimeout membatasi satu request metadata distribusi
+const metadataTimeout = 60 * time.Second
+
+// distributions berisi distribusi bawaan berdasarkan nama
+var distributions = map[string]func(baseURL string, client *http.Client) Distribution{
+	DistChromeForTesting: func(baseURL string, client *http.Client) Distribution {
+		return &chromeForTesting{binary: "chrome", baseURL: baseURL, client: client}
+	},
+	DistHeadlessShell: func(baseURL string, client *http.Client) Distribution {
+		return &chromeForTesting{binary: "chrome-headless-shell", baseURL: baseURL, client: client}
+	},
+	DistChromiumSnapshot: func(baseURL string, client *http.Client) Distribution {
+		return &chromiumSnapshot{baseURL: baseURL, client: client}
+	},
+	DistUngoogled: func(baseURL string, client *http.Client) Distribution {
+		return &ungoogledChromium{baseURL: baseURL, client: client}
+	},
+}
+
+// DistributionByName mengembalikan distribusi bawaan. baseURL (opsional)
+// mengganti lokasi endpoint metadata distribusi tersebut.
+func DistributionByName(name, baseURL string) (Distribution, error) {
+	return newDistribution(name, baseURL, nil)
+}
+
+// newDistribution seperti DistributionByName, dengan client untuk request
+// metadata (nil = http.DefaultClient)
+func newDistribution(name, baseURL string, client *http.Client) (Distribution, error) {
+	if name == "" {
+		name = DistChromeForTesting
+	}
+	factory, ok := distributions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown browser distribution %q (available: %s)", name, strings.Join(DistributionNames(), ", "))
+	}
+	return factory(strings.TrimRight(baseURL, "/"), client), nil
+}
+
+// DistributionNames mengembalikan nama semua distribusi bawaan
+func DistributionNames() []string {
+	names := make([]string, 0, len(distributions))
+	for name := range distributions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatFromURL menebak format arsip dari akhiran URL
+func formatFromURL(url string) ArchiveFormat {
+	switch {
+	case strings.HasSuffix(url, ".zip"):
+		return FormatZip
+	case strings.HasSuffix(url, ".tar.xz"):
+		return FormatTarXz
+	}
+	return ""
+}
+
+// fetchMetadata mengirim GET ke url dengan client (nil = http.DefaultClient)
+// dan batas waktu metadataTimeout. cancel harus dipanggil setelah body dibaca.
+func fetchMetadata(ctx context.Context, client *http.Client, url string) (*http.Response, context.CancelFunc, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return nil, nil, fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return resp, cancel, nil
+}
+
+// fetchJSON mengunduh dan men-decode JSON dari url
+func fetchJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	resp, cancel, err := fetchMetadata(ctx, client, url)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// Channel Chrome for Testing yang bisa dipakai di Config.Channel
+const (
+	ChannelStable = "Stable"
+	ChannelBeta   = "Beta"
+	ChannelDev    = "Dev"
+	ChannelCanary = "Canary"
+)
+
+// DefaultVersionsBaseURL adalah lokasi endpoint JSON Chrome for Testing
+const DefaultVersionsBaseURL = "https://googlechromelabs.github.io/chrome-for-testing"
+
+// Struktur JSON Chrome for Testing (*-with-downloads.json)
+type cftDownload struct {
+	Platform string `json:"platform"`
+	URL      string `json:"url"`
+}
+
+type cftRelease struct {
+	Version   string                   `json:"version"`
+	Revision  string                   `json:"revision"`
+	Downloads map[string][]cftDownload `json:"downloads"`
+}
+
+type cftChannels struct {
+	Channels map[string]cftRelease `json:"channels"`
+}
+
+type cftMilestones struct {
+	Milestones map[string]cftRelease `json:"milestones"`
+}
+
+type cftKnownGood struct {
+	Versions []cftRelease `json:"versions"`
+}
+
+// chromeForTesting adalah distribusi Chrome for Testing; binary menentukan
+// artefak yang dipakai ("chrome" atau "chrome-headless-shell")
+type chromeForTesting struct {
+	binary  string
+	baseURL string
+	client  *http.Client
+}
+
+func (d *chromeForTesting) Name() string {
+	if d.binary == "chrome-headless-shell" {
+		return DistHeadlessShell
+	}
+	return DistChromeForTesting
+}
+
+// Resolve memakai last-known-good-versions (channel), latest-versions-per-milestone
+// (milestone) atau known-good-versions (versi exact). Tanpa ketiganya, channel Stable dipakai.
+func (d *chromeForTesting) Resolve(ctx context.Context, config Config) (Release, error) {
+	baseURL := d.baseURL
+	if baseURL == "" {
+		baseURL = DefaultVersionsBaseURL
+	}
+
+	var release cftRelease
+	var found bool
+	var query string
+	switch {
+	case config.Milestone != "" && config.Channel == "":
+		query = "milestone " + config.Milestone
+		var data cftMilestones
+		if err := fetchJSON(ctx, d.client, baseURL+"/latest-versions-per-milestone-with-downloads.json", &data); err != nil {
+			return Release{}, err
+		}
+		release, found = data.Milestones[config.Milestone]
+	case config.Version != "" && config.Channel == "":
+		query = "version " + config.Version
+		var data cftKnownGood
+		if err := fetchJSON(ctx, d.client, baseURL+"/known-good-versions-with-downloads.json", &data); err != nil {
+			return Release{}, err
+		}
+		for _, v := range data.Versions {
+			if v.Version == config.Version {
+				release, found = v, true
+				break
+			}
+		}
+	default:
+		channel := normalizeChannel(config.Channel)
+		if channel == "" {
+			channel = ChannelStable
+		}
+		query = "channel " + channel
+		var data cftChannels
+		if err := fetchJSON(ctx, d.client, baseURL+"/last-known-good-versions-with-downloads.json", &data); err != nil {
+			return Release{}, err
+		}
+		release, found = data.Channels[channel]
+	}
+	if !found {
+		return Release{}, fmt.Errorf("%s not found", query)
+	}
+
+	platform, err := cftPlatform()
+	if err != nil {
+		return Release{}, err
+	}
+
+	for _, dl := range release.Downloads[d.binary] {
+		if dl.Platform == platform {
+			return Release{
+				Version:     release.Version,
+				Revision:    release.Revision,
+				DownloadURL: dl.URL,
+				Format:      FormatZip,
+				Executable:  cftExecutable(d.binary, platform),
+			}, nil
+		}
+	}
+
+	return Release{}, fmt.Errorf("no %s download for platform %s in version %s", d.binary, platform, release.Version)
+}
+
+// cftPlatform mengembalikan nama platform Chrome for Testing untuk host ini
+func cftPlatform() (string, error) {
+	switch runtime.GOOS + "/" + runtime.GOARCH {
+	case "linux/amd64":
+		return "linux64", nil
+	case "darwin/amd64":
+		return "mac-x64", nil
+	case "darwin/arm64":
+		return "mac-arm64", nil
+	case "windows/amd64":
+		return "win64", nil
+	case "windows/386":
+		return "win32", nil
+	}
+	return "", fmt.Errorf("chrome for testing is not available for %s/%s", runtime.GOOS, runtime.GOARCH)
+}
+
+// cftExecutable mengembalikan path executable di dalam arsip Chrome for Testing
+func cftExecutable(binary, platform string) string {
+	dir := binary + "-" + platform
+	switch {
+	case binary == "chrome" && strings.HasPrefix(platform, "mac"):
+		return dir + "/Google Chrome for Testing.app/Contents/MacOS/Google Chrome for Testing"
+	case strings.HasPrefix(platform, "win"):
+		return dir + "/" + binary + ".exe"
+	}
+	return dir + "/" + binary
+}
+
+// normalizeChannel mengubah "stable"/"STABLE" menjadi "Stable"
+func normalizeChannel(channel string) string {
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	if channel == "" {
+		return ""
+	}
+	return strings.ToUpper(channel[:1]) + channel[1:]
+}
@@ -0,0 +1,143 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// DefaultSnapshotBaseURL adalah lokasi build snapshot Chromium
+const DefaultSnapshotBaseURL = "https://commondatastorage.googleapis.com/chromium-browser-snapshots"
+
+// DefaultUngoogledBaseURL adalah GitHub API untuk rilis Ungoogled Chromium portable
+const DefaultUngoogledBaseURL = "https://api.github.com/repos/ungoogled-software/ungoogled-chromium-portablelinux"
+
+// chromiumSnapshot adalah distribusi build snapshot Chromium (per revisi).
+// Config.Version berisi nomor revisi; kosong = LAST_CHANGE.
+type chromiumSnapshot struct {
+	baseURL string
+	client  *http.Client
+}
+
+func (d *chromiumSnapshot) Name() string { return DistChromiumSnapshot }
+
+func (d *chromiumSnapshot) Resolve(ctx context.Context, config Config) (Release, error) {
+	if config.Channel != "" || config.Milestone != "" {
+		return Release{}, fmt.Errorf("%s only supports Version (revision number)", d.Name())
+	}
+
+	baseURL := d.baseURL
+	if baseURL == "" {
+		baseURL = DefaultSnapshotBaseURL
+	}
+
+	var platformDir, archive, executable string
+	switch runtime.GOOS + "/" + runtime.GOARCH {
+	case "linux/amd64":
+		platformDir, archive, executable = "Linux_x64", "chrome-linux", "chrome-linux/chrome"
+	case "darwin/amd64":
+		platformDir, archive, executable = "Mac", "chrome-mac", "chrome-mac/Chromium.app/Contents/MacOS/Chromium"
+	case "darwin/arm64":
+		platformDir, archive, executable = "Mac_Arm", "chrome-mac", "chrome-mac/Chromium.app/Contents/MacOS/Chromium"
+	case "windows/amd64":
+		platformDir, archive, executable = "Win_x64", "chrome-win", "chrome-win/chrome.exe"
+	default:
+		return Release{}, fmt.Errorf("chromium snapshots are not available for %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+
+	revision := config.Version
+	if revision == "" {
+		var err error
+		revision, err = fetchText(ctx, d.client, baseURL+"/"+platformDir+"/LAST_CHANGE")
+		if err != nil {
+			return Release{}, err
+		}
+	}
+
+	return Release{
+		Version:     revision,
+		Revision:    revision,
+		DownloadURL: fmt.Sprintf("%s/%s/%s/%s.zip", baseURL, platformDir, revision, archive),
+		Format:      FormatZip,
+		Executable:  executable,
+	}, nil
+}
+
+// ungoogledChromium adalah distribusi tarball portable Ungoogled Chromium
+// dari GitHub releases. Config.Version berisi tag rilis; kosong = rilis terbaru.
+type ungoogledChromium struct {
+	baseURL string
+	client  *http.Client
+}
+
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+func (d *ungoogledChromium) Name() string { return DistUngoogled }
+
+func (d *ungoogledChromium) Resolve(ctx context.Context, config Config) (Release, error) {
+	if config.Channel != "" || config.Milestone != "" {
+		return Release{}, fmt.Errorf("%s only supports Version (release tag)", d.Name())
+	}
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		return Release{}, fmt.Errorf("ungoogled chromium portable is only available for linux/amd64")
+	}
+
+	baseURL := d.baseURL
+	if baseURL == "" {
+		baseURL = DefaultUngoogledBaseURL
+	}
+
+	url := baseURL + "/releases/latest"
+	if config.Version != "" {
+		url = baseURL + "/releases/tags/" + config.Version
+	}
+
+	var release githubRelease
+	if err := fetchJSON(ctx, d.client, url, &release); err != nil {
+		return Release{}, err
+	}
+
+	for _, asset := range release.Assets {
+		name := asset.Name
+		if !strings.HasSuffix(name, ".tar.xz") || !strings.Contains(name, "linux") {
+			continue
+		}
+		if strings.Contains(name, "arm64") || strings.Contains(name, "aarch64") {
+			continue
+		}
+		// Nama direktori teratas tarball tidak selalu sama dengan nama
+		// asset, jadi Executable dibiarkan kosong dan hasil ekstrak dipindai
+		return Release{
+			Version:     release.TagName,
+			DownloadURL: asset.BrowserDownloadURL,
+			Format:      FormatTarXz,
+		}, nil
+	}
+
+	return Release{}, fmt.Errorf("no linux x86_64 tarball in release %s", release.TagName)
+}
+
+// fetchText mengunduh file teks kecil dan mengembalikan isinya tanpa spasi
+func fetchText(ctx context.Context, client *http.Client, url string) (string, error) {
+	resp, cancel, err := fetchMetadata(ctx, client, url)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
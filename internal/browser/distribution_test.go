@@ -0,0 +1,246 @@
+package browser_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+func skipUnlessLinuxAmd64(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		t.Skip("fixtures only provide linux x86_64 builds")
+	}
+}
+
+func zipWithFile(t *testing.T, name string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("#!/bin/sh\n"))
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestHeadlessShellDistribution(t *testing.T) {
+	skipUnlessLinuxAmd64(t)
+	archive := zipWithFile(t, "chrome-headless-shell-linux64/chrome-headless-shell")
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/last-known-good-versions-with-downloads.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"channels": map[string]interface{}{
+			"Stable": map[string]interface{}{
+				"version": "131.0.6778.204",
+				"downloads": map[string]interface{}{
+					"chrome-headless-shell": []map[string]string{
+						{"platform": "linux64", "url": srv.URL + "/chrome-headless-shell-linux64.zip"},
+					},
+				},
+			},
+		}})
+	})
+	mux.HandleFunc("/chrome-headless-shell-linux64.zip", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+
+	mgr, rootDir := newTestManager(t, browser.Config{
+		InstallDirName:  "chrome-test",
+		Distribution:    browser.DistHeadlessShell,
+		Channel:         browser.ChannelStable,
+		VersionsBaseURL: srv.URL,
+	})
+	if err := mgr.Setup(); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	want := filepath.Join(rootDir, browser.DistHeadlessShell, "131.0.6778.204", "chrome-headless-shell-linux64", "chrome-headless-shell")
+	if mgr.GetExecutablePath() != want {
+		t.Errorf("expected executable %s, got %s", want, mgr.GetExecutablePath())
+	}
+}
+
+func TestChromiumSnapshotDistribution(t *testing.T) {
+	skipUnlessLinuxAmd64(t)
+	archive := zipWithFile(t, "chrome-linux/chrome")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/Linux_x64/LAST_CHANGE", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("1234567\n"))
+	})
+	mux.HandleFunc("/Linux_x64/1234567/chrome-linux.zip", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mgr, rootDir := newTestManager(t, browser.Config{
+		InstallDirName:  "chrome-test",
+		Distribution:    browser.DistChromiumSnapshot,
+		VersionsBaseURL: srv.URL,
+	})
+	if err := mgr.Setup(); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	if mgr.GetExecutablePath() != filepath.Join(rootDir, browser.DistChromiumSnapshot, "1234567", "chrome-linux", "chrome") {
+		t.Errorf("unexpected executable %s", mgr.GetExecutablePath())
+	}
+}
+
+func TestUngoogledChromiumDistribution(t *testing.T) {
+	skipUnlessLinuxAmd64(t)
+	archive := buildTarXz(t, []*tar.Header{
+		{Name: "ungoogled-chromium_131.0.6778.204-1_linux/chrome", Typeflag: tar.TypeReg},
+	})
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"tag_name": "131.0.6778.204-1",
+			"assets": []map[string]string{
+				{"name": "ungoogled-chromium_131.0.6778.204-1_linux.AppImage", "browser_download_url": srv.URL + "/appimage"},
+				{"name": "ungoogled-chromium_131.0.6778.204-1_linux.tar.xz", "browser_download_url": srv.URL + "/download/linux.tar.xz"},
+			},
+		})
+	})
+	mux.HandleFunc("/download/linux.tar.xz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+
+	mgr, rootDir := newTestManager(t, browser.Config{
+		InstallDirName:  "chrome-test",
+		Distribution:    browser.DistUngoogled,
+		VersionsBaseURL: srv.URL,
+	})
+	if err := mgr.Setup(); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	want := filepath.Join(rootDir, browser.DistUngoogled, "131.0.6778.204-1", "ungoogled-chromium_131.0.6778.204-1_linux", "chrome")
+	if mgr.GetExecutablePath() != want {
+		t.Errorf("expected executable %s, got %s", want, mgr.GetExecutablePath())
+	}
+}
+
+// Direktori teratas tarball yang berbeda dari nama asset tetap ditemukan
+func TestUngoogledChromiumDirectoryDiffersFromAsset(t *testing.T) {
+	skipUnlessLinuxAmd64(t)
+	archive := buildTarXz(t, []*tar.Header{
+		{Name: "ungoogled-chromium-131.0.6778.204-1-x86_64_linux/chrome", Typeflag: tar.TypeReg},
+	})
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/releases/tags/131.0.6778.204-1", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"tag_name": "131.0.6778.204-1",
+			"assets": []map[string]string{
+				{"name": "ungoogled-chromium_131.0.6778.204-1_linux.tar.xz", "browser_download_url": srv.URL + "/download/linux.tar.xz"},
+			},
+		})
+	})
+	mux.HandleFunc("/download/linux.tar.xz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+
+	mgr, rootDir := newTestManager(t, browser.Config{
+		InstallDirName:  "chrome-test",
+		Distribution:    browser.DistUngoogled,
+		Version:         "131.0.6778.204-1",
+		VersionsBaseURL: srv.URL,
+	})
+	if err := mgr.Setup(); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	want := filepath.Join(rootDir, browser.DistUngoogled, "131.0.6778.204-1", "ungoogled-chromium-131.0.6778.204-1-x86_64_linux", "chrome")
+	if mgr.GetExecutablePath() != want {
+		t.Errorf("expected executable %s, got %s", want, mgr.GetExecutablePath())
+	}
+}
+
+func TestUnknownDistribution(t *testing.T) {
+	if _, err := browser.DistributionByName("firefox", ""); err == nil {
+		t.Error("expected error for unknown distribution")
+	}
+	for _, name := range browser.DistributionNames() {
+		dist, err := browser.DistributionByName(name, "")
+		if err != nil || dist.Name() != name {
+			t.Errorf("distribution %s: got %v, %v", name, dist, err)
+		}
+	}
+}
+
+// Test setiap distribusi bisa di-resolve dari DefaultConfig hanya dengan
+// mengganti Distribution
+func TestDistributionsResolveFromDefaultConfig(t *testing.T) {
+	skipUnlessLinuxAmd64(t)
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/last-known-good-versions-with-downloads.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"channels": map[string]interface{}{
+			"Stable": map[string]interface{}{
+				"version": "131.0.6778.204",
+				"downloads": map[string]interface{}{
+					"chrome":                []map[string]string{{"platform": "linux64", "url": srv.URL + "/chrome-linux64.zip"}},
+					"chrome-headless-shell": []map[string]string{{"platform": "linux64", "url": srv.URL + "/chrome-headless-shell-linux64.zip"}},
+				},
+			},
+		}})
+	})
+	mux.HandleFunc("/Linux_x64/LAST_CHANGE", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("1234567\n"))
+	})
+	mux.HandleFunc("/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"tag_name": "131.0.6778.204-1",
+			"assets": []map[string]string{
+				{"name": "ungoogled-chromium_131.0.6778.204-1_linux.tar.xz", "browser_download_url": srv.URL + "/linux.tar.xz"},
+			},
+		})
+	})
+
+	want := map[string]string{
+		browser.DistChromeForTesting: "131.0.6778.204",
+		browser.DistHeadlessShell:    "131.0.6778.204",
+		browser.DistChromiumSnapshot: "1234567",
+		browser.DistUngoogled:        "131.0.6778.204-1",
+	}
+	for _, name := range browser.DistributionNames() {
+		t.Run(name, func(t *testing.T) {
+			config := browser.DefaultConfig()
+			config.Distribution = name
+			dist, err := browser.DistributionByName(config.Distribution, srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			release, err := dist.Resolve(context.Background(), config)
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if release.Version != want[name] {
+				t.Errorf("version = %q, want %q", release.Version, want[name])
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Downloader mengunduh file ke disk dengan dukungan resume (HTTP Range)
+// dan retry dengan exponential backoff. Dipakai untuk arsip Chrome dan .deb.
+type Downloader struct {
+	// HTTP client yang dipakai (default http.DefaultClient)
+	Client *http.Client
+
+	// Jumlah maksimal percobaan per download
+	MaxAttempts int
+
+	// Backoff awal dan maksimal antar percobaan
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Batas waktu total satu download (termasuk semua retry), 0 = tanpa batas
+	Timeout time.Duration
+
+	logger func(key, value string)
+}
+
+// NewDownloader membuat Downloader dengan nilai default
+func NewDownloader(logger func(key, value string)) *Downloader {
+	if logger == nil {
+		logger = func(key, value string) {}
+	}
+
+	return &Downloader{
+		Client:         http.DefaultClient,
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		Timeout:        15 * time.Minute,
+		logger:         logger,
+	}
+}
+
+// httpClient mengembalikan Client, atau http.DefaultClient jika nil
+func (d *Downloader) httpClient() *http.Client {
+	if d.Client != nil {
+		return d.Client
+	}
+	return http.DefaultClient
+}
+
+// retryableError menandai error yang boleh dicoba ulang
+type retryableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// Download mengunduh url ke dest. Data ditulis ke dest+".part" dan di-rename
+// setelah lengkap, sehingga download yang terputus bisa dilanjutkan. Validator
+// response (ETag atau Last-Modified) disimpan di dest+".part.validator" dan
+// dikirim sebagai If-Range saat resume agar partial file tidak disambung ke
+// file yang sudah berubah di server.
+// Jika h tidak nil, seluruh isi file (termasuk bagian yang di-resume) di-hash.
+func (d *Downloader) Download(url, dest string, h hash.Hash) error {
+	return d.DownloadContext(context.Background(), url, dest, h)
+}
+
+// DownloadContext sama dengan Download, tetapi dibatalkan saat ctx selesai
+func (d *Downloader) DownloadContext(ctx context.Context, url, dest string, h hash.Hash) error {
+	if d.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+
+	partFile := dest + ".part"
+	backoff := d.InitialBackoff
+	attempts := d.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		d.logger("download_attempt", fmt.Sprintf("%d/%d %s", attempt, attempts, url))
+
+		err := d.fetch(ctx, url, partFile, h)
+		if err == nil {
+			if err := os.Rename(partFile, dest); err != nil {
+				return fmt.Errorf("failed to finalize download: %w", err)
+			}
+			os.Remove(validatorPath(partFile))
+			d.logger("download_complete", dest)
+			return nil
+		}
+		lastErr = err
+
+		var retryErr *retryableError
+		if !errors.As(err, &retryErr) {
+			return err
+		}
+		if attempt == attempts {
+			break
+		}
+
+		wait := backoff
+		if retryErr.retryAfter > 0 {
+			wait = retryErr.retryAfter
+			// Retry-After dari server tetap dibatasi MaxBackoff
+			if d.MaxBackoff > 0 && wait > d.MaxBackoff {
+				wait = d.MaxBackoff
+			}
+		}
+		d.logger("download_retry", fmt.Sprintf("in %s: %v", wait, err))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("download cancelled: %w (last error: %v)", ctx.Err(), err)
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+		if d.MaxBackoff > 0 && backoff > d.MaxBackoff {
+			backoff = d.MaxBackoff
+		}
+	}
+
+	return fmt.Errorf("download failed after %d attempts: %w", attempts, lastErr)
+}
+
+// fetch melakukan satu percobaan download, melanjutkan dari partFile jika ada
+func (d *Downloader) fetch(ctx context.Context, url, partFile string, h hash.Hash) error {
+	var offset int64
+	if info, err := os.Stat(partFile); err == nil {
+		offset = info.Size()
+	}
+	validator := readValidator(partFile)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	switch {
+	case offset > 0 && validator != "":
+		// Server mengirim file utuh (200) jika validator tidak cocok lagi
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", validator)
+		d.logger("download_resume", fmt.Sprintf("offset=%d", offset))
+	case offset > 0:
+		// Tanpa validator tidak bisa dipastikan file di server masih sama
+		d.logger("download_restart", fmt.Sprintf("no validator for partial file at offset %d", offset))
+		offset = 0
+	}
+
+	resp, err := d.httpClient().Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return &retryableError{err: err}
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0 && contentRangeStart(resp) == offset:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusPartialContent:
+		// Range yang dikembalikan tidak sesuai dengan partial file
+		os.Remove(partFile)
+		os.Remove(validatorPath(partFile))
+		return &retryableError{err: fmt.Errorf("unexpected content range %q", resp.Header.Get("Content-Range"))}
+	case resp.StatusCode == http.StatusOK:
+		// Server tidak mendukung Range, file berubah (If-Range tidak cocok)
+		// atau tidak ada partial: mulai dari awal
+		offset = 0
+		flags |= os.O_TRUNC
+		if err := saveValidator(partFile, resp); err != nil {
+			return err
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// Partial file tidak valid lagi (misal file di server berubah)
+		os.Remove(partFile)
+		os.Remove(validatorPath(partFile))
+		return &retryableError{err: fmt.Errorf("range not satisfiable at offset %d", offset)}
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return &retryableError{
+			err:        fmt.Errorf("status %d", resp.StatusCode),
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	default:
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	if h != nil {
+		h.Reset()
+		if offset > 0 {
+			if err := hashFile(partFile, h); err != nil {
+				return err
+			}
+		}
+	}
+
+	f, err := os.OpenFile(partFile, flags, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open partial file: %w", err)
+	}
+
+	var w io.Writer = f
+	if h != nil {
+		w = io.MultiWriter(f, h)
+	}
+
+	n, copyErr := io.Copy(w, resp.Body)
+	closeErr := f.Close()
+
+	if copyErr != nil {
+		if ctx.Err() != nil {
+			return copyErr
+		}
+		d.logger("download_interrupted", fmt.Sprintf("after %d bytes: %v", offset+n, copyErr))
+		return &retryableError{err: copyErr}
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to write partial file: %w", closeErr)
+	}
+
+	// Pastikan body tidak terpotong tanpa error dari transport
+	if resp.ContentLength >= 0 && n != resp.ContentLength {
+		return &retryableError{err: fmt.Errorf("short body: got %d of %d bytes", n, resp.ContentLength)}
+	}
+
+	return nil
+}
+
+// validatorPath mengembalikan path file validator untuk partFile
+func validatorPath(partFile string) string {
+	return partFile + ".validator"
+}
+
+// readValidator membaca validator partial file; kosong jika tidak ada
+func readValidator(partFile string) string {
+	data, err := os.ReadFile(validatorPath(partFile))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// saveValidator menyimpan validator untuk If-Range dari response lengkap.
+// If-Range hanya boleh memakai ETag kuat; ETag lemah diganti Last-Modified.
+// Tanpa validator, file lama dihapus sehingga partial file tidak di-resume.
+func saveValidator(partFile string, resp *http.Response) error {
+	validator := resp.Header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = resp.Header.Get("Last-Modified")
+	}
+	if validator == "" {
+		os.Remove(validatorPath(partFile))
+		return nil
+	}
+	if err := os.WriteFile(validatorPath(partFile), []byte(validator), 0o644); err != nil {
+		return fmt.Errorf("failed to save download validator: %w", err)
+	}
+	return nil
+}
+
+// contentRangeStart mengambil posisi awal dari header Content-Range
+func contentRangeStart(resp *http.Response) int64 {
+	cr := strings.TrimPrefix(resp.Header.Get("Content-Range"), "bytes ")
+	idx := strings.IndexByte(cr, '-')
+	if idx <= 0 {
+		return -1
+	}
+	start, err := strconv.ParseInt(cr[:idx], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return start
+}
+
+// parseRetryAfter mem-parsing header Retry-After (detik atau HTTP-date)
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(t); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// hashFile menulis isi file ke hash
+func hashFile(path string, h hash.Hash) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(h, f)
+	return err
+}
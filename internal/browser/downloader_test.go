@@ -0,0 +1,221 @@
+package browser_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+func newTestDownloader() *browser.Downloader {
+	d := browser.NewDownloader(nil)
+	d.InitialBackoff = time.Millisecond
+	d.MaxBackoff = 10 * time.Millisecond
+	d.Timeout = 10 * time.Second
+	return d
+}
+
+// Server memutus koneksi di tengah jalan pada request pertama,
+// download harus dilanjutkan dengan Range dari posisi terakhir.
+func TestDownloaderResumesAfterCutConnection(t *testing.T) {
+	payload := bytes.Repeat([]byte("chrome-archive-"), 4096)
+	var requests int32
+	var rangeHeader, ifRange atomic.Value
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Content-Length", "61440")
+			w.WriteHeader(http.StatusOK)
+			w.Write(payload[:len(payload)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		rangeHeader.Store(r.Header.Get("Range"))
+		ifRange.Store(r.Header.Get("If-Range"))
+		http.ServeContent(w, r, "chrome.zip", time.Time{}, bytes.NewReader(payload))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "chrome.zip")
+	h := sha256.New()
+	if err := newTestDownloader().Download(srv.URL, dest, h); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("downloaded content mismatch: got %d bytes, want %d", len(got), len(payload))
+	}
+	if rh, _ := rangeHeader.Load().(string); !strings.HasPrefix(rh, "bytes=") || rh == "bytes=0-" {
+		t.Errorf("expected resume Range header, got %q", rh)
+	}
+	if ir, _ := ifRange.Load().(string); ir != `"v1"` {
+		t.Errorf("expected If-Range with the ETag of the first response, got %q", ir)
+	}
+
+	want := sha256.Sum256(payload)
+	if !bytes.Equal(h.Sum(nil), want[:]) {
+		t.Error("hash should cover resumed and new bytes")
+	}
+	if _, err := os.Stat(dest + ".part"); !os.IsNotExist(err) {
+		t.Error("partial file should be renamed after completion")
+	}
+	if _, err := os.Stat(dest + ".part.validator"); !os.IsNotExist(err) {
+		t.Error("validator file should be removed after completion")
+	}
+}
+
+// File di server berubah di antara dua percobaan: If-Range tidak cocok,
+// server mengirim file baru utuh dan partial file lama tidak boleh disambung
+func TestDownloaderRestartsWhenFileChanged(t *testing.T) {
+	oldPayload := bytes.Repeat([]byte("old-archive-"), 4096)
+	newPayload := bytes.Repeat([]byte("new-archive-"), 4096)
+	var requests int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("ETag", `"old"`)
+			w.Header().Set("Content-Length", strconv.Itoa(len(oldPayload)))
+			w.WriteHeader(http.StatusOK)
+			w.Write(oldPayload[:len(oldPayload)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		w.Header().Set("ETag", `"new"`)
+		http.ServeContent(w, r, "chrome.zip", time.Time{}, bytes.NewReader(newPayload))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "chrome.zip")
+	h := sha256.New()
+	if err := newTestDownloader().Download(srv.URL, dest, h); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, newPayload) {
+		t.Fatalf("expected the new file only, got %d bytes", len(got))
+	}
+	want := sha256.Sum256(newPayload)
+	if !bytes.Equal(h.Sum(nil), want[:]) {
+		t.Error("hash should cover only the new file")
+	}
+}
+
+// Partial file tanpa validator tidak bisa dicek, sehingga diunduh ulang
+func TestDownloaderDoesNotResumeWithoutValidator(t *testing.T) {
+	var rangeHeader atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rangeHeader.Store(r.Header.Get("Range"))
+		w.Write([]byte("complete"))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "pkg.deb")
+	if err := os.WriteFile(dest+".part", []byte("stale"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := newTestDownloader().Download(srv.URL, dest, nil); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	if rh, _ := rangeHeader.Load().(string); rh != "" {
+		t.Errorf("expected no Range header, got %q", rh)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != "complete" {
+		t.Errorf("unexpected content %q", got)
+	}
+}
+
+// Retry-After yang besar tetap dibatasi MaxBackoff
+func TestDownloaderCapsRetryAfter(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	d := newTestDownloader()
+	d.Timeout = 0
+	dest := filepath.Join(t.TempDir(), "pkg.deb")
+	start := time.Now()
+	if err := d.Download(srv.URL, dest, nil); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Retry-After should be capped by MaxBackoff, waited %s", elapsed)
+	}
+}
+
+func TestDownloaderRetriesServerErrors(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "pkg.deb")
+	if err := newTestDownloader().Download(srv.URL, dest, nil); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+}
+
+func TestDownloaderDoesNotRetryClientErrors(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "pkg.deb")
+	if err := newTestDownloader().Download(srv.URL, dest, nil); err == nil {
+		t.Fatal("expected error for 404")
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("404 should not be retried, got %d requests", got)
+	}
+}
+
+func TestDownloaderTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	d := newTestDownloader()
+	d.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	err := d.Download(srv.URL, filepath.Join(t.TempDir(), "slow"), nil)
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Errorf("timeout not honored, took %s", time.Since(start))
+	}
+}
@@ -0,0 +1,287 @@
+package browser
+
+import (
+	"bufio"
+	"debug/elf"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ldSoConfPath adalah konfigurasi direktori library dynamic linker
+var ldSoConfPath = "/etc/ld.so.conf"
+
+// LibraryReport adalah hasil pemeriksaan DT_NEEDED executable Chrome dan
+// library bawaannya, mirip output ldd tetapi tanpa menjalankan binary
+type LibraryReport struct {
+	// Binaries berisi file ELF yang diperiksa: executable dan .so bawaan
+	Binaries []string
+	// SearchDirs berisi direktori pencarian sesuai urutan
+	SearchDirs []string
+	// Resolved memetakan soname ke file yang ditemukan
+	Resolved map[string]string
+	// Unresolved berisi soname yang tidak ditemukan, diurutkan berdasarkan nama
+	Unresolved []UnresolvedLibrary
+}
+
+// UnresolvedLibrary adalah satu soname yang tidak ditemukan di direktori pencarian
+type UnresolvedLibrary struct {
+	Soname string
+	// NeededBy berisi file ELF yang membutuhkan soname ini
+	NeededBy []string
+	// Provider adalah Dependency yang menyediakan soname ini, nil jika tidak ada di daftar
+	Provider *Dependency
+}
+
+// OK bernilai true jika semua DT_NEEDED ditemukan
+func (r *LibraryReport) OK() bool {
+	return len(r.Unresolved) == 0
+}
+
+// DiagnoseLibraries membaca section dynamic executable Chrome dan semua .so
+// di direktorinya, lalu mencari setiap DT_NEEDED (termasuk kebutuhan library
+// yang ditemukan) di RPATH/RUNPATH, pohon libs/, LD_LIBRARY_PATH,
+// /etc/ld.so.conf dan direktori library sistem. Soname yang tidak ditemukan
+// dipasangkan dengan Dependency di config yang menyediakannya.
+func (cm *ChromiumManager) DiagnoseLibraries(executable string) (*LibraryReport, error) {
+	exeDir := filepath.Dir(executable)
+	binaries := []string{executable}
+	entries, err := os.ReadDir(exeDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chrome directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && isSharedObjectName(entry.Name()) {
+			binaries = append(binaries, filepath.Join(exeDir, entry.Name()))
+		}
+	}
+
+	dirs := []string{exeDir, filepath.Join(exeDir, "lib")}
+	dirs = append(dirs, cm.dependencyLibDirs()...)
+	dirs = append(dirs, filepath.SplitList(os.Getenv("LD_LIBRARY_PATH"))...)
+	dirs = append(dirs, readLdSoConf(ldSoConfPath, map[string]bool{})...)
+	dirs = append(dirs, systemLibraryDirs...)
+
+	report, err := ResolveSharedLibraries(binaries, dirs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range report.Unresolved {
+		report.Unresolved[i].Provider = dependencyForSoname(cm.config.Dependencies, report.Unresolved[i].Soname)
+	}
+	return report, nil
+}
+
+// ResolveSharedLibraries mencari DT_NEEDED setiap binary secara transitif di
+// searchDirs. Library hanya dianggap cocok jika kelas ELF dan arsitekturnya
+// sama dengan file yang membutuhkannya, seperti dynamic linker.
+func ResolveSharedLibraries(binaries, searchDirs []string) (*LibraryReport, error) {
+	report := &LibraryReport{
+		Binaries:   binaries,
+		SearchDirs: uniqueDirs(searchDirs),
+		Resolved:   map[string]string{},
+	}
+	unresolved := map[string]*UnresolvedLibrary{}
+	visited := map[string]bool{}
+
+	queue := append([]string(nil), binaries...)
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		if visited[path] {
+			continue
+		}
+		visited[path] = true
+
+		info, err := readELFDeps(path)
+		if err != nil {
+			// Executable harus ELF; .so bawaan yang rusak dilewati
+			if path == binaries[0] {
+				return nil, err
+			}
+			continue
+		}
+
+		dirs := append(info.searchPath(filepath.Dir(path)), report.SearchDirs...)
+		for _, soname := range info.needed {
+			if _, ok := report.Resolved[soname]; ok {
+				continue
+			}
+			if found := findLibrary(soname, dirs, info); found != "" {
+				report.Resolved[soname] = found
+				queue = append(queue, found)
+				continue
+			}
+			missing := unresolved[soname]
+			if missing == nil {
+				missing = &UnresolvedLibrary{Soname: soname}
+				unresolved[soname] = missing
+			}
+			missing.NeededBy = append(missing.NeededBy, path)
+		}
+	}
+
+	for _, missing := range unresolved {
+		report.Unresolved = append(report.Unresolved, *missing)
+	}
+	sort.Slice(report.Unresolved, func(i, j int) bool {
+		return report.Unresolved[i].Soname < report.Unresolved[j].Soname
+	})
+	return report, nil
+}
+
+// elfDeps berisi informasi dynamic section satu file ELF
+type elfDeps struct {
+	class   elf.Class
+	machine elf.Machine
+	needed  []string
+	rpath   []string
+	runpath []string
+}
+
+// readELFDeps membaca DT_NEEDED, DT_RPATH dan DT_RUNPATH dari file ELF
+func readELFDeps(path string) (*elfDeps, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read elf %s: %w", path, err)
+	}
+	defer f.Close()
+
+	info := &elfDeps{class: f.Class, machine: f.Machine}
+	// File statis tidak punya section dynamic, berarti tidak ada DT_NEEDED
+	if f.SectionByType(elf.SHT_DYNAMIC) == nil {
+		return info, nil
+	}
+	if info.needed, err = f.DynString(elf.DT_NEEDED); err != nil {
+		return nil, fmt.Errorf("failed to read DT_NEEDED of %s: %w", path, err)
+	}
+	rpath, _ := f.DynString(elf.DT_RPATH)
+	runpath, _ := f.DynString(elf.DT_RUNPATH)
+	for _, value := range rpath {
+		info.rpath = append(info.rpath, strings.Split(value, ":")...)
+	}
+	for _, value := range runpath {
+		info.runpath = append(info.runpath, strings.Split(value, ":")...)
+	}
+	return info, nil
+}
+
+// searchPath mengembalikan direktori dari RPATH/RUNPATH dengan $ORIGIN
+// diganti direktori file. RPATH diabaikan jika RUNPATH ada.
+func (d *elfDeps) searchPath(origin string) []string {
+	paths := d.rpath
+	if len(d.runpath) > 0 {
+		paths = d.runpath
+	}
+	var dirs []string
+	for _, dir := range paths {
+		dir = strings.ReplaceAll(dir, "${ORIGIN}", origin)
+		dir = strings.ReplaceAll(dir, "$ORIGIN", origin)
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// findLibrary mencari soname di dirs dan mengembalikan file pertama yang
+// kompatibel dengan file yang membutuhkannya
+func findLibrary(soname string, dirs []string, requester *elfDeps) string {
+	candidates := dirs
+	if strings.Contains(soname, "/") {
+		candidates = []string{""}
+	}
+	for _, dir := range candidates {
+		path := soname
+		if dir != "" {
+			path = filepath.Join(dir, soname)
+		}
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		f, err := elf.Open(path)
+		if err != nil {
+			continue
+		}
+		compatible := f.Class == requester.class && f.Machine == requester.machine
+		f.Close()
+		if compatible {
+			return path
+		}
+	}
+	return ""
+}
+
+// readLdSoConf membaca direktori dari ld.so.conf beserta file "include"-nya
+func readLdSoConf(path string, seen map[string]bool) []string {
+	if seen[path] {
+		return nil
+	}
+	seen[path] = true
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var dirs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if pattern, ok := strings.CutPrefix(line, "include"); ok && (pattern == "" || pattern[0] == ' ' || pattern[0] == '\t') {
+			pattern = strings.TrimSpace(pattern)
+			if !filepath.IsAbs(pattern) {
+				pattern = filepath.Join(filepath.Dir(path), pattern)
+			}
+			matches, _ := filepath.Glob(pattern)
+			sort.Strings(matches)
+			for _, match := range matches {
+				dirs = append(dirs, readLdSoConf(match, seen)...)
+			}
+			continue
+		}
+		dirs = append(dirs, line)
+	}
+	return dirs
+}
+
+// dependencyForSoname mencari Dependency yang LibraryName-nya cocok dengan
+// soname, contoh "libnss3.so" untuk "libnss3.so" atau "libatk-1.0.so" untuk "libatk-1.0.so.0"
+func dependencyForSoname(deps []Dependency, soname string) *Dependency {
+	for i := range deps {
+		name := deps[i].LibraryName
+		if soname == name || strings.HasPrefix(soname, name+".") {
+			return &deps[i]
+		}
+	}
+	return nil
+}
+
+// isSharedObjectName mengecek nama file seperti "libEGL.so" atau "libvulkan.so.1"
+func isSharedObjectName(name string) bool {
+	return strings.HasSuffix(name, ".so") || strings.Contains(name, ".so.")
+}
+
+// uniqueDirs membuang direktori kosong dan duplikat dengan urutan tetap
+func uniqueDirs(dirs []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, dir := range dirs {
+		if dir == "" || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		out = append(out, dir)
+	}
+	return out
+}
@@ -0,0 +1,169 @@
+package browser_test
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// writeELF menulis file ELF64 minimal yang hanya berisi section .dynamic
+// dengan DT_NEEDED dan DT_RUNPATH (jika runpath tidak kosong)
+func writeELF(t *testing.T, path string, machine elf.Machine, runpath string, needed ...string) {
+	t.Helper()
+
+	dynstr := []byte{0}
+	addString := func(s string) uint64 {
+		off := uint64(len(dynstr))
+		dynstr = append(dynstr, s...)
+		dynstr = append(dynstr, 0)
+		return off
+	}
+	var dyn []elf.Dyn64
+	for _, name := range needed {
+		dyn = append(dyn, elf.Dyn64{Tag: int64(elf.DT_NEEDED), Val: addString(name)})
+	}
+	if runpath != "" {
+		dyn = append(dyn, elf.Dyn64{Tag: int64(elf.DT_RUNPATH), Val: addString(runpath)})
+	}
+	dyn = append(dyn, elf.Dyn64{Tag: int64(elf.DT_NULL)})
+	shstrtab := []byte("\x00.dynstr\x00.dynamic\x00.shstrtab\x00")
+
+	const headerSize = 64
+	dynstrOff := uint64(headerSize)
+	dynamicOff := (dynstrOff + uint64(len(dynstr)) + 7) &^ 7
+	dynamicSize := uint64(len(dyn) * 16)
+	shstrtabOff := dynamicOff + dynamicSize
+	shOff := (shstrtabOff + uint64(len(shstrtab)) + 7) &^ 7
+
+	var buf bytes.Buffer
+	header := elf.Header64{
+		Type:      uint16(elf.ET_DYN),
+		Machine:   uint16(machine),
+		Version:   uint32(elf.EV_CURRENT),
+		Shoff:     shOff,
+		Ehsize:    headerSize,
+		Phentsize: 56,
+		Shentsize: 64,
+		Shnum:     4,
+		Shstrndx:  3,
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	write := func(v any) {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pad := func(off uint64) {
+		buf.Write(make([]byte, int(off)-buf.Len()))
+	}
+
+	write(header)
+	buf.Write(dynstr)
+	pad(dynamicOff)
+	write(dyn)
+	buf.Write(shstrtab)
+	pad(shOff)
+	write([]elf.Section64{
+		{},
+		{Name: 1, Type: uint32(elf.SHT_STRTAB), Off: dynstrOff, Size: uint64(len(dynstr)), Addralign: 1},
+		{Name: 9, Type: uint32(elf.SHT_DYNAMIC), Off: dynamicOff, Size: dynamicSize, Link: 1, Addralign: 8, Entsize: 16},
+		{Name: 18, Type: uint32(elf.SHT_STRTAB), Off: shstrtabOff, Size: uint64(len(shstrtab)), Addralign: 1},
+	})
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Test DT_NEEDED dicari secara transitif, lewat RUNPATH $ORIGIN, dan library
+// dengan arsitektur berbeda dilewati
+func TestResolveSharedLibraries(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "chrome", "chrome")
+	system := filepath.Join(dir, "system")
+	foreign := filepath.Join(dir, "foreign")
+
+	writeELF(t, exe, elf.EM_X86_64, "$ORIGIN/bundled", "libbundled.so", "libnss3.so", "libarch.so.1", "libgone.so.2")
+	writeELF(t, filepath.Join(dir, "chrome", "bundled", "libbundled.so"), elf.EM_X86_64, "")
+	writeELF(t, filepath.Join(system, "libnss3.so"), elf.EM_X86_64, "", "libnspr4.so")
+	writeELF(t, filepath.Join(foreign, "libarch.so.1"), elf.EM_AARCH64, "")
+	writeELF(t, filepath.Join(system, "libarch.so.1"), elf.EM_X86_64, "")
+
+	report, err := browser.ResolveSharedLibraries([]string{exe}, []string{foreign, system})
+	if err != nil {
+		t.Fatalf("ResolveSharedLibraries: %v", err)
+	}
+
+	wantResolved := map[string]string{
+		"libbundled.so": filepath.Join(dir, "chrome", "bundled", "libbundled.so"),
+		"libnss3.so":    filepath.Join(system, "libnss3.so"),
+		"libarch.so.1":  filepath.Join(system, "libarch.so.1"),
+	}
+	for soname, want := range wantResolved {
+		if got := report.Resolved[soname]; got != want {
+			t.Errorf("Resolved[%s] = %q, want %q", soname, got, want)
+		}
+	}
+
+	if report.OK() || len(report.Unresolved) != 2 {
+		t.Fatalf("Unresolved = %+v, want libgone.so.2 and libnspr4.so", report.Unresolved)
+	}
+	if got := report.Unresolved[0]; got.Soname != "libgone.so.2" || got.NeededBy[0] != exe {
+		t.Errorf("Unresolved[0] = %+v", got)
+	}
+	if got := report.Unresolved[1]; got.Soname != "libnspr4.so" || got.NeededBy[0] != filepath.Join(system, "libnss3.so") {
+		t.Errorf("Unresolved[1] = %+v, want libnspr4.so needed by libnss3.so", got)
+	}
+}
+
+// Test DiagnoseLibraries memeriksa .so bawaan, memakai pohon libs/ dan
+// memetakan soname yang hilang ke Dependency
+func TestDiagnoseLibrariesMapsProviders(t *testing.T) {
+	root := t.TempDir()
+	config := browser.DefaultConfig()
+	config.RootDir = root
+	config.Dependencies = []browser.Dependency{
+		{Name: "libnss3", LibraryName: "libnss3.so"},
+		{Name: "libfake1.0-0", LibraryName: "libfake-1.0.so"},
+	}
+	cm := browser.NewChromiumManagerWithConfig(config, nil)
+
+	exe := filepath.Join(root, "131.0.0.0", "chrome-linux64", "chrome")
+	writeELF(t, exe, elf.EM_X86_64, "", "libnss3.so", "libtest-unknown.so.7")
+	writeELF(t, filepath.Join(filepath.Dir(exe), "libEGL.so"), elf.EM_X86_64, "", "libfake-1.0.so.0")
+	writeELF(t, filepath.Join(root, "libs", "usr", "lib", "x86_64-linux-gnu", "libnss3.so"), elf.EM_X86_64, "")
+
+	report, err := cm.DiagnoseLibraries(exe)
+	if err != nil {
+		t.Fatalf("DiagnoseLibraries: %v", err)
+	}
+	if len(report.Binaries) != 2 {
+		t.Errorf("Binaries = %v, want chrome and libEGL.so", report.Binaries)
+	}
+	if _, ok := report.Resolved["libnss3.so"]; !ok {
+		t.Error("libnss3.so should resolve from libs tree")
+	}
+
+	providers := map[string]string{}
+	for _, missing := range report.Unresolved {
+		name := ""
+		if missing.Provider != nil {
+			name = missing.Provider.Name
+		}
+		providers[missing.Soname] = name
+	}
+	if len(providers) != 2 || providers["libfake-1.0.so.0"] != "libfake1.0-0" || providers["libtest-unknown.so.7"] != "" {
+		t.Errorf("unresolved providers = %v", providers)
+	}
+}
@@ -0,0 +1,497 @@
+package browser
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// UnsafeEntryError dikembalikan jika arsip berisi entry yang bisa menulis
+// di luar direktori tujuan (zip-slip, path absolut, symlink keluar root)
+// atau tipe file yang tidak diizinkan (hardlink, device, fifo).
+type UnsafeEntryError struct {
+	Name   string
+	Reason string
+}
+
+func (e *UnsafeEntryError) Error() string {
+	return fmt.Sprintf("unsafe archive entry %q: %s", e.Name, e.Reason)
+}
+
+// extractResult mencatat jumlah entry arsip (file dan symlink, nama unik)
+// yang diharapkan dan yang benar-benar dibuat di disk
+type extractResult struct {
+	expected int
+	written  int
+	// entries bernilai true jika entry dengan nama tersebut sudah ditulis
+	entries map[string]bool
+}
+
+// expect mencatat entry non-direktori yang ada di arsip
+func (r *extractResult) expect(name string) {
+	if r.entries == nil {
+		r.entries = make(map[string]bool)
+	}
+	key := path.Clean(filepath.ToSlash(name))
+	if _, ok := r.entries[key]; !ok {
+		r.entries[key] = false
+		r.expected++
+	}
+}
+
+// wrote mencatat entry yang berhasil dibuat di disk
+func (r *extractResult) wrote(name string) {
+	key := path.Clean(filepath.ToSlash(name))
+	if done, ok := r.entries[key]; ok && !done {
+		r.entries[key] = true
+		r.written++
+	}
+}
+
+// safeJoin menggabungkan root dan nama entry arsip, menolak path absolut
+// dan komponen ".." yang keluar dari root
+func safeJoin(root, name string) (string, error) {
+	if name == "" {
+		return "", &UnsafeEntryError{Name: name, Reason: "empty name"}
+	}
+	if strings.ContainsRune(name, 0) {
+		return "", &UnsafeEntryError{Name: name, Reason: "NUL byte in name"}
+	}
+	slashed := filepath.ToSlash(name)
+	if strings.HasPrefix(slashed, "/") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", &UnsafeEntryError{Name: name, Reason: "absolute path"}
+	}
+
+	target := filepath.Join(root, name)
+	if !isWithin(root, target) {
+		return "", &UnsafeEntryError{Name: name, Reason: "path escapes destination directory"}
+	}
+	return target, nil
+}
+
+// checkSymlink memastikan symlink di target dengan isi linkname tetap menunjuk
+// ke dalam realRoot. Parent target dan komponen linkname di-resolve terhadap
+// symlink yang sudah diekstrak, sehingga rantai seperti "s -> ." lalu
+// "s/x -> ../y" ditolak.
+func checkSymlink(root, realRoot, target, name, linkname string) error {
+	if linkname == "" {
+		return &UnsafeEntryError{Name: name, Reason: "empty symlink target"}
+	}
+	if filepath.IsAbs(linkname) || strings.HasPrefix(filepath.ToSlash(linkname), "/") {
+		return &UnsafeEntryError{Name: name, Reason: fmt.Sprintf("absolute symlink target %q", linkname)}
+	}
+	escapes := &UnsafeEntryError{Name: name, Reason: fmt.Sprintf("symlink target %q escapes destination directory", linkname)}
+	if !isWithin(root, filepath.Join(filepath.Dir(target), linkname)) {
+		return escapes
+	}
+	parent, err := filepath.Rel(root, filepath.Dir(target))
+	if err != nil {
+		return escapes
+	}
+	base, ok := resolveWithin(realRoot, realRoot, parent)
+	if !ok {
+		return escapes
+	}
+	if _, ok := resolveWithin(realRoot, base, linkname); !ok {
+		return escapes
+	}
+	return nil
+}
+
+// resolveWithin me-resolve rel dari base per komponen seperti kernel:
+// symlink yang sudah ada diikuti dan ".." berlaku terhadap path aslinya.
+// false jika path keluar dari realRoot di langkah mana pun, symlink tidak
+// bisa di-resolve, atau ".." dipakai setelah komponen yang belum ada (path
+// aslinya baru diketahui setelah komponen itu dibuat).
+func resolveWithin(realRoot, base, rel string) (string, bool) {
+	cur := base
+	exists := true
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if !exists {
+				return "", false
+			}
+			cur = filepath.Dir(cur)
+		default:
+			cur = filepath.Join(cur, part)
+			if !exists {
+				break
+			}
+			info, err := os.Lstat(cur)
+			if err != nil {
+				exists = false
+				break
+			}
+			if info.Mode()&fs.ModeSymlink != 0 {
+				if cur, err = filepath.EvalSymlinks(cur); err != nil {
+					return "", false
+				}
+			}
+		}
+		if !isWithin(realRoot, cur) {
+			return "", false
+		}
+	}
+	return cur, true
+}
+
+// isWithin mengecek apakah path berada di dalam (atau sama dengan) root
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(root), filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// checkParent memastikan parent directory target, setelah symlink di-resolve,
+// masih berada di dalam realRoot (mencegah penulisan melalui symlink yang sudah ada)
+func checkParent(realRoot, target, name string) error {
+	existing := filepath.Dir(target)
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		next := filepath.Dir(existing)
+		if next == existing {
+			return nil
+		}
+		existing = next
+	}
+
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return &UnsafeEntryError{Name: name, Reason: fmt.Sprintf("cannot resolve parent directory: %v", err)}
+	}
+	if !isWithin(realRoot, resolved) {
+		return &UnsafeEntryError{Name: name, Reason: "parent directory resolves outside destination directory"}
+	}
+	return nil
+}
+
+// resolveRoot membuat root jika belum ada dan mengembalikan path aslinya
+func resolveRoot(root string) (string, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", root, err)
+	}
+	return filepath.EvalSymlinks(root)
+}
+
+// entryReader adalah arsip yang dibaca per entry seperti *tar.Reader; Read
+// membaca isi entry saat ini. Dipakai juga untuk payload cpio RPM.
+type entryReader interface {
+	Next() (*tar.Header, error)
+	Read(p []byte) (int, error)
+}
+
+// extractTarTo mengekstrak semua entry tar ke root secara aman.
+// onFile (opsional) dipanggil untuk setiap file reguler yang ditulis.
+func extractTarTo(ctx context.Context, tarReader entryReader, root string, onFile func(name string)) (extractResult, error) {
+	var result extractResult
+
+	realRoot, err := resolveRoot(root)
+	if err != nil {
+		return result, err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		// Header pax (global dari "git archive"/dpkg-deb, atau per entry)
+		// hanya berisi metadata, bukan file
+		if header.Typeflag == tar.TypeXGlobalHeader || header.Typeflag == tar.TypeXHeader {
+			continue
+		}
+
+		target, err := safeJoin(root, header.Name)
+		if err != nil {
+			return result, err
+		}
+		if err := checkParent(realRoot, target, header.Name); err != nil {
+			return result, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return result, fmt.Errorf("failed to create directory %s: %w", target, err)
+			}
+
+		case tar.TypeReg, tar.TypeRegA:
+			result.expect(header.Name)
+			if err := writeFile(target, &contextReader{ctx: ctx, r: tarReader}, os.FileMode(header.Mode).Perm()); err != nil {
+				return result, err
+			}
+			result.wrote(header.Name)
+			if onFile != nil {
+				onFile(header.Name)
+			}
+
+		case tar.TypeSymlink:
+			result.expect(header.Name)
+			if err := checkSymlink(root, realRoot, target, header.Name, header.Linkname); err != nil {
+				return result, err
+			}
+			created, err := writeSymlink(target, header.Linkname)
+			if err != nil {
+				return result, err
+			}
+			if created {
+				result.wrote(header.Name)
+			}
+
+		case tar.TypeLink:
+			return result, &UnsafeEntryError{Name: header.Name, Reason: "hardlinks are not allowed"}
+
+		case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+			return result, &UnsafeEntryError{Name: header.Name, Reason: "device and fifo entries are not allowed"}
+
+		default:
+			return result, &UnsafeEntryError{Name: header.Name, Reason: fmt.Sprintf("unsupported entry type %q", header.Typeflag)}
+		}
+	}
+
+	return result, nil
+}
+
+// extractZipTo mengekstrak semua entry ZIP ke root secara aman
+func extractZipTo(ctx context.Context, zipReader *zip.Reader, root string) (extractResult, error) {
+	var result extractResult
+
+	realRoot, err := resolveRoot(root)
+	if err != nil {
+		return result, err
+	}
+
+	// Jumlah yang diharapkan diambil dari central directory, terpisah dari
+	// entry yang berhasil ditulis
+	for _, file := range zipReader.File {
+		if !file.Mode().IsDir() {
+			result.expect(file.Name)
+		}
+	}
+
+	for _, file := range zipReader.File {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		target, err := safeJoin(root, file.Name)
+		if err != nil {
+			return result, err
+		}
+		if err := checkParent(realRoot, target, file.Name); err != nil {
+			return result, err
+		}
+
+		mode := file.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return result, fmt.Errorf("failed to create directory %s: %w", target, err)
+			}
+
+		case mode&os.ModeSymlink != 0:
+			linkname, err := readZipEntry(file, 4096)
+			if err != nil {
+				return result, err
+			}
+			if err := checkSymlink(root, realRoot, target, file.Name, linkname); err != nil {
+				return result, err
+			}
+			created, err := writeSymlink(target, linkname)
+			if err != nil {
+				return result, err
+			}
+			if created {
+				result.wrote(file.Name)
+			}
+
+		case mode.IsRegular():
+			rc, err := file.Open()
+			if err != nil {
+				return result, fmt.Errorf("failed to open zip entry %s: %w", file.Name, err)
+			}
+			err = writeFile(target, &contextReader{ctx: ctx, r: rc}, mode.Perm())
+			rc.Close()
+			if err != nil {
+				return result, err
+			}
+			result.wrote(file.Name)
+
+		default:
+			return result, &UnsafeEntryError{Name: file.Name, Reason: fmt.Sprintf("unsupported file mode %s", mode)}
+		}
+	}
+
+	return result, nil
+}
+
+// Magic bytes format kompresi yang dikenali decompressReader
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	bzip2Magic = []byte("BZh")
+	// lzmaMagic adalah properti lc=3 lp=0 pb=2 (default) di header .lzma
+	// lama, dipakai payload RPM "lzdio" dan data.tar.lzma
+	lzmaMagic = []byte{0x5d, 0x00, 0x00}
+)
+
+// decompressReader mendeteksi kompresi dari magic bytes (gzip, xz, zstd,
+// bzip2, lzma) dan mengembalikan reader hasil dekompresi beserta nama formatnya.
+// Data tanpa magic yang dikenali dikembalikan apa adanya ("none").
+// close harus dipanggil setelah selesai membaca.
+func decompressReader(r io.Reader) (out io.Reader, format string, close func(), err error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(6)
+	noop := func() {}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, "gzip", noop, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		return gr, "gzip", func() { gr.Close() }, nil
+	case bytes.HasPrefix(magic, xzMagic):
+		xr, err := xz.NewReader(br)
+		if err != nil {
+			return nil, "xz", noop, fmt.Errorf("failed to create xz reader: %w", err)
+		}
+		return xr, "xz", noop, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, "zstd", noop, fmt.Errorf("failed to create zstd reader: %w", err)
+		}
+		return zr, "zstd", zr.Close, nil
+	case bytes.HasPrefix(magic, bzip2Magic):
+		return bzip2.NewReader(br), "bzip2", noop, nil
+	case bytes.HasPrefix(magic, lzmaMagic):
+		lr, err := lzma.NewReader(br)
+		if err != nil {
+			return nil, "lzma", noop, fmt.Errorf("failed to create lzma reader: %w", err)
+		}
+		return lr, "lzma", noop, nil
+	}
+	return br, "none", noop, nil
+}
+
+// contextReader menghentikan pembacaan saat ctx selesai (untuk file besar)
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// writeFile menulis r ke target, membuat parent directory jika perlu
+func writeFile(target string, r io.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to create parent directory for %s: %w", target, err)
+	}
+
+	// Hapus symlink yang mungkin sudah ada agar tidak ditulis melalui link
+	if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		os.Remove(target)
+	}
+
+	outFile, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", target, err)
+	}
+
+	if _, err := io.Copy(outFile, r); err != nil {
+		outFile.Close()
+		return fmt.Errorf("failed to write file %s: %w", target, err)
+	}
+
+	if err := outFile.Close(); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", target, err)
+	}
+	return nil
+}
+
+// writeSymlink membuat symlink, mengganti symlink lama dengan nama yang sama.
+// created bernilai false jika sudah ada file atau direktori dengan nama itu.
+func writeSymlink(target, linkname string) (created bool, err error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return false, fmt.Errorf("failed to create parent directory for %s: %w", target, err)
+	}
+
+	if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		os.Remove(target)
+	}
+
+	if err := os.Symlink(linkname, target); err != nil {
+		if os.IsExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create symlink %s: %w", target, err)
+	}
+	return true, nil
+}
+
+// countEntries menghitung file dan symlink di bawah dir (direktori tidak dihitung)
+func countEntries(dir string) (int, error) {
+	count := 0
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// readZipEntry membaca isi entry ZIP kecil (misal target symlink)
+func readZipEntry(file *zip.File, limit int64) (string, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open zip entry %s: %w", file.Name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, limit))
+	if err != nil {
+		return "", fmt.Errorf("failed to read zip entry %s: %w", file.Name, err)
+	}
+	return string(data), nil
+}
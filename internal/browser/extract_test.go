@@ -0,0 +1,225 @@
+package browser_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ulikunitz/xz"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// serveArchive menjalankan Setup (versi 1.0.0.0) terhadap arsip yang disajikan
+// httptest dan mengembalikan direktori instalasi versi tersebut
+func serveArchive(t *testing.T, name string, archive []byte) (string, error) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	t.Cleanup(srv.Close)
+
+	mgr, rootDir := newTestManager(t, browser.Config{
+		DownloadURL:    srv.URL + "/" + name,
+		InstallDirName: "chrome-test",
+		Version:        "1.0.0.0",
+	})
+	return filepath.Join(rootDir, "custom_url", "1.0.0.0"), mgr.Setup()
+}
+
+func buildTarXz(t *testing.T, headers []*tar.Header) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	xw, err := xz.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(xw)
+	for _, hdr := range headers {
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Size = int64(len("payload"))
+		}
+		if hdr.Mode == 0 && hdr.Typeflag != tar.TypeXGlobalHeader {
+			hdr.Mode = 0o755
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			tw.Write([]byte("payload"))
+		}
+	}
+	tw.Close()
+	xw.Close()
+	return buf.Bytes()
+}
+
+func TestExtractRejectsUnsafeTarEntries(t *testing.T) {
+	cases := map[string]*tar.Header{
+		"dotdot":           {Name: "../../escaped", Typeflag: tar.TypeReg},
+		"absolute":         {Name: "/tmp/escaped", Typeflag: tar.TypeReg},
+		"symlink_outside":  {Name: "chrome-linux64/link", Typeflag: tar.TypeSymlink, Linkname: "../../../etc/passwd"},
+		"symlink_absolute": {Name: "chrome-linux64/link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+		"hardlink":         {Name: "chrome-linux64/hard", Typeflag: tar.TypeLink, Linkname: "chrome-linux64/chrome"},
+		"char_device":      {Name: "chrome-linux64/dev", Typeflag: tar.TypeChar},
+		"fifo":             {Name: "chrome-linux64/fifo", Typeflag: tar.TypeFifo},
+	}
+
+	for name, bad := range cases {
+		t.Run(name, func(t *testing.T) {
+			archive := buildTarXz(t, []*tar.Header{
+				{Name: "chrome-linux64/chrome", Typeflag: tar.TypeReg},
+				bad,
+			})
+
+			installDir, err := serveArchive(t, "chrome.tar.xz", archive)
+			var unsafe *browser.UnsafeEntryError
+			if !errors.As(err, &unsafe) {
+				t.Fatalf("expected UnsafeEntryError, got %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(installDir), "escaped")); !os.IsNotExist(err) {
+				t.Error("file was written outside the install directory")
+			}
+			if _, err := os.Stat(installDir); !os.IsNotExist(err) {
+				t.Error("install directory should not exist after rejected archive")
+			}
+		})
+	}
+}
+
+// Symlink yang valid di dalam arsip tidak boleh dipakai untuk menulis keluar root
+func TestExtractRejectsWriteThroughSymlink(t *testing.T) {
+	archive := buildTarXz(t, []*tar.Header{
+		{Name: "chrome-linux64/chrome", Typeflag: tar.TypeReg},
+		{Name: "chrome-linux64/up", Typeflag: tar.TypeSymlink, Linkname: ".."},
+		{Name: "chrome-linux64/up/up2", Typeflag: tar.TypeSymlink, Linkname: ".."},
+		{Name: "chrome-linux64/up/up2/escaped", Typeflag: tar.TypeReg},
+	})
+
+	installDir, err := serveArchive(t, "chrome.tar.xz", archive)
+	var unsafe *browser.UnsafeEntryError
+	if !errors.As(err, &unsafe) {
+		t.Fatalf("expected UnsafeEntryError, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(installDir), "escaped")); !os.IsNotExist(err) {
+		t.Error("file was written outside the install directory")
+	}
+}
+
+func TestExtractRejectsZipSlip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("chrome-linux64/chrome")
+	w.Write([]byte("payload"))
+	w, _ = zw.Create("chrome-linux64/../../escaped")
+	w.Write([]byte("payload"))
+	zw.Close()
+
+	installDir, err := serveArchive(t, "chrome-linux64.zip", buf.Bytes())
+	var unsafe *browser.UnsafeEntryError
+	if !errors.As(err, &unsafe) {
+		t.Fatalf("expected UnsafeEntryError, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(installDir), "escaped")); !os.IsNotExist(err) {
+		t.Error("file was written outside the install directory")
+	}
+}
+
+// Test header pax global (misalnya dari "git archive") dilewati, bukan ditolak
+func TestExtractSkipsPaxGlobalHeader(t *testing.T) {
+	archive := buildTarXz(t, []*tar.Header{
+		{Name: "pax_global_header", Typeflag: tar.TypeXGlobalHeader, PAXRecords: map[string]string{"comment": "3e5bbd2"}},
+		{Name: "chrome-linux64/chrome", Typeflag: tar.TypeReg},
+	})
+
+	installDir, err := serveArchive(t, "chrome.tar.xz", archive)
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(installDir, "chrome-linux64", "chrome")); err != nil {
+		t.Errorf("chrome not extracted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(installDir, "pax_global_header")); err == nil {
+		t.Error("pax global header should not be written as a file")
+	}
+}
+
+// Symlink yang tidak bisa dibuat karena namanya sudah dipakai direktori
+// tidak boleh dihitung sebagai tertulis
+func TestExtractDetectsSymlinkNotWritten(t *testing.T) {
+	archive := buildTarXz(t, []*tar.Header{
+		{Name: "chrome-linux64/chrome", Typeflag: tar.TypeReg},
+		{Name: "chrome-linux64/lib/", Typeflag: tar.TypeDir},
+		{Name: "chrome-linux64/lib", Typeflag: tar.TypeSymlink, Linkname: "chrome"},
+	})
+
+	installDir, err := serveArchive(t, "chrome.tar.xz", archive)
+	if err == nil || !strings.Contains(err.Error(), "incomplete extraction") {
+		t.Fatalf("expected incomplete extraction error, got %v", err)
+	}
+	if _, err := os.Stat(installDir); !os.IsNotExist(err) {
+		t.Error("install directory should not exist after incomplete extraction")
+	}
+}
+
+// Symlink di-resolve terhadap symlink yang sudah diekstrak, bukan hanya
+// secara tekstual
+func TestExtractRejectsSymlinkChains(t *testing.T) {
+	cases := map[string][]*tar.Header{
+		"dot_then_parent": {
+			{Name: "s", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "s/x", Typeflag: tar.TypeSymlink, Linkname: "../y"},
+		},
+		"parent_through_link": {
+			{Name: "s", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "t", Typeflag: tar.TypeSymlink, Linkname: "s/s/../x"},
+		},
+		"parent_of_missing": {
+			{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "b/.."},
+			{Name: "b", Typeflag: tar.TypeSymlink, Linkname: "."},
+		},
+	}
+	for name, links := range cases {
+		t.Run(name, func(t *testing.T) {
+			headers := append([]*tar.Header{{Name: "chrome-linux64/chrome", Typeflag: tar.TypeReg}}, links...)
+			_, err := serveArchive(t, "chrome.tar.xz", buildTarXz(t, headers))
+			var unsafe *browser.UnsafeEntryError
+			if !errors.As(err, &unsafe) {
+				t.Fatalf("expected UnsafeEntryError, got %v", err)
+			}
+		})
+	}
+
+	// Rantai yang tetap di dalam root diterima
+	archive := buildTarXz(t, []*tar.Header{
+		{Name: "chrome-linux64/chrome", Typeflag: tar.TypeReg},
+		{Name: "chrome-linux64/lib", Typeflag: tar.TypeSymlink, Linkname: "."},
+		{Name: "chrome-linux64/lib/libfoo.so", Typeflag: tar.TypeSymlink, Linkname: "lib/chrome"},
+	})
+	if _, err := serveArchive(t, "chrome.tar.xz", archive); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+}
+
+func TestExtractAllowsSymlinkInsideRoot(t *testing.T) {
+	archive := buildTarXz(t, []*tar.Header{
+		{Name: "chrome-linux64/chrome-real", Typeflag: tar.TypeReg},
+		{Name: "chrome-linux64/chrome", Typeflag: tar.TypeSymlink, Linkname: "chrome-real"},
+	})
+
+	installDir, err := serveArchive(t, "chrome.tar.xz", archive)
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	link, err := os.Readlink(filepath.Join(installDir, "chrome-linux64", "chrome"))
+	if err != nil || link != "chrome-real" {
+		t.Errorf("expected symlink to chrome-real, got %q (%v)", link, err)
+	}
+}
@@ -0,0 +1,269 @@
+package browser_test
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeCDP adalah server DevTools minimal untuk test tanpa Chrome asli.
+// Setiap koneksi websocket dianggap satu proses browser; semua method
+// dijawab dengan result kosong kecuali yang punya handler.
+type fakeCDP struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	conns    []*fakeConn
+	calls    map[string]int
+	handlers map[string]func(params json.RawMessage) (any, error)
+	nextID   int
+}
+
+// fakeConn adalah satu koneksi websocket dari Rod
+type fakeConn struct {
+	conn    net.Conn
+	writeMu sync.Mutex
+	closed  chan struct{}
+	once    sync.Once
+}
+
+type cdpRequest struct {
+	ID        int             `json:"id"`
+	Method    string          `json:"method"`
+	Params    json.RawMessage `json:"params"`
+	SessionID string          `json:"sessionId,omitempty"`
+}
+
+func newFakeCDP(t *testing.T) *fakeCDP {
+	t.Helper()
+	f := &fakeCDP{calls: map[string]int{}, handlers: map[string]func(json.RawMessage) (any, error){}}
+	f.Handle("Target.createBrowserContext", func(json.RawMessage) (any, error) {
+		return map[string]string{"browserContextId": f.newID("context")}, nil
+	})
+	f.Handle("Target.createTarget", func(json.RawMessage) (any, error) {
+		return map[string]string{"targetId": f.newID("target")}, nil
+	})
+	f.Handle("Target.attachToTarget", func(json.RawMessage) (any, error) {
+		return map[string]string{"sessionId": f.newID("session")}, nil
+	})
+
+	f.srv = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(func() {
+		f.DropAll()
+		f.srv.Close()
+	})
+	return f
+}
+
+// Script mengembalikan shell script yang berpura-pura menjadi Chrome.
+// afterListen dijalankan setelah URL DevTools dicetak.
+func (f *fakeCDP) Script(afterListen ...string) string {
+	return fmt.Sprintf("#!/bin/sh\necho \"DevTools listening on %s/devtools/browser/fake\" >&2\n%s\nexec sleep 30\n",
+		"ws://"+f.srv.Listener.Addr().String(), strings.Join(afterListen, "\n"))
+}
+
+// Handle mengganti jawaban untuk method tertentu
+func (f *fakeCDP) Handle(method string, h func(params json.RawMessage) (any, error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers[method] = h
+}
+
+// Calls mengembalikan berapa kali method dipanggil
+func (f *fakeCDP) Calls(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+// Connections mengembalikan jumlah koneksi browser sejak server dibuat
+func (f *fakeCDP) Connections() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.conns)
+}
+
+// Drop memutus koneksi browser ke-i, seolah-olah Chrome crash
+func (f *fakeCDP) Drop(i int) {
+	f.mu.Lock()
+	c := f.conns[i]
+	f.mu.Unlock()
+	c.close()
+}
+
+// DropAll memutus semua koneksi browser
+func (f *fakeCDP) DropAll() {
+	f.mu.Lock()
+	conns := append([]*fakeConn(nil), f.conns...)
+	f.mu.Unlock()
+	for _, c := range conns {
+		c.close()
+	}
+}
+
+// Emit mengirim event CDP ke semua koneksi yang masih terbuka
+func (f *fakeCDP) Emit(method, sessionID string, params any) {
+	f.mu.Lock()
+	conns := append([]*fakeConn(nil), f.conns...)
+	f.mu.Unlock()
+	msg := map[string]any{"method": method, "params": params}
+	if sessionID != "" {
+		msg["sessionId"] = sessionID
+	}
+	data, _ := json.Marshal(msg)
+	for _, c := range conns {
+		c.write(data)
+	}
+}
+
+func (f *fakeCDP) newID(prefix string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	return fmt.Sprintf("%s-%d", prefix, f.nextID)
+}
+
+func (f *fakeCDP) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/json/version" {
+		json.NewEncoder(w).Encode(map[string]string{
+			"webSocketDebuggerUrl": "ws://" + r.Host + "/devtools/browser/fake",
+		})
+		return
+	}
+
+	// Handshake websocket (RFC 6455)
+	key := r.Header.Get("Sec-WebSocket-Key")
+	hj, ok := w.(http.Hijacker)
+	if key == "" || !ok {
+		http.Error(w, "websocket only", http.StatusBadRequest)
+		return
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	sum := sha1.Sum([]byte(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(sum[:]))
+	rw.Flush()
+
+	c := &fakeConn{conn: conn, closed: make(chan struct{})}
+	f.mu.Lock()
+	f.conns = append(f.conns, c)
+	f.mu.Unlock()
+
+	defer c.close()
+	for {
+		payload, err := readFrame(rw.Reader)
+		if err != nil {
+			return
+		}
+		var req cdpRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			continue
+		}
+
+		f.mu.Lock()
+		f.calls[req.Method]++
+		h := f.handlers[req.Method]
+		f.mu.Unlock()
+
+		resp := map[string]any{"id": req.ID}
+		if req.SessionID != "" {
+			resp["sessionId"] = req.SessionID
+		}
+		var result any = map[string]any{}
+		if h != nil {
+			result, err = h(req.Params)
+		}
+		if err != nil {
+			resp["error"] = map[string]any{"code": -32000, "message": err.Error()}
+		} else {
+			resp["result"] = result
+		}
+		data, _ := json.Marshal(resp)
+		c.write(data)
+
+		if req.Method == "Browser.close" {
+			return
+		}
+	}
+}
+
+func (c *fakeConn) write(payload []byte) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	select {
+	case <-c.closed:
+		return
+	default:
+	}
+	header := []byte{0x81}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126, byte(n>>8), byte(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	c.conn.Write(append(header, payload...))
+}
+
+func (c *fakeConn) close() {
+	c.once.Do(func() {
+		close(c.closed)
+		c.conn.Close()
+	})
+}
+
+// readFrame membaca satu frame websocket dari client (selalu di-mask)
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+	if head[0]&0x0F == 0x8 {
+		return nil, io.EOF
+	}
+	n := uint64(head[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	var mask [4]byte
+	if head[1]&0x80 != 0 {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return nil, err
+		}
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return payload, nil
+}
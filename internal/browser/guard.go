@@ -0,0 +1,300 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// blockedPrefixes adalah range yang tidak tercakup helper netip.Addr
+// (IsPrivate, IsLoopback, IsLinkLocalUnicast, dst.) tetapi tetap bukan internet publik
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, termasuk broadcast
+	netip.MustParsePrefix("64:ff9b:1::/48"), // NAT64 lokal
+	netip.MustParsePrefix("2001:db8::/32"),  // dokumentasi
+}
+
+// Resolver me-resolve hostname ke IP; *net.Resolver memenuhi interface ini
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// NetworkGuard mencegah browser mengakses alamat private, loopback,
+// link-local dan endpoint metadata cloud (SSRF). Hostname setiap request
+// di-resolve dan dicek sebelum request diteruskan (Fetch domain), lalu Chrome
+// memuatnya sendiri lewat GuardProxy yang mengecek ulang IP saat connect
+// sehingga DNS rebinding, redirect dan WebSocket ke alamat internal juga
+// diblokir.
+type NetworkGuard struct {
+	// AllowCIDRs dikecualikan dari blokir, contoh "10.20.0.0/16" untuk service internal
+	AllowCIDRs []string
+	// Resolver untuk lookup hostname; nil berarti net.DefaultResolver
+	Resolver Resolver
+	// Timeout untuk satu request HTTPClient; 0 berarti 30 detik
+	Timeout time.Duration
+	// OnBlocked dipanggil untuk setiap request yang diblokir
+	OnBlocked func(*BlockedAddressError)
+
+	blocked atomic.Int64
+}
+
+// BlockedAddressError dikembalikan jika URL mengarah ke alamat yang dilarang
+type BlockedAddressError struct {
+	URL  string
+	Host string
+	// Addr adalah IP yang ditolak
+	Addr netip.Addr
+	// Rebinding true jika hostname lolos pengecekan awal tetapi saat connect
+	// di-resolve ke alamat yang dilarang
+	Rebinding bool
+}
+
+func (e *BlockedAddressError) Error() string {
+	msg := fmt.Sprintf("request to %s blocked: %s resolves to non-public address %s", e.URL, e.Host, e.Addr)
+	if e.Rebinding {
+		msg += " (dns rebinding)"
+	}
+	return msg
+}
+
+// Blocked mengembalikan jumlah request yang sudah diblokir guard
+func (g *NetworkGuard) Blocked() int64 {
+	return g.blocked.Load()
+}
+
+// Check me-resolve host rawURL dan mengembalikan *BlockedAddressError jika
+// salah satu alamatnya tidak publik. URL selain http, https, ws dan wss lolos.
+func (g *NetworkGuard) Check(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "ws", "wss":
+	default:
+		return nil
+	}
+	err = g.checkHost(ctx, u.Hostname())
+	var blocked *BlockedAddressError
+	if errors.As(err, &blocked) {
+		blocked.URL = rawURL
+	}
+	return err
+}
+
+// checkHost me-resolve host dan mengembalikan *BlockedAddressError (tanpa
+// URL) jika salah satu alamatnya tidak publik
+func (g *NetworkGuard) checkHost(ctx context.Context, host string) error {
+	allow, err := g.allowPrefixes()
+	if err != nil {
+		return err
+	}
+	addrs, err := g.lookup(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !isPublicAddr(addr, allow) {
+			return &BlockedAddressError{Host: host, Addr: addr}
+		}
+	}
+	return nil
+}
+
+// HTTPClient mengembalikan http.Client yang menolak koneksi ke alamat tidak
+// publik, termasuk setelah redirect. Berguna untuk mengambil URL dari user di
+// luar browser dengan aturan yang sama.
+func (g *NetworkGuard) HTTPClient() *http.Client {
+	return &http.Client{Transport: checkedTransport{g, g.newTransport(nil)}, Timeout: g.timeout()}
+}
+
+// checkedTransport menjalankan Check sebelum setiap request, termasuk redirect
+type checkedTransport struct {
+	guard *NetworkGuard
+	next  http.RoundTripper
+}
+
+func (t checkedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.guard.Check(req.Context(), req.URL.String()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// handler mengembalikan requestHandler yang memblokir request ke alamat tidak
+// publik. Request yang lolos diteruskan ke Chrome (ContinueRequest) dan dimuat
+// lewat GuardProxy, yang mengecek ulang IP saat connect.
+func (g *NetworkGuard) handler(logger func(key, value string)) requestHandler {
+	return func(h *rod.Hijack) bool {
+		rawURL := h.Request.URL().String()
+		err := g.Check(h.Request.Req().Context(), rawURL)
+		if err == nil {
+			return false
+		}
+
+		var blocked *BlockedAddressError
+		if errors.As(err, &blocked) {
+			g.report(blocked, string(h.Request.Type()), logger)
+			h.Response.Fail(proto.NetworkErrorReasonAddressUnreachable)
+			return true
+		}
+		logger("ssrf_guard_error", fmt.Sprintf("url=%s error=%v", rawURL, err))
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			h.Response.Fail(proto.NetworkErrorReasonNameNotResolved)
+		} else {
+			h.Response.Fail(proto.NetworkErrorReasonFailed)
+		}
+		return true
+	}
+}
+
+// report mencatat request yang diblokir; kind adalah tipe resource atau "proxy"
+func (g *NetworkGuard) report(blocked *BlockedAddressError, kind string, logger func(key, value string)) {
+	g.blocked.Add(1)
+	logger("ssrf_blocked", fmt.Sprintf("type=%s url=%s addr=%s rebinding=%t",
+		kind, blocked.URL, blocked.Addr, blocked.Rebinding))
+	if g.OnBlocked != nil {
+		g.OnBlocked(blocked)
+	}
+}
+
+// newTransport mengembalikan transport yang mengecek ulang IP tepat sebelum
+// connect, sehingga hasil DNS yang berubah setelah Check tidak bisa dipakai
+// untuk mencapai alamat internal. Jika upstream diisi, request diteruskan ke
+// proxy tersebut; koneksi ke upstream sendiri tidak dicek.
+func (g *NetworkGuard) newTransport(upstream *url.URL) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	var upstreamAddr string
+	if upstream != nil {
+		transport.Proxy = http.ProxyURL(upstream)
+		upstreamAddr = proxyAddr(upstream)
+	}
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return g.dialContext(ctx, network, address, upstreamAddr)
+	}
+	return transport
+}
+
+// dialContext me-resolve dan mengecek host di address lalu connect ke IP
+// yang sudah dicek. address yang sama dengan trusted di-dial tanpa dicek.
+func (g *NetworkGuard) dialContext(ctx context.Context, network, address, trusted string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	if address == trusted {
+		return dialer.DialContext(ctx, network, address)
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	allow, err := g.allowPrefixes()
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := g.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		if !isPublicAddr(addr, allow) {
+			return nil, &BlockedAddressError{URL: address, Host: host, Addr: addr, Rebinding: true}
+		}
+	}
+	var lastErr error
+	for _, addr := range addrs {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// lookup me-resolve host; IP literal dikembalikan langsung
+func (g *NetworkGuard) lookup(ctx context.Context, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil {
+		return []netip.Addr{addr.Unmap()}, nil
+	}
+	resolver := g.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	ipAddrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	if len(ipAddrs) == 0 {
+		return nil, fmt.Errorf("failed to resolve %s: no addresses", host)
+	}
+	addrs := make([]netip.Addr, 0, len(ipAddrs))
+	for _, ip := range ipAddrs {
+		addr, ok := netip.AddrFromSlice(ip.IP)
+		if !ok {
+			return nil, fmt.Errorf("failed to resolve %s: invalid address %v", host, ip.IP)
+		}
+		addrs = append(addrs, addr.Unmap())
+	}
+	return addrs, nil
+}
+
+func (g *NetworkGuard) allowPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(g.AllowCIDRs))
+	for _, cidr := range g.AllowCIDRs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed CIDR %q: %w", cidr, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+func (g *NetworkGuard) timeout() time.Duration {
+	if g.Timeout > 0 {
+		return g.Timeout
+	}
+	return 30 * time.Second
+}
+
+// validate mengecek AllowCIDRs
+func (g *NetworkGuard) validate() error {
+	_, err := g.allowPrefixes()
+	return err
+}
+
+// isPublicAddr mengecek apakah addr boleh diakses
+func isPublicAddr(addr netip.Addr, allow []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range allow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,386 @@
+package browser
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// GuardProxy adalah forward proxy HTTP lokal yang menjadi --proxy-server
+// Chrome saat NetworkGuard atau Policy aktif. Setiap koneksi keluar (HTTP,
+// CONNECT untuk HTTPS dan WebSocket) dibuka lewat dialer guard yang mengecek
+// IP tepat sebelum connect, sehingga DNS rebinding dan redirect ke alamat
+// internal tetap diblokir walaupun request diteruskan Chrome apa adanya
+// (cookie, TLS dan HTTP/2 tetap milik Chrome). WebSocket tidak terlihat oleh
+// Fetch domain, sehingga Policy juga ditegakkan di sini (lihat
+// Policy.allowsConnection).
+type GuardProxy struct {
+	// guard nil jika proxy hanya menegakkan Policy
+	guard  *NetworkGuard
+	policy *Policy
+	logger func(key, value string)
+	// upstream adalah proxy berikutnya (LaunchOptions.Proxy), nil jika langsung
+	upstream *url.URL
+
+	listener net.Listener
+	server   *http.Server
+	reverse  *httputil.ReverseProxy
+
+	closeOnce sync.Once
+}
+
+// StartProxy menjalankan GuardProxy di 127.0.0.1 dengan port acak. upstream
+// (opsional, "http://" atau "https://") adalah proxy berikutnya; dengan
+// upstream, hostname tujuan dicek sebelum diteruskan tetapi di-resolve ulang
+// oleh upstream sehingga perlindungan DNS rebinding bergantung pada upstream.
+func (g *NetworkGuard) StartProxy(upstream string) (*GuardProxy, error) {
+	return startGuardProxy(g, nil, upstream, func(string, string) {})
+}
+
+// startGuardProxy menjalankan GuardProxy untuk guard dan/atau policy (salah
+// satunya boleh nil)
+func startGuardProxy(guard *NetworkGuard, policy *Policy, upstream string, logger func(key, value string)) (*GuardProxy, error) {
+	upstreamURL, err := parseUpstreamProxy(upstream)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start network guard proxy: %w", err)
+	}
+
+	var transport http.RoundTripper
+	if guard != nil {
+		transport = checkedTransport{guard, guard.newTransport(upstreamURL)}
+	} else {
+		plain := http.DefaultTransport.(*http.Transport).Clone()
+		plain.Proxy = nil
+		if upstreamURL != nil {
+			plain.Proxy = http.ProxyURL(upstreamURL)
+		}
+		transport = plain
+	}
+
+	p := &GuardProxy{guard: guard, policy: policy, logger: logger, upstream: upstreamURL, listener: listener}
+	p.reverse = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.Out.URL = r.In.URL
+			r.Out.Host = r.In.Host
+		},
+		Transport:     transport,
+		FlushInterval: -1,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			p.fail(w, r.URL.String(), err)
+		},
+	}
+	p.server = &http.Server{Handler: p, ReadHeaderTimeout: 30 * time.Second}
+	go p.server.Serve(listener)
+	return p, nil
+}
+
+// parseUpstreamProxy memvalidasi proxy upstream; tanpa skema berarti http
+func parseUpstreamProxy(upstream string) (*url.URL, error) {
+	if upstream == "" {
+		return nil, nil
+	}
+	raw := upstream
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q", redactProxy(upstream))
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("network guard only supports http and https upstream proxies, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", redactProxy(upstream))
+	}
+	return u, nil
+}
+
+// Addr mengembalikan alamat listen proxy (host:port)
+func (p *GuardProxy) Addr() string {
+	return p.listener.Addr().String()
+}
+
+// URL mengembalikan URL proxy untuk --proxy-server
+func (p *GuardProxy) URL() string {
+	return "http://" + p.Addr()
+}
+
+// Close menghentikan proxy dan memutus koneksi yang masih terbuka
+func (p *GuardProxy) Close() error {
+	var err error
+	p.closeOnce.Do(func() {
+		err = p.server.Close()
+	})
+	return err
+}
+
+func (p *GuardProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.serveConnect(w, r)
+		return
+	}
+	if !r.URL.IsAbs() {
+		http.Error(w, "network guard proxy only accepts absolute URLs", http.StatusBadRequest)
+		return
+	}
+	// Handshake ws:// lewat proxy tanpa tunnel
+	if p.policy != nil && isWebSocketUpgrade(r) {
+		wsURL := *r.URL
+		wsURL.Scheme = "ws"
+		if r.URL.Scheme == "https" {
+			wsURL.Scheme = "wss"
+		}
+		if ok, reason := p.policy.Decide(wsURL.String(), string(proto.NetworkResourceTypeWebSocket)); !ok {
+			p.deny(w, string(proto.NetworkResourceTypeWebSocket), wsURL.String(), reason)
+			return
+		}
+	}
+	p.reverse.ServeHTTP(w, r)
+}
+
+// isWebSocketUpgrade mengecek apakah r adalah handshake WebSocket
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// deny menolak request atau tunnel yang tidak diizinkan Policy
+func (p *GuardProxy) deny(w http.ResponseWriter, kind, rawURL, reason string) {
+	p.logger("policy_blocked", fmt.Sprintf("type=%s url=%s reason=%s", kind, rawURL, reason))
+	http.Error(w, fmt.Sprintf("%s blocked by policy: %s", rawURL, reason), http.StatusForbidden)
+}
+
+// serveConnect membuka tunnel ke host:port tujuan (HTTPS dan WebSocket)
+func (p *GuardProxy) serveConnect(w http.ResponseWriter, r *http.Request) {
+	target := r.Host
+	rawURL := "https://" + target
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		http.Error(w, "invalid CONNECT target", http.StatusBadRequest)
+		return
+	}
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
+		http.Error(w, "invalid CONNECT target", http.StatusBadRequest)
+		return
+	}
+	// Isi tunnel belum terlihat: tolak jika tidak ada scheme yang diizinkan
+	// ke host:port ini, sebelum connect ke tujuan
+	if p.policy != nil {
+		if ok, reason := p.policy.allowsConnection(host, portNum, "https", "wss", "http", "ws"); !ok {
+			p.deny(w, "connect", target, reason)
+			return
+		}
+	}
+	if p.guard != nil {
+		if err := p.guard.checkHost(r.Context(), host); err != nil {
+			p.fail(w, rawURL, err)
+			return
+		}
+	}
+	upstream, err := p.dialTunnel(r.Context(), target)
+	if err != nil {
+		p.fail(w, rawURL, err)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	if _, err := io.WriteString(client, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		client.Close()
+		upstream.Close()
+		return
+	}
+	if p.policy != nil && !p.checkTunnel(client, buffered.Reader, upstream, host, portNum, target) {
+		client.Close()
+		upstream.Close()
+		return
+	}
+	pipeConns(client, buffered.Reader, upstream)
+}
+
+// checkTunnel mengecek awal data tunnel terhadap Policy. TLS (https atau
+// wss) hanya bisa dicek per host dan port; handshake ws:// yang tidak
+// terenkripsi dicek lengkap dengan path-nya lalu diteruskan ke upstream.
+func (p *GuardProxy) checkTunnel(client net.Conn, reader *bufio.Reader, upstream net.Conn, host string, port int, target string) bool {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return false
+	}
+	if first[0] == tlsHandshakeRecord {
+		ok, reason := p.policy.allowsConnection(host, port, "https", "wss")
+		if !ok {
+			p.logger("policy_blocked", fmt.Sprintf("type=connect url=%s reason=%s", target, reason))
+		}
+		return ok
+	}
+
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		return false
+	}
+	scheme, kind := "http", "connect"
+	if isWebSocketUpgrade(req) {
+		scheme, kind = "ws", string(proto.NetworkResourceTypeWebSocket)
+	}
+	rawURL := scheme + "://" + target + req.URL.RequestURI()
+	var ok bool
+	var reason string
+	if kind == "connect" {
+		ok, reason = p.policy.allowsConnection(host, port, scheme)
+	} else {
+		ok, reason = p.policy.Decide(rawURL, kind)
+	}
+	if !ok {
+		p.logger("policy_blocked", fmt.Sprintf("type=%s url=%s reason=%s", kind, rawURL, reason))
+		resp := &http.Response{
+			StatusCode: http.StatusForbidden,
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{},
+			Close:      true,
+		}
+		resp.Write(client)
+		return false
+	}
+	return req.Write(upstream) == nil
+}
+
+// tlsHandshakeRecord adalah byte pertama record TLS handshake (ClientHello)
+const tlsHandshakeRecord = 0x16
+
+// dialTunnel membuka koneksi ke target lewat dialer guard, atau lewat CONNECT
+// ke proxy upstream
+func (p *GuardProxy) dialTunnel(ctx context.Context, target string) (net.Conn, error) {
+	var dialer net.Dialer
+	if p.upstream == nil {
+		if p.guard == nil {
+			return dialer.DialContext(ctx, "tcp", target)
+		}
+		return p.guard.dialContext(ctx, "tcp", target, "")
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddr(p.upstream))
+	if err != nil {
+		return nil, err
+	}
+	if p.upstream.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: p.upstream.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: target},
+		Host:   target,
+		Header: http.Header{},
+	}
+	if user := p.upstream.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("upstream proxy CONNECT %s: %s", target, resp.Status)
+	}
+	if reader.Buffered() > 0 {
+		// Data yang terlanjur dibaca setelah header respons milik tunnel
+		return &bufferedConn{Conn: conn, r: reader}, nil
+	}
+	return conn, nil
+}
+
+// fail menjawab request yang gagal; alamat yang diblokir dilaporkan ke guard
+func (p *GuardProxy) fail(w http.ResponseWriter, rawURL string, err error) {
+	var blocked *BlockedAddressError
+	if p.guard != nil && errors.As(err, &blocked) {
+		blocked.URL = rawURL
+		p.guard.report(blocked, "proxy", p.logger)
+		http.Error(w, blocked.Error(), http.StatusForbidden)
+		return
+	}
+	p.logger("ssrf_guard_error", fmt.Sprintf("url=%s error=%v", rawURL, err))
+	http.Error(w, err.Error(), http.StatusBadGateway)
+}
+
+// bufferedConn membaca sisa data di r sebelum membaca dari Conn
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// pipeConns menyalin data dua arah sampai salah satu sisi selesai.
+// clientReader berisi data client yang sudah dibuffer server HTTP.
+func pipeConns(client net.Conn, clientReader io.Reader, upstream net.Conn) {
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(upstream, clientReader)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(client, upstream)
+		done <- struct{}{}
+	}()
+	<-done
+	client.Close()
+	upstream.Close()
+	<-done
+}
+
+// proxyAddr mengembalikan host:port proxy dengan port default skemanya
+func proxyAddr(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	if u.Scheme == "https" {
+		return net.JoinHostPort(u.Hostname(), "443")
+	}
+	return net.JoinHostPort(u.Hostname(), "80")
+}
@@ -0,0 +1,310 @@
+package browser_test
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// fakeResolver menjawab lookup dari tabel; jawaban terakhir diulang jika
+// host di-resolve lebih sering dari jumlah jawaban (untuk simulasi rebinding)
+type fakeResolver struct {
+	mu      sync.Mutex
+	answers map[string][]string
+	lookups map[string]int
+}
+
+func (r *fakeResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	answers, ok := r.answers[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	if r.lookups == nil {
+		r.lookups = map[string]int{}
+	}
+	i := r.lookups[host]
+	r.lookups[host]++
+	if i >= len(answers) {
+		i = len(answers) - 1
+	}
+	return []net.IPAddr{{IP: net.ParseIP(answers[i])}}, nil
+}
+
+func TestNetworkGuardCheck(t *testing.T) {
+	guard := &browser.NetworkGuard{
+		AllowCIDRs: []string{"10.20.0.0/16"},
+		Resolver: &fakeResolver{answers: map[string][]string{
+			"internal.test": {"192.168.1.10"},
+			"public.test":   {"93.184.216.34"},
+			"service.test":  {"10.20.1.1"},
+		}},
+	}
+
+	tests := []struct {
+		url     string
+		blocked bool
+	}{
+		{"http://169.254.169.254/latest/meta-data/", true},
+		{"http://127.0.0.1:8080/", true},
+		{"http://10.0.0.1/", true},
+		{"http://172.16.5.4/", true},
+		{"https://192.168.0.1/", true},
+		{"http://100.64.0.1/", true},
+		{"http://0.0.0.0/", true},
+		{"http://[::1]/", true},
+		{"http://[::ffff:127.0.0.1]/", true},
+		{"http://[fd00:ec2::254]/", true},
+		{"http://[fe80::1]/", true},
+		{"http://internal.test/", true},
+		{"http://8.8.8.8/", false},
+		{"https://public.test/", false},
+		{"http://10.20.3.4/", false},
+		{"http://service.test/", false},
+		{"data:text/plain,hello", false},
+	}
+	for _, tt := range tests {
+		err := guard.Check(context.Background(), tt.url)
+		var blocked *browser.BlockedAddressError
+		if got := errors.As(err, &blocked); got != tt.blocked {
+			t.Errorf("Check(%s) = %v, want blocked=%v", tt.url, err, tt.blocked)
+		}
+	}
+
+	if err := (&browser.NetworkGuard{AllowCIDRs: []string{"not-a-cidr"}}).Check(context.Background(), "http://8.8.8.8/"); err == nil {
+		t.Error("invalid AllowCIDRs should fail")
+	}
+}
+
+func TestNetworkGuardHTTPClientBlocksRedirect(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://metadata.test/latest/meta-data/", http.StatusFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	guard := &browser.NetworkGuard{
+		AllowCIDRs: []string{"127.0.0.1/32"},
+		Resolver: &fakeResolver{answers: map[string][]string{
+			"metadata.test": {"169.254.169.254"},
+		}},
+	}
+	client := guard.HTTPClient()
+
+	resp, err := client.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("allowed request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	_, err = client.Get(srv.URL + "/redirect")
+	var blocked *browser.BlockedAddressError
+	if !errors.As(err, &blocked) {
+		t.Fatalf("expected BlockedAddressError after redirect, got %v", err)
+	}
+	if blocked.Addr != netip.MustParseAddr("169.254.169.254") || blocked.Rebinding {
+		t.Errorf("unexpected blocked error: %+v", blocked)
+	}
+}
+
+// proxyFlag membaca nilai --proxy-server dari argumen yang dicatat fake chrome
+func proxyFlag(t *testing.T, argsFile string) string {
+	t.Helper()
+	var value string
+	waitFor(t, "chrome args to be recorded", func() bool {
+		data, _ := os.ReadFile(argsFile)
+		for _, arg := range strings.Split(string(data), "\n") {
+			if v, ok := strings.CutPrefix(arg, "--proxy-server="); ok {
+				value = v
+				return true
+			}
+		}
+		return false
+	})
+	return value
+}
+
+func TestNetworkGuardInterceptsBrowserRequests(t *testing.T) {
+	var mu sync.Mutex
+	var cookies []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		cookies = append(cookies, r.Header.Get("Cookie"))
+		mu.Unlock()
+		w.Write([]byte("ok"))
+	})
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	tlsSrv := httptest.NewTLSServer(handler)
+	defer tlsSrv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	_, tlsPort, _ := net.SplitHostPort(tlsSrv.Listener.Addr().String())
+
+	var reported []*browser.BlockedAddressError
+	guard := &browser.NetworkGuard{
+		AllowCIDRs: []string{"127.0.0.1/32"},
+		Resolver: &fakeResolver{answers: map[string][]string{
+			"app.test": {"127.0.0.1"},
+			// Lolos pengecekan proxy, saat connect berubah ke alamat internal
+			"rebind.test": {"93.184.216.34", "10.0.0.1"},
+			"ws.test":     {"169.254.169.254"},
+		}},
+		OnBlocked: func(err *browser.BlockedAddressError) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, err)
+		},
+	}
+
+	cdp := newFakeCDP(t)
+	logs := &logRecorder{}
+	argsFile := filepath.Join(t.TempDir(), "args")
+	mgr := installFakeChromeWithLogger(t, cdp.Script(`printf '%s\n' "$@" > `+argsFile), logs.Log)
+	b, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{
+		Sandbox:      browser.SandboxOff,
+		NetworkGuard: guard,
+	})
+	if err != nil {
+		t.Fatalf("GetBrowserWithOptions failed: %v", err)
+	}
+	defer b.Close()
+
+	// Request yang lolos diteruskan ke Chrome, bukan dimuat ulang di Go
+	cdp.Emit("Fetch.requestPaused", "", pausedRequest("req-1", "http://app.test:"+port+"/", "Document"))
+	waitFor(t, "allowed request to be continued", func() bool { return cdp.Calls("Fetch.continueRequest") == 1 })
+	cdp.Emit("Fetch.requestPaused", "", pausedRequest("req-2", "http://169.254.169.254/latest/meta-data/", "XHR"))
+	waitFor(t, "blocked request to fail", func() bool { return cdp.Calls("Fetch.failRequest") == 1 })
+	if cdp.Calls("Fetch.fulfillRequest") != 0 {
+		t.Error("guard must not fulfill requests itself")
+	}
+
+	// Chrome memuat request lewat GuardProxy
+	proxyURL, err := url.Parse(proxyFlag(t, argsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	for _, target := range []string{"http://app.test:" + port + "/", "https://app.test:" + tlsPort + "/"} {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Cookie", "session=abc")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s through proxy: %v", target, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s through proxy: status %d", target, resp.StatusCode)
+		}
+	}
+	mu.Lock()
+	if len(cookies) != 2 || cookies[0] != "session=abc" || cookies[1] != "session=abc" {
+		t.Errorf("cookies should reach the server unchanged, got %q", cookies)
+	}
+	mu.Unlock()
+
+	// Rebinding saat connect dan tunnel (WebSocket/HTTPS) ke alamat internal
+	resp, err := client.Get("http://rebind.test:" + port + "/")
+	if err != nil {
+		t.Fatalf("GET rebind.test: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("rebinding through proxy: status %d, want 403", resp.StatusCode)
+	}
+	if _, err := client.Get("https://ws.test:443/"); err == nil {
+		t.Error("CONNECT to metadata address should fail")
+	}
+
+	if guard.Blocked() != 3 {
+		t.Errorf("Blocked() = %d, want 3", guard.Blocked())
+	}
+	if !logs.Has("ssrf_blocked", "169.254.169.254") {
+		t.Error("metadata request should be logged")
+	}
+	if !logs.Has("ssrf_blocked", "rebinding=true") {
+		t.Error("rebinding should be logged")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var rebinding bool
+	for _, err := range reported {
+		if err.Rebinding && strings.HasPrefix(err.URL, "http://rebind.test") {
+			rebinding = true
+		}
+	}
+	if len(reported) != 3 || !rebinding {
+		t.Errorf("unexpected reports: %v", reported)
+	}
+}
+
+// Test proxy upstream selain http/https ditolak saat NetworkGuard aktif
+func TestNetworkGuardRejectsSocksProxy(t *testing.T) {
+	mgr := browser.NewChromiumManagerWithConfig(browser.Config{InstallDirName: "chrome-test"}, nil)
+	_, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{
+		Proxy:        "socks5://127.0.0.1:1080",
+		NetworkGuard: &browser.NetworkGuard{},
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid launch options") {
+		t.Errorf("expected invalid launch options error, got %v", err)
+	}
+}
+
+// Test LaunchOptions.Proxy dipakai sebagai upstream: tujuan tetap dicek,
+// koneksi ke upstream (alamat loopback) tidak diblokir
+func TestNetworkGuardProxyUpstream(t *testing.T) {
+	var forwarded []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = append(forwarded, r.URL.String())
+		w.Write([]byte("via upstream"))
+	}))
+	defer upstream.Close()
+
+	guard := &browser.NetworkGuard{Resolver: &fakeResolver{answers: map[string][]string{
+		"public.test":   {"93.184.216.34"},
+		"internal.test": {"10.0.0.1"},
+	}}}
+	proxy, err := guard.StartProxy(upstream.URL)
+	if err != nil {
+		t.Fatalf("StartProxy: %v", err)
+	}
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL())
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	for target, want := range map[string]int{
+		"http://public.test/page":   http.StatusOK,
+		"http://internal.test/page": http.StatusForbidden,
+	} {
+		resp, err := client.Get(target)
+		if err != nil {
+			t.Fatalf("GET %s: %v", target, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET %s: status %d, want %d", target, resp.StatusCode, want)
+		}
+	}
+	if len(forwarded) != 1 || forwarded[0] != "http://public.test/page" {
+		t.Errorf("upstream received %v", forwarded)
+	}
+}
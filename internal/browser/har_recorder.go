@@ -0,0 +1,688 @@
+package browser
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// defaultHARMaxBodySize adalah batas ukuran body yang direkam jika MaxBodySize 0
+const defaultHARMaxBodySize = 1 << 20
+
+// harCreatorName dicatat sebagai creator di setiap file HAR
+const harCreatorName = "go-rod-testing-browser-restrict"
+
+// unsafeFileChars dipakai untuk membuat nama file HAR dari target ID
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// HAROptions mengatur perekaman HAR
+type HAROptions struct {
+	// Dir dipakai untuk perekaman global lewat LaunchOptions.HAR: setiap page
+	// ditulis ke Dir/<target-id>.har saat page ditutup atau browser berhenti
+	Dir string
+	// IncludeBodies merekam body response (lewat Network.getResponseBody)
+	IncludeBodies bool
+	// MaxBodySize membatasi body request/response yang disimpan dalam byte;
+	// body yang lebih besar dilewati dengan komentar. 0 berarti 1 MiB.
+	MaxBodySize int
+}
+
+func (o HAROptions) maxBodySize() int {
+	if o.MaxBodySize > 0 {
+		return o.MaxBodySize
+	}
+	return defaultHARMaxBodySize
+}
+
+// HARRecorder merekam lalu lintas jaringan satu page dari event Network CDP
+type HARRecorder struct {
+	page   *rod.Page
+	opts   HAROptions
+	cancel context.CancelFunc
+	// mainFrame adalah frame top-level page; kosong jika tidak diketahui
+	mainFrame proto.PageFrameID
+
+	mu       sync.Mutex
+	entries  []*harEntryState
+	byID     map[proto.NetworkRequestID]*harEntryState
+	pages    []*harPageState
+	bodies   sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// harEntryState adalah entry yang masih direkam
+type harEntryState struct {
+	entry     HAREntry
+	startTime proto.MonotonicTime
+	timing    *proto.NetworkResourceTiming
+}
+
+// harPageState adalah page HAR yang masih direkam
+type harPageState struct {
+	page      HARPage
+	startTime proto.MonotonicTime
+}
+
+// RecordHAR mulai merekam request page sampai Stop dipanggil atau page ditutup
+func (cm *ChromiumManager) RecordHAR(page *rod.Page, opts HAROptions) (*HARRecorder, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	browser := page.Browser().Context(ctx)
+	r := newHARRecorder(browser.PageFromSession(page.SessionID), page.TargetID, opts)
+	r.cancel = cancel
+
+	// Berlangganan sebelum domain diaktifkan agar tidak ada event yang terlewat
+	messages := browser.Event()
+	go func() {
+		for msg := range messages {
+			if msg.SessionID == page.SessionID {
+				r.handle(msg)
+				continue
+			}
+			// Session page dilepas saat page ditutup; rekaman yang sudah ada
+			// tetap bisa diambil
+			var detached proto.TargetDetachedFromTarget
+			var destroyed proto.TargetTargetDestroyed
+			if msg.Load(&detached) && detached.SessionID == page.SessionID ||
+				msg.Load(&destroyed) && destroyed.TargetID == page.TargetID {
+				r.Stop()
+				cm.logger("har_stopped", fmt.Sprintf("target=%s reason=page closed", page.TargetID))
+				return
+			}
+		}
+	}()
+	if err := r.enable(); err != nil {
+		cancel()
+		return nil, err
+	}
+	cm.logger("har_recording", fmt.Sprintf("target=%s bodies=%t", page.TargetID, opts.IncludeBodies))
+	return r, nil
+}
+
+// newHARRecorder membuat recorder untuk session page. Event dimasukkan lewat
+// handle oleh pemanggil agar urutannya sama dengan urutan dari Chrome.
+func newHARRecorder(page *rod.Page, target proto.TargetTargetID, opts HAROptions) *HARRecorder {
+	return &HARRecorder{
+		page:      page,
+		opts:      opts,
+		cancel:    func() {},
+		mainFrame: proto.PageFrameID(target),
+		byID:      map[proto.NetworkRequestID]*harEntryState{},
+	}
+}
+
+// enable mengaktifkan event Network dan Page (untuk pageTimings) pada session
+func (r *HARRecorder) enable() error {
+	if err := (proto.NetworkEnable{}).Call(r.page); err != nil {
+		return fmt.Errorf("failed to enable network events: %w", err)
+	}
+	if err := (proto.PageEnable{}).Call(r.page); err != nil {
+		return fmt.Errorf("failed to enable page events: %w", err)
+	}
+	return nil
+}
+
+// handle meneruskan satu event CDP ke handler yang sesuai
+func (r *HARRecorder) handle(msg *rod.Message) {
+	var (
+		sent     proto.NetworkRequestWillBeSent
+		received proto.NetworkResponseReceived
+		finished proto.NetworkLoadingFinished
+		failed   proto.NetworkLoadingFailed
+		content  proto.PageDomContentEventFired
+		load     proto.PageLoadEventFired
+	)
+	switch {
+	case msg.Load(&sent):
+		r.onRequestWillBeSent(&sent)
+	case msg.Load(&received):
+		r.onResponseReceived(&received)
+	case msg.Load(&finished):
+		r.onLoadingFinished(&finished)
+	case msg.Load(&failed):
+		r.onLoadingFailed(&failed)
+	case msg.Load(&content):
+		r.onDOMContentEventFired(&content)
+	case msg.Load(&load):
+		r.onLoadEventFired(&load)
+	}
+}
+
+// Stop berhenti merekam. HAR yang sudah direkam tetap bisa diambil.
+func (r *HARRecorder) Stop() {
+	r.stopOnce.Do(r.cancel)
+}
+
+// HAR mengembalikan salinan rekaman saat ini setelah menunggu body yang
+// sedang diambil. Request yang belum selesai ikut disertakan.
+func (r *HARRecorder) HAR() *HAR {
+	r.bodies.Wait()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h := &HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: harCreatorName, Version: "1.0"},
+		Pages:   make([]HARPage, 0, len(r.pages)),
+		Entries: make([]HAREntry, 0, len(r.entries)),
+	}}
+	for _, p := range r.pages {
+		h.Log.Pages = append(h.Log.Pages, p.page)
+	}
+	for _, e := range r.entries {
+		h.Log.Entries = append(h.Log.Entries, e.entry)
+	}
+	return h
+}
+
+// WriteFile menulis rekaman saat ini ke path
+func (r *HARRecorder) WriteFile(path string) error {
+	return r.HAR().WriteFile(path)
+}
+
+func (r *HARRecorder) onRequestWillBeSent(e *proto.NetworkRequestWillBeSent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Redirect memakai requestId yang sama: tutup entry sebelumnya dengan
+	// response redirect lalu buat entry baru
+	if prev, ok := r.byID[e.RequestID]; ok && e.RedirectResponse != nil {
+		r.applyResponse(prev, e.RedirectResponse)
+		r.finish(prev, e.Timestamp, 0)
+	}
+
+	if e.Type == proto.NetworkResourceTypeDocument && e.RedirectResponse == nil && r.isMainFrame(e.FrameID) {
+		r.pages = append(r.pages, &harPageState{
+			page: HARPage{
+				StartedDateTime: harTime(e.WallTime),
+				ID:              fmt.Sprintf("page_%d", len(r.pages)+1),
+				Title:           e.Request.URL,
+				PageTimings:     HARPageTimings{OnContentLoad: -1, OnLoad: -1},
+			},
+			startTime: e.Timestamp,
+		})
+	}
+
+	state := &harEntryState{
+		startTime: e.Timestamp,
+		entry: HAREntry{
+			StartedDateTime: harTime(e.WallTime),
+			Request:         r.harRequest(e.Request),
+			Response: HARResponse{
+				Cookies: []HARCookie{},
+				Headers: []HARNameValue{},
+				Content: HARContent{MimeType: "x-unknown"},
+				// Nilai -1 sampai response diterima
+				HeadersSize: -1,
+				BodySize:    -1,
+			},
+			Timings:      HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1},
+			ResourceType: string(e.Type),
+		},
+	}
+	if len(r.pages) > 0 {
+		state.entry.Pageref = r.pages[len(r.pages)-1].page.ID
+	}
+	r.entries = append(r.entries, state)
+	r.byID[e.RequestID] = state
+}
+
+func (r *HARRecorder) onResponseReceived(e *proto.NetworkResponseReceived) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if state, ok := r.byID[e.RequestID]; ok {
+		r.applyResponse(state, e.Response)
+	}
+}
+
+func (r *HARRecorder) onLoadingFinished(e *proto.NetworkLoadingFinished) {
+	r.mu.Lock()
+	state, ok := r.byID[e.RequestID]
+	if ok {
+		r.finish(state, e.Timestamp, int(e.EncodedDataLength))
+		delete(r.byID, e.RequestID)
+	}
+	r.mu.Unlock()
+
+	if ok && r.opts.IncludeBodies {
+		r.bodies.Add(1)
+		go r.loadBody(e.RequestID, state)
+	}
+}
+
+func (r *HARRecorder) onLoadingFailed(e *proto.NetworkLoadingFailed) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if state, ok := r.byID[e.RequestID]; ok {
+		r.finish(state, e.Timestamp, 0)
+		state.entry.Error = e.ErrorText
+		delete(r.byID, e.RequestID)
+	}
+}
+
+func (r *HARRecorder) onDOMContentEventFired(e *proto.PageDomContentEventFired) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p := r.currentPage(); p != nil {
+		p.page.PageTimings.OnContentLoad = msSince(p.startTime, e.Timestamp)
+	}
+}
+
+func (r *HARRecorder) onLoadEventFired(e *proto.PageLoadEventFired) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p := r.currentPage(); p != nil {
+		p.page.PageTimings.OnLoad = msSince(p.startTime, e.Timestamp)
+	}
+}
+
+func (r *HARRecorder) currentPage() *harPageState {
+	if len(r.pages) == 0 {
+		return nil
+	}
+	return r.pages[len(r.pages)-1]
+}
+
+func (r *HARRecorder) isMainFrame(frame proto.PageFrameID) bool {
+	if r.mainFrame != "" {
+		return frame == r.mainFrame
+	}
+	// Frame utama tidak diketahui: anggap dokumen pertama sebagai page
+	return len(r.pages) == 0
+}
+
+// loadBody mengambil body response dan memasukkannya ke entry jika tidak
+// melebihi MaxBodySize
+func (r *HARRecorder) loadBody(id proto.NetworkRequestID, state *harEntryState) {
+	defer r.bodies.Done()
+
+	limit := r.opts.maxBodySize()
+	r.mu.Lock()
+	size := state.entry.Response.BodySize
+	r.mu.Unlock()
+	if size > limit {
+		r.mu.Lock()
+		state.entry.Response.Content.Comment = fmt.Sprintf("body omitted: %d bytes exceeds limit of %d", size, limit)
+		r.mu.Unlock()
+		return
+	}
+
+	body, err := proto.NetworkGetResponseBody{RequestID: id}.Call(r.page)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	content := &state.entry.Response.Content
+	switch {
+	case err != nil:
+		content.Comment = "body unavailable: " + err.Error()
+	case len(body.Body) > limit:
+		content.Comment = fmt.Sprintf("body omitted: %d bytes exceeds limit of %d", len(body.Body), limit)
+	default:
+		content.Text = body.Body
+		content.Size = len(body.Body)
+		if body.Base64Encoded {
+			content.Encoding = "base64"
+			if decoded, err := base64.StdEncoding.DecodeString(body.Body); err == nil {
+				content.Size = len(decoded)
+			}
+		}
+	}
+}
+
+// applyResponse mengisi response, timing dan info koneksi entry
+func (r *HARRecorder) applyResponse(state *harEntryState, res *proto.NetworkResponse) {
+	e := &state.entry
+	e.Response.Status = res.Status
+	e.Response.StatusText = res.StatusText
+	e.Response.HTTPVersion = harHTTPVersion(res.Protocol)
+	e.Response.Headers = harHeaders(res.Headers)
+	e.Response.Cookies = harResponseCookies(res.Headers)
+	e.Response.RedirectURL = headerValue(res.Headers, "Location")
+	e.Response.Content.MimeType = res.MIMEType
+	if e.Response.Content.MimeType == "" {
+		e.Response.Content.MimeType = "x-unknown"
+	}
+	if res.HeadersText != "" {
+		e.Response.HeadersSize = len(res.HeadersText)
+	}
+	e.Request.HTTPVersion = e.Response.HTTPVersion
+	// Header yang benar-benar dikirim (termasuk cookie) jika Chrome menyertakannya
+	if len(res.RequestHeaders) > 0 {
+		e.Request.Headers = harHeaders(res.RequestHeaders)
+		e.Request.Cookies = harRequestCookies(res.RequestHeaders)
+	}
+	if res.RemoteIPAddress != "" {
+		e.ServerIPAddress = strings.Trim(res.RemoteIPAddress, "[]")
+	}
+	if res.ConnectionID > 0 {
+		e.Connection = fmt.Sprintf("%.0f", res.ConnectionID)
+	}
+	state.timing = res.Timing
+}
+
+// finish menghitung timings dan ukuran entry saat request selesai
+func (r *HARRecorder) finish(state *harEntryState, end proto.MonotonicTime, encodedLength int) {
+	e := &state.entry
+	total := msSince(state.startTime, end)
+	t := HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+
+	if timing := state.timing; timing != nil {
+		if timing.DNSStart >= 0 {
+			t.Blocked = timing.DNSStart
+		} else if timing.ConnectStart >= 0 {
+			t.Blocked = timing.ConnectStart
+		} else {
+			t.Blocked = timing.SendStart
+		}
+		if timing.DNSStart >= 0 {
+			t.DNS = timing.DNSEnd - timing.DNSStart
+		}
+		if timing.ConnectStart >= 0 {
+			t.Connect = timing.ConnectEnd - timing.ConnectStart
+		}
+		if timing.SslStart >= 0 {
+			t.SSL = timing.SslEnd - timing.SslStart
+		}
+		t.Send = timing.SendEnd - timing.SendStart
+		t.Wait = timing.ReceiveHeadersEnd - timing.SendEnd
+		// requestTime (detik) bisa lebih akhir dari timestamp requestWillBeSent
+		t.Receive = (float64(end)-timing.RequestTime)*1000 - timing.ReceiveHeadersEnd
+		if t.Receive < 0 {
+			t.Receive = 0
+		}
+		total = t.Send + t.Wait + t.Receive
+		for _, v := range []float64{t.Blocked, t.DNS, t.Connect} {
+			if v > 0 {
+				total += v
+			}
+		}
+	} else {
+		if total < 0 {
+			total = 0
+		}
+		t.Send = 0
+		t.Wait = total
+	}
+	e.Timings = t
+	e.Time = total
+
+	if encodedLength > 0 {
+		e.Response.BodySize = encodedLength
+		if e.Response.HeadersSize > 0 {
+			e.Response.BodySize -= e.Response.HeadersSize
+		}
+		e.Response.Content.Size = e.Response.BodySize
+	} else if e.Response.Status >= 300 && e.Response.Status < 400 {
+		e.Response.BodySize = 0
+	}
+}
+
+// harRequest mengubah request CDP menjadi HARRequest
+func (r *HARRecorder) harRequest(req *proto.NetworkRequest) HARRequest {
+	rawURL := req.URL + req.URLFragment
+	h := HARRequest{
+		Method:      req.Method,
+		URL:         rawURL,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     harRequestCookies(req.Headers),
+		Headers:     harHeaders(req.Headers),
+		QueryString: []HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    0,
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		h.QueryString = harQuery(u.RawQuery)
+	}
+	if req.HasPostData || req.PostData != "" {
+		mimeType := headerValue(req.Headers, "Content-Type")
+		post := &HARPostData{MimeType: mimeType, Params: []HARNameValue{}}
+		h.BodySize = len(req.PostData)
+		limit := r.opts.maxBodySize()
+		if len(req.PostData) > limit {
+			post.Comment = fmt.Sprintf("body omitted: %d bytes exceeds limit of %d", len(req.PostData), limit)
+		} else {
+			post.Text = req.PostData
+			if mediaType, _, _ := mime.ParseMediaType(mimeType); mediaType == "application/x-www-form-urlencoded" {
+				post.Params = harQuery(req.PostData)
+			}
+		}
+		h.PostData = post
+	}
+	return h
+}
+
+// startHARAutoRecord merekam setiap page target di browser dan menulis
+// HAR-nya ke opts.Dir saat page dilepas atau proses Chrome berhenti. Semua
+// event dibaca dari satu langganan sehingga event Network sebuah page selalu
+// diproses sebelum event detach-nya. Target baru di-attach otomatis dalam
+// keadaan tertahan dan baru dilanjutkan setelah domain Network aktif, sehingga
+// request pertama page ikut terekam.
+func startHARAutoRecord(browser *rod.Browser, process *chromeProcess, opts HAROptions, logger func(key, value string)) error {
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create har directory: %w", err)
+	}
+
+	var mu sync.Mutex
+	targets := map[proto.TargetSessionID]*harTarget{}
+	// recording mencegah satu target direkam dua kali, misalnya lewat session
+	// auto-attach dan session yang dibuat Rod untuk page yang sama
+	recording := map[proto.TargetTargetID]bool{}
+
+	write := func(t *harTarget) {
+		path := filepath.Join(opts.Dir, unsafeFileChars.ReplaceAllString(string(t.targetID), "_")+".har")
+		if err := t.recorder.WriteFile(path); err != nil {
+			logger("har_error", err.Error())
+			return
+		}
+		logger("har_written", path)
+	}
+
+	messages := browser.Event()
+	go func() {
+		for msg := range messages {
+			var attached proto.TargetAttachedToTarget
+			var detached proto.TargetDetachedFromTarget
+			switch {
+			case msg.Load(&attached):
+				session := browser.PageFromSession(attached.SessionID)
+				mu.Lock()
+				record := attached.TargetInfo != nil && attached.TargetInfo.Type == proto.TargetTargetInfoTypePage &&
+					!recording[attached.TargetInfo.TargetID]
+				var t *harTarget
+				if record {
+					t = &harTarget{
+						targetID: attached.TargetInfo.TargetID,
+						recorder: newHARRecorder(session, attached.TargetInfo.TargetID, opts),
+					}
+					targets[attached.SessionID] = t
+					recording[t.targetID] = true
+				}
+				mu.Unlock()
+				if !record && !attached.WaitingForDebugger {
+					continue
+				}
+				// Panggilan CDP tidak dilakukan di loop event. Target yang
+				// tertahan (termasuk yang tidak direkam) harus dilanjutkan.
+				go func() {
+					if t != nil {
+						if err := t.recorder.enable(); err != nil {
+							logger("har_error", err.Error())
+						}
+					}
+					if attached.WaitingForDebugger {
+						if err := (proto.RuntimeRunIfWaitingForDebugger{}).Call(session); err != nil {
+							logger("har_error", fmt.Sprintf("failed to resume target: %v", err))
+						}
+					}
+				}()
+			case msg.Load(&detached):
+				mu.Lock()
+				t, ok := targets[detached.SessionID]
+				delete(targets, detached.SessionID)
+				if ok {
+					delete(recording, t.targetID)
+				}
+				mu.Unlock()
+				if ok {
+					go write(t)
+				}
+			default:
+				mu.Lock()
+				t, ok := targets[msg.SessionID]
+				mu.Unlock()
+				if ok {
+					t.recorder.handle(msg)
+				}
+			}
+		}
+	}()
+
+	// Page yang sudah ada juga di-attach; target baru ditahan sampai recorder
+	// mengaktifkan domain Network
+	err := proto.TargetSetAutoAttach{AutoAttach: true, WaitForDebuggerOnStart: true, Flatten: true}.Call(browser)
+	if err != nil {
+		return fmt.Errorf("failed to auto-attach targets for har recording: %w", err)
+	}
+
+	go func() {
+		<-process.Exited()
+		mu.Lock()
+		remaining := targets
+		targets = map[proto.TargetSessionID]*harTarget{}
+		mu.Unlock()
+		for _, t := range remaining {
+			write(t)
+		}
+	}()
+	return nil
+}
+
+// harTarget adalah recorder untuk satu page target pada perekaman global
+type harTarget struct {
+	targetID proto.TargetTargetID
+	recorder *HARRecorder
+}
+
+// harHeaders mengubah header CDP menjadi daftar yang terurut berdasarkan nama
+func harHeaders(headers proto.NetworkHeaders) []HARNameValue {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make([]HARNameValue, 0, len(headers))
+	for _, name := range names {
+		// Header dengan beberapa nilai digabung CDP dengan newline
+		for _, v := range strings.Split(headers[name].Str(), "\n") {
+			out = append(out, HARNameValue{Name: name, Value: v})
+		}
+	}
+	return out
+}
+
+// harQuery mem-parse query string dengan urutan aslinya
+func harQuery(raw string) []HARNameValue {
+	out := []HARNameValue{}
+	for _, pair := range strings.Split(raw, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		if n, err := url.QueryUnescape(name); err == nil {
+			name = n
+		}
+		if v, err := url.QueryUnescape(value); err == nil {
+			value = v
+		}
+		out = append(out, HARNameValue{Name: name, Value: value})
+	}
+	return out
+}
+
+func headerValue(headers proto.NetworkHeaders, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v.Str()
+		}
+	}
+	return ""
+}
+
+func harRequestCookies(headers proto.NetworkHeaders) []HARCookie {
+	req := http.Request{Header: http.Header{}}
+	if cookie := headerValue(headers, "Cookie"); cookie != "" {
+		req.Header.Set("Cookie", cookie)
+	}
+	cookies := []HARCookie{}
+	for _, c := range req.Cookies() {
+		cookies = append(cookies, HARCookie{Name: c.Name, Value: c.Value})
+	}
+	return cookies
+}
+
+func harResponseCookies(headers proto.NetworkHeaders) []HARCookie {
+	res := http.Response{Header: http.Header{}}
+	for _, line := range strings.Split(headerValue(headers, "Set-Cookie"), "\n") {
+		if line != "" {
+			res.Header.Add("Set-Cookie", line)
+		}
+	}
+	cookies := []HARCookie{}
+	for _, c := range res.Cookies() {
+		cookie := HARCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			HTTPOnly: c.HttpOnly,
+			Secure:   c.Secure,
+		}
+		if !c.Expires.IsZero() {
+			cookie.Expires = c.Expires.UTC().Format(time.RFC3339)
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies
+}
+
+// harHTTPVersion mengubah protocol CDP ("http/1.1", "h2", "h3") ke format HAR
+func harHTTPVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "", "http/1.1":
+		return "HTTP/1.1"
+	case "http/1.0":
+		return "HTTP/1.0"
+	case "h2":
+		return "HTTP/2.0"
+	case "h3", "h3-29":
+		return "HTTP/3.0"
+	}
+	return strings.ToUpper(protocol)
+}
+
+func harTime(t proto.TimeSinceEpoch) string {
+	if t <= 0 {
+		return time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	return t.Time().UTC().Format(time.RFC3339Nano)
+}
+
+// msSince mengembalikan selisih dua timestamp monotonic CDP dalam milidetik
+func msSince(start, end proto.MonotonicTime) float64 {
+	if start <= 0 || end < start {
+		return -1
+	}
+	return (float64(end) - float64(start)) * 1000
+}
@@ -0,0 +1,282 @@
+package browser_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// emitExchange mengirim event Network satu request lengkap ke sessionID
+func emitExchange(cdp *fakeCDP, sessionID, frameID, requestID, url, resourceType string, status int, start float64) {
+	cdp.Emit("Network.requestWillBeSent", sessionID, map[string]any{
+		"requestId":   requestID,
+		"loaderId":    "loader",
+		"documentURL": url,
+		"frameId":     frameID,
+		"type":        resourceType,
+		"timestamp":   start,
+		"wallTime":    1700000000.5,
+		"initiator":   map[string]any{"type": "other"},
+		"request": map[string]any{
+			"url":             url,
+			"method":          "GET",
+			"headers":         map[string]string{"Accept": "*/*", "Cookie": "session=abc"},
+			"initialPriority": "High",
+			"referrerPolicy":  "no-referrer",
+		},
+	})
+	cdp.Emit("Network.responseReceived", sessionID, map[string]any{
+		"requestId": requestID,
+		"loaderId":  "loader",
+		"timestamp": start + 0.05,
+		"type":      resourceType,
+		"response": map[string]any{
+			"url":               url,
+			"status":            status,
+			"statusText":        "OK",
+			"headers":           map[string]string{"Content-Type": "text/html", "Set-Cookie": "id=1; Path=/; HttpOnly"},
+			"mimeType":          "text/html",
+			"connectionReused":  false,
+			"connectionId":      7,
+			"remoteIPAddress":   "93.184.216.34",
+			"encodedDataLength": 100,
+			"protocol":          "h2",
+			"securityState":     "secure",
+			"timing": map[string]any{
+				"requestTime": start, "proxyStart": -1, "proxyEnd": -1,
+				"dnsStart": 1, "dnsEnd": 5, "connectStart": 5, "connectEnd": 20,
+				"sslStart": 10, "sslEnd": 20, "workerStart": -1, "workerReady": -1,
+				"workerFetchStart": -1, "workerRespondWithSettled": -1,
+				"sendStart": 21, "sendEnd": 22, "pushStart": 0, "pushEnd": 0,
+				"receiveHeadersStart": 40, "receiveHeadersEnd": 50,
+			},
+		},
+	})
+	cdp.Emit("Network.loadingFinished", sessionID, map[string]any{
+		"requestId":         requestID,
+		"timestamp":         start + 0.1,
+		"encodedDataLength": 300,
+	})
+}
+
+func TestRecordHAR(t *testing.T) {
+	cdp := newFakeCDP(t)
+	cdp.Handle("Network.getResponseBody", func(params json.RawMessage) (any, error) {
+		var req struct {
+			RequestID string `json:"requestId"`
+		}
+		json.Unmarshal(params, &req)
+		if req.RequestID == "big" {
+			return map[string]any{"body": strings.Repeat("x", 1000), "base64Encoded": false}, nil
+		}
+		return map[string]any{"body": "<html>ok</html>", "base64Encoded": false}, nil
+	})
+	mgr := installFakeChrome(t, cdp.Script())
+	b, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{Sandbox: browser.SandboxOff})
+	if err != nil {
+		t.Fatalf("GetBrowserWithOptions failed: %v", err)
+	}
+	defer b.Close()
+	page, err := b.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		t.Fatalf("Page failed: %v", err)
+	}
+
+	rec, err := mgr.RecordHAR(page, browser.HAROptions{IncludeBodies: true, MaxBodySize: 500})
+	if err != nil {
+		t.Fatalf("RecordHAR failed: %v", err)
+	}
+	defer rec.Stop()
+
+	session, frame := string(page.SessionID), string(page.TargetID)
+	emitExchange(cdp, session, frame, "doc", "https://example.com/?q=go&lang=id", "Document", 200, 100)
+	emitExchange(cdp, session, frame, "big", "https://example.com/big.js", "Script", 200, 100.2)
+	cdp.Emit("Network.requestWillBeSent", session, map[string]any{
+		"requestId": "fail", "loaderId": "loader", "documentURL": "https://example.com/", "type": "XHR",
+		"timestamp": 100.3, "wallTime": 1700000001.0, "initiator": map[string]any{"type": "script"},
+		"request": map[string]any{
+			"url": "https://example.com/api", "method": "POST",
+			"headers":  map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			"postData": "a=1&b=two", "hasPostData": true,
+			"initialPriority": "High", "referrerPolicy": "no-referrer",
+		},
+	})
+	cdp.Emit("Network.loadingFailed", session, map[string]any{
+		"requestId": "fail", "timestamp": 100.4, "type": "XHR", "errorText": "net::ERR_CONNECTION_REFUSED",
+	})
+
+	var har *browser.HAR
+	waitFor(t, "three finished entries", func() bool {
+		har = rec.HAR()
+		if len(har.Log.Entries) != 3 {
+			return false
+		}
+		return har.Log.Entries[2].Error != ""
+	})
+
+	if har.Log.Version != "1.2" || len(har.Log.Pages) != 1 {
+		t.Errorf("unexpected log header: version=%s pages=%d", har.Log.Version, len(har.Log.Pages))
+	}
+
+	doc := har.Log.Entries[0]
+	if doc.Pageref != har.Log.Pages[0].ID || doc.Response.Status != 200 || doc.Response.HTTPVersion != "HTTP/2.0" {
+		t.Errorf("unexpected document entry: %+v", doc)
+	}
+	if doc.Response.Content.Text != "<html>ok</html>" {
+		t.Errorf("document body = %q (%s)", doc.Response.Content.Text, doc.Response.Content.Comment)
+	}
+	if doc.Timings.DNS != 4 || doc.Timings.Connect != 15 || doc.Timings.SSL != 10 || doc.Timings.Wait != 28 {
+		t.Errorf("unexpected timings: %+v", doc.Timings)
+	}
+	if len(doc.Request.QueryString) != 2 || doc.Request.QueryString[0].Name != "q" {
+		t.Errorf("unexpected query string: %+v", doc.Request.QueryString)
+	}
+	if len(doc.Request.Cookies) != 1 || len(doc.Response.Cookies) != 1 || !doc.Response.Cookies[0].HTTPOnly {
+		t.Errorf("unexpected cookies: request=%+v response=%+v", doc.Request.Cookies, doc.Response.Cookies)
+	}
+
+	big := har.Log.Entries[1]
+	if big.Response.Content.Text != "" || !strings.Contains(big.Response.Content.Comment, "exceeds limit") {
+		t.Errorf("large body should be omitted: %+v", big.Response.Content)
+	}
+
+	failed := har.Log.Entries[2]
+	if failed.Error != "net::ERR_CONNECTION_REFUSED" || failed.Request.PostData == nil ||
+		len(failed.Request.PostData.Params) != 2 || failed.Request.PostData.Text != "a=1&b=two" {
+		t.Errorf("unexpected failed entry: %+v", failed)
+	}
+
+	path := filepath.Join(t.TempDir(), "page.har")
+	if err := rec.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	read, err := browser.ReadHAR(path)
+	if err != nil || len(read.Log.Entries) != 3 {
+		t.Fatalf("ReadHAR = %v, %v", read, err)
+	}
+}
+
+// Test perekaman berhenti sendiri saat session page dilepas (page ditutup)
+func TestRecordHARStopsWhenPageCloses(t *testing.T) {
+	cdp := newFakeCDP(t)
+	logs := &logRecorder{}
+	mgr := installFakeChromeWithLogger(t, cdp.Script(), logs.Log)
+	b, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{Sandbox: browser.SandboxOff})
+	if err != nil {
+		t.Fatalf("GetBrowserWithOptions failed: %v", err)
+	}
+	defer b.Close()
+	page, err := b.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		t.Fatalf("Page failed: %v", err)
+	}
+
+	rec, err := mgr.RecordHAR(page, browser.HAROptions{})
+	if err != nil {
+		t.Fatalf("RecordHAR failed: %v", err)
+	}
+
+	session, frame := string(page.SessionID), string(page.TargetID)
+	emitExchange(cdp, session, frame, "before", "https://example.com/", "Document", 200, 100)
+	cdp.Emit("Target.detachedFromTarget", "", map[string]any{"sessionId": session, "targetId": frame})
+	waitFor(t, "recording to stop", func() bool { return logs.Has("har_stopped", frame) })
+	emitExchange(cdp, session, frame, "after", "https://example.com/late.js", "Script", 200, 101)
+
+	har := rec.HAR()
+	if len(har.Log.Entries) != 1 || har.Log.Entries[0].Request.URL != "https://example.com/" {
+		t.Errorf("expected only the request before close, got %+v", har.Log.Entries)
+	}
+}
+
+func TestLaunchOptionsHARRecordsEveryPage(t *testing.T) {
+	dir := t.TempDir()
+	cdp := newFakeCDP(t)
+	mgr := installFakeChrome(t, cdp.Script())
+	b, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{
+		Sandbox: browser.SandboxOff,
+		HAR:     &browser.HAROptions{Dir: dir},
+	})
+	if err != nil {
+		t.Fatalf("GetBrowserWithOptions failed: %v", err)
+	}
+	defer b.Close()
+
+	cdp.Emit("Target.attachedToTarget", "", map[string]any{
+		"sessionId":          "har-session",
+		"waitingForDebugger": false,
+		"targetInfo": map[string]any{
+			"targetId": "TARGET1", "type": "page", "title": "", "url": "about:blank",
+			"attached": true, "canAccessOpener": false,
+		},
+	})
+	waitFor(t, "network events to be enabled", func() bool { return cdp.Calls("Network.enable") > 0 })
+	if cdp.Calls("Target.setAutoAttach") == 0 {
+		t.Error("HAR recording should auto-attach to new targets")
+	}
+
+	emitExchange(cdp, "har-session", "TARGET1", "r1", "https://example.com/", "Document", 200, 50)
+	cdp.Emit("Target.detachedFromTarget", "", map[string]any{"sessionId": "har-session", "targetId": "TARGET1"})
+
+	path := filepath.Join(dir, "TARGET1.har")
+	waitFor(t, "har file to be written", func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	})
+	har, err := browser.ReadHAR(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(har.Log.Entries) != 1 || har.Log.Entries[0].Request.URL != "https://example.com/" {
+		t.Errorf("unexpected entries: %+v", har.Log.Entries)
+	}
+
+	if _, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{HAR: &browser.HAROptions{}}); err == nil {
+		t.Error("HAR without directory should be rejected")
+	}
+}
+
+// Test target yang tertahan saat attach baru dilanjutkan setelah domain
+// Network aktif, sehingga request pertama page tidak terlewat
+func TestLaunchOptionsHAREnablesNetworkBeforeResume(t *testing.T) {
+	cdp := newFakeCDP(t)
+	resumed := make(chan int, 1)
+	cdp.Handle("Runtime.runIfWaitingForDebugger", func(json.RawMessage) (any, error) {
+		resumed <- cdp.Calls("Network.enable")
+		return map[string]any{}, nil
+	})
+	mgr := installFakeChrome(t, cdp.Script())
+	b, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{
+		Sandbox: browser.SandboxOff,
+		HAR:     &browser.HAROptions{Dir: t.TempDir()},
+	})
+	if err != nil {
+		t.Fatalf("GetBrowserWithOptions failed: %v", err)
+	}
+	defer b.Close()
+
+	enabled := cdp.Calls("Network.enable")
+	cdp.Emit("Target.attachedToTarget", "", map[string]any{
+		"sessionId":          "paused-session",
+		"waitingForDebugger": true,
+		"targetInfo": map[string]any{
+			"targetId": "TARGET2", "type": "page", "title": "", "url": "about:blank",
+			"attached": true, "canAccessOpener": false,
+		},
+	})
+	select {
+	case calls := <-resumed:
+		if calls <= enabled {
+			t.Error("target resumed before the Network domain was enabled")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("paused target was never resumed")
+	}
+}
@@ -0,0 +1,122 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// stagingPattern mengembalikan pola nama direktori staging (sibling installDir)
+func (cm *ChromiumManager) stagingPattern() string {
+	return filepath.Base(cm.installDir) + ".staging-*"
+}
+
+// cleanupStaging menghapus sisa direktori staging/old dari instalasi yang terputus
+// (untuk semua distribusi dan versi di root directory, dipanggil saat memegang lock)
+func (cm *ChromiumManager) cleanupStaging() {
+	for _, pattern := range []string{"*/*.staging-*", "*/*.old-*", "*.staging-*", "*.old-*"} {
+		matches, _ := filepath.Glob(filepath.Join(cm.rootDir, pattern))
+		for _, dir := range matches {
+			if err := os.RemoveAll(dir); err != nil {
+				cm.logger("chrome_staging_cleanup_error", fmt.Sprintf("%s: %v", dir, err))
+				continue
+			}
+			cm.logger("chrome_staging_cleanup", dir)
+		}
+	}
+}
+
+// installFromArchive mengekstrak arsip ke direktori staging, memverifikasi hasilnya,
+// lalu memindahkannya ke installDir dengan rename. Instalasi lama tidak disentuh
+// sampai staging lengkap, sehingga proses yang terputus tidak meninggalkan
+// instalasi setengah jadi.
+func (cm *ChromiumManager) installFromArchive(ctx context.Context, archivePath string) error {
+	staging, err := os.MkdirTemp(filepath.Dir(cm.installDir), cm.stagingPattern())
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	cm.logger("chrome_staging_dir", staging)
+
+	installed := false
+	defer func() {
+		if !installed {
+			os.RemoveAll(staging)
+		}
+	}()
+
+	var result extractResult
+	switch cm.release.Format {
+	case FormatZip:
+		// Chrome for Testing dan Chromium snapshot menggunakan ZIP
+		result, err = cm.extractZip(ctx, archivePath, staging)
+	case FormatTarXz:
+		// Ungoogled Chromium menggunakan TAR.XZ
+		archive, openErr := os.Open(archivePath)
+		if openErr != nil {
+			return fmt.Errorf("failed to open download: %w", openErr)
+		}
+		result, err = cm.extractTarXz(ctx, archive, staging)
+		archive.Close()
+	default:
+		return fmt.Errorf("unsupported file format: %s", cm.release.DownloadURL)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Verifikasi hasil ekstraksi: jumlah entry arsip dibandingkan dengan yang
+	// ditulis dan dengan isi staging di disk
+	onDisk, err := countEntries(staging)
+	if err != nil {
+		return fmt.Errorf("failed to verify extraction: %w", err)
+	}
+	cm.logger("chrome_extract_files", fmt.Sprintf("%d/%d on_disk=%d", result.written, result.expected, onDisk))
+	if result.written != result.expected || onDisk != result.expected {
+		return fmt.Errorf("incomplete extraction: wrote %d and found %d of %d files", result.written, onDisk, result.expected)
+	}
+	if cm.findExecutable(staging) == "" {
+		return fmt.Errorf("chrome executable not found in archive")
+	}
+
+	// .version ditulis paling akhir, menandai staging sudah lengkap
+	if err := cm.saveVersion(staging); err != nil {
+		return fmt.Errorf("failed to write version file: %w", err)
+	}
+
+	if err := cm.swapInstall(staging); err != nil {
+		return err
+	}
+	installed = true
+
+	cm.logger("chrome_install", "committed")
+	cm.isInstalled()
+	return nil
+}
+
+// swapInstall mengganti installDir dengan staging
+func (cm *ChromiumManager) swapInstall(staging string) error {
+	if _, err := os.Stat(cm.installDir); os.IsNotExist(err) {
+		if err := os.Rename(staging, cm.installDir); err != nil {
+			return fmt.Errorf("failed to move staging into place: %w", err)
+		}
+		return nil
+	}
+
+	old := fmt.Sprintf("%s.old-%d", cm.installDir, os.Getpid())
+	if err := os.Rename(cm.installDir, old); err != nil {
+		return fmt.Errorf("failed to move previous installation aside: %w", err)
+	}
+
+	if err := os.Rename(staging, cm.installDir); err != nil {
+		// Kembalikan instalasi lama
+		os.Rename(old, cm.installDir)
+		return fmt.Errorf("failed to move staging into place: %w", err)
+	}
+
+	if err := os.RemoveAll(old); err != nil {
+		cm.logger("chrome_old_install_cleanup_error", err.Error())
+	}
+
+	return nil
+}
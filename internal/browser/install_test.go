@@ -0,0 +1,90 @@
+package browser_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+func TestSetupInstallsAtomicallyAndCleansStaging(t *testing.T) {
+	archive := buildChromeZip(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer srv.Close()
+
+	mgr, rootDir := newTestManager(t, browser.Config{
+		DownloadURL:    srv.URL + "/chrome-linux64.zip",
+		InstallDirName: "chrome-test",
+		Version:        "1.0.0.0",
+	})
+	installDir := filepath.Join(rootDir, "custom_url", "1.0.0.0")
+
+	// Sisa staging dari proses yang crash sebelumnya
+	leftover := filepath.Join(rootDir, "custom_url", "0.9.0.0.staging-123")
+	if err := os.MkdirAll(filepath.Join(leftover, "chrome-linux64"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mgr.Setup(); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Error("leftover staging directory should be removed")
+	}
+	if mgr.GetExecutablePath() != filepath.Join(installDir, "chrome-linux64", "chrome") {
+		t.Errorf("unexpected executable path %q", mgr.GetExecutablePath())
+	}
+	for _, pattern := range []string{".staging-*", ".download*", ".old-*"} {
+		matches, _ := filepath.Glob(installDir + pattern)
+		if len(matches) != 0 {
+			t.Errorf("no sibling staging/download files should remain, got %v", matches)
+		}
+	}
+}
+
+func TestSetupKeepsPreviousInstallOnBadArchive(t *testing.T) {
+	// Arsip tanpa executable chrome
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("chrome-linux64/README")
+	w.Write([]byte("no binary"))
+	zw.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	mgr, rootDir := newTestManager(t, browser.Config{
+		DownloadURL:    srv.URL + "/chrome-linux64.zip",
+		InstallDirName: "chrome-test",
+		Version:        "2.0.0.0",
+	})
+	installDir := filepath.Join(rootDir, "custom_url", "2.0.0.0")
+
+	// Instalasi sebelumnya yang terputus (tanpa .version)
+	oldChrome := filepath.Join(installDir, "chrome-linux64", "chrome")
+	os.MkdirAll(filepath.Dir(oldChrome), 0o755)
+	os.WriteFile(oldChrome, []byte("old"), 0o755)
+
+	if err := mgr.Setup(); err == nil {
+		t.Fatal("expected error for archive without chrome executable")
+	}
+
+	data, err := os.ReadFile(oldChrome)
+	if err != nil || string(data) != "old" {
+		t.Errorf("previous installation should be untouched, chrome=%q (%v)", data, err)
+	}
+	matches, _ := filepath.Glob(filepath.Join(rootDir, "*", "*.staging-*"))
+	if len(matches) != 0 {
+		t.Errorf("staging directory should be removed after failure, got %v", matches)
+	}
+}
@@ -0,0 +1,58 @@
+package browser
+
+import (
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// requestHandler memproses satu request yang di-intercept lewat Fetch domain.
+// Kembalikan true jika request sudah diselesaikan (diblokir atau dijawab),
+// false untuk meneruskan ke handler berikutnya.
+type requestHandler func(h *rod.Hijack) bool
+
+// startInterception memasang satu HijackRouter untuk seluruh browser dan
+// menjalankan handlers berurutan. Request yang tidak diselesaikan handler
+// mana pun diteruskan ke jaringan tanpa diubah. Hanya boleh ada satu router
+// per browser karena Fetch.enable berlaku untuk seluruh browser.
+func startInterception(browser *rod.Browser, handlers []requestHandler) (*rod.HijackRouter, error) {
+	// Rod mencatat Fetch.enable di state browser (dihapus oleh Fetch.disable
+	// saat router di-Stop), termasuk untuk clone lewat Context/Timeout
+	if browser.LoadState("", &proto.FetchEnable{}) {
+		return nil, fmt.Errorf("request interception is already active on this browser")
+	}
+	router := browser.HijackRequests()
+	err := router.Add("*", "", func(h *rod.Hijack) {
+		for _, handle := range handlers {
+			if handle(h) {
+				return
+			}
+		}
+		h.ContinueRequest(&proto.FetchContinueRequest{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	go router.Run()
+	return router, nil
+}
+
+// requestHandlers mengumpulkan handler interception dari opsi launch
+func (o LaunchOptions) requestHandlers(logger func(key, value string)) []requestHandler {
+	var handlers []requestHandler
+	if o.Policy != nil {
+		handlers = append(handlers, o.Policy.handler(logger))
+	}
+	if o.Blocker != nil {
+		handlers = append(handlers, o.Blocker.handler())
+	}
+	if o.Replay != nil {
+		handlers = append(handlers, o.Replay.handler(logger))
+	}
+	// Guard terakhir: request yang dijawab handler lain tidak menyentuh jaringan
+	if o.NetworkGuard != nil {
+		handlers = append(handlers, o.NetworkGuard.handler(logger))
+	}
+	return handlers
+}
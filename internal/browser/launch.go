@@ -0,0 +1,121 @@
+package browser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxStderrBytes membatasi output Chrome yang disimpan untuk diagnosa
+const maxStderrBytes = 16 * 1024
+
+// LaunchError dikembalikan jika proses Chrome gagal dijalankan atau tidak
+// pernah menampilkan URL DevTools. Stderr berisi output terakhir Chrome.
+type LaunchError struct {
+	Executable string
+	Stderr     string
+	Err        error
+}
+
+func (e *LaunchError) Error() string {
+	return fmt.Sprintf("failed to launch chrome %s: %v%s", e.Executable, e.Err, formatStderr(e.Stderr))
+}
+
+func (e *LaunchError) Unwrap() error {
+	return e.Err
+}
+
+// ConnectError dikembalikan jika Chrome sudah berjalan tetapi koneksi CDP
+// ke URL DevTools gagal.
+type ConnectError struct {
+	URL    string
+	Stderr string
+	Err    error
+}
+
+func (e *ConnectError) Error() string {
+	return fmt.Sprintf("failed to connect to chrome at %s: %v%s", e.URL, e.Err, formatStderr(e.Stderr))
+}
+
+func (e *ConnectError) Unwrap() error {
+	return e.Err
+}
+
+// MissingLibrariesError dikembalikan jika Chrome gagal start karena shared
+// library tidak ditemukan oleh dynamic linker. Err berisi *LaunchError asli
+// sehingga errors.As tetap bisa menemukan keduanya.
+type MissingLibrariesError struct {
+	Libraries []string
+	Stderr    string
+	Err       error
+}
+
+func (e *MissingLibrariesError) Error() string {
+	return fmt.Sprintf("chrome is missing shared libraries: %s%s", strings.Join(e.Libraries, ", "), formatStderr(e.Stderr))
+}
+
+func (e *MissingLibrariesError) Unwrap() error {
+	return e.Err
+}
+
+// missingLibraryPattern mencocokkan pesan ld.so, contoh:
+// "error while loading shared libraries: libnss3.so: cannot open shared object file"
+var missingLibraryPattern = regexp.MustCompile(`error while loading shared libraries: ([^:\s]+): cannot open shared object file`)
+
+// missingLibraries mengambil nama library yang hilang dari output Chrome
+func missingLibraries(stderr string) []string {
+	seen := map[string]bool{}
+	var libs []string
+	for _, match := range missingLibraryPattern.FindAllStringSubmatch(stderr, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			libs = append(libs, match[1])
+		}
+	}
+	sort.Strings(libs)
+	return libs
+}
+
+// launchFailure membungkus error launch menjadi MissingLibrariesError jika
+// penyebabnya library yang hilang, atau LaunchError untuk penyebab lain
+func launchFailure(execPath, stderr string, err error) error {
+	launchErr := &LaunchError{Executable: execPath, Stderr: stderr, Err: err}
+	if libs := missingLibraries(stderr); len(libs) > 0 {
+		return &MissingLibrariesError{Libraries: libs, Stderr: stderr, Err: launchErr}
+	}
+	return launchErr
+}
+
+// formatStderr menambahkan output Chrome ke pesan error jika ada
+func formatStderr(stderr string) string {
+	stderr = strings.TrimSpace(stderr)
+	if stderr == "" {
+		return ""
+	}
+	return "\nchrome stderr:\n" + stderr
+}
+
+// stderrBuffer menyimpan output terakhir proses Chrome (maksimal maxStderrBytes).
+// Aman dipakai bersamaan karena launcher menulis dari goroutine lain.
+type stderrBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *stderrBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > maxStderrBytes {
+		b.buf = append([]byte(nil), b.buf[len(b.buf)-maxStderrBytes:]...)
+	}
+	return len(p), nil
+}
+
+func (b *stderrBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
@@ -0,0 +1,132 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// errLockBusy dikembalikan tryLock jika lock sedang dipegang proses lain
+var errLockBusy = errors.New("lock busy")
+
+// lockPollInterval adalah jeda antar percobaan mengambil lock
+const lockPollInterval = 200 * time.Millisecond
+
+// installLock adalah advisory file lock (flock) untuk instalasi Chrome,
+// dipakai agar beberapa proses tidak mendownload ke direktori yang sama bersamaan
+type installLock struct {
+	file *os.File
+	path string
+}
+
+// acquireInstallLock mengambil lock di path, menunggu sampai lock dilepas
+// proses lain atau ctx selesai
+func acquireInstallLock(ctx context.Context, path string, logger func(key, value string)) (*installLock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	waiting := false
+	start := time.Now()
+	for {
+		err := tryLock(f)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLockBusy) {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		holder := readLockHolder(path)
+		if !waiting {
+			logger("chrome_lock_waiting", fmt.Sprintf("held by %s", holder))
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, fmt.Errorf("timed out waiting for install lock held by %s: %w", holder, ctx.Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	if waiting {
+		logger("chrome_lock_waited", time.Since(start).Round(time.Millisecond).String())
+	}
+
+	// Catat siapa pemegang lock untuk proses lain yang menunggu
+	hostname, _ := os.Hostname()
+	info := fmt.Sprintf("pid=%d host=%s since=%s", os.Getpid(), hostname, time.Now().Format(time.RFC3339))
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(info), 0)
+	}
+	logger("chrome_lock_acquired", info)
+
+	return &installLock{file: f, path: path}, nil
+}
+
+// release melepas lock
+func (l *installLock) release() {
+	l.file.Truncate(0)
+	unlock(l.file)
+	l.file.Close()
+}
+
+// readLockHolder membaca info pemegang lock dari file lock
+func readLockHolder(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil || len(strings.TrimSpace(string(data))) == 0 {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// inUseFile ada di direktori instalasi setiap versi. Selama Chrome dari
+// versi itu berjalan, file ini di-flock shared agar PruneVersions di proses
+// lain tidak menghapusnya.
+const inUseFile = ".in-use"
+
+// lockInUse menandai versi di dir sedang dipakai sampai file yang
+// dikembalikan ditutup
+func lockInUse(dir string) (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(dir, inUseFile), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open in-use lock: %w", err)
+	}
+	if err := tryLockShared(f); err != nil {
+		f.Close()
+		if errors.Is(err, errLockBusy) {
+			return nil, fmt.Errorf("chrome in %s is being removed", dir)
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", f.Name(), err)
+	}
+	return f, nil
+}
+
+// lockUnused mengambil exclusive lock in-use dir jika tidak ada Chrome dari
+// versi itu yang berjalan. inUse true berarti versi sedang dipakai; jika
+// tidak, file yang dikembalikan (boleh nil) ditutup setelah dir dihapus.
+func lockUnused(dir string) (f *os.File, inUse bool, err error) {
+	f, err = os.OpenFile(filepath.Join(dir, inUseFile), os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open in-use lock: %w", err)
+	}
+	if err := tryLock(f); err != nil {
+		f.Close()
+		if errors.Is(err, errLockBusy) {
+			return nil, true, nil
+		}
+		return nil, false, fmt.Errorf("failed to lock %s: %w", f.Name(), err)
+	}
+	return f, false, nil
+}
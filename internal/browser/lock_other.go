@@ -0,0 +1,20 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package browser
+
+import "os"
+
+// tryLock tidak melakukan apa-apa di platform tanpa flock
+func tryLock(f *os.File) error {
+	return nil
+}
+
+// tryLockShared tidak melakukan apa-apa di platform tanpa flock
+func tryLockShared(f *os.File) error {
+	return nil
+}
+
+// unlock tidak melakukan apa-apa di platform tanpa flock
+func unlock(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,95 @@
+package browser_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// Beberapa manager yang Setup bersamaan hanya boleh mendownload sekali,
+// yang lain menunggu lock lalu memakai instalasi yang sudah ada.
+func TestConcurrentSetupDownloadsOnce(t *testing.T) {
+	archive := buildChromeZip(t)
+	var downloads int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&downloads, 1)
+		time.Sleep(300 * time.Millisecond)
+		w.Write(archive)
+	}))
+	defer srv.Close()
+
+	config := browser.Config{
+		DownloadURL:    srv.URL + "/chrome-linux64.zip",
+		InstallDirName: "chrome-test",
+		Version:        "1.0.0.0",
+	}
+	first, _ := newTestManager(t, config)
+
+	managers := []*browser.ChromiumManager{first}
+	for i := 0; i < 3; i++ {
+		managers = append(managers, browser.NewChromiumManagerWithConfig(config, nil))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(managers))
+	for _, mgr := range managers {
+		wg.Add(1)
+		go func(mgr *browser.ChromiumManager) {
+			defer wg.Done()
+			errs <- mgr.Setup()
+		}(mgr)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Setup failed: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&downloads); got != 1 {
+		t.Errorf("expected exactly one download, got %d", got)
+	}
+	for _, mgr := range managers {
+		if mgr.GetExecutablePath() == "" {
+			t.Error("every manager should resolve the shared executable")
+		}
+	}
+}
+
+func TestSetupLockTimeout(t *testing.T) {
+	archive := buildChromeZip(t)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write(archive)
+	}))
+	defer srv.Close()
+
+	config := browser.Config{
+		DownloadURL:    srv.URL + "/chrome-linux64.zip",
+		InstallDirName: "chrome-test",
+		Version:        "1.0.0.0",
+		LockTimeout:    200 * time.Millisecond,
+	}
+	holder, _ := newTestManager(t, config)
+	done := make(chan error, 1)
+	go func() { done <- holder.Setup() }()
+	time.Sleep(100 * time.Millisecond)
+
+	waiter := browser.NewChromiumManagerWithConfig(config, nil)
+	err := waiter.Setup()
+
+	close(release)
+	if holderErr := <-done; holderErr != nil {
+		t.Errorf("holder Setup failed: %v", holderErr)
+	}
+	if err == nil {
+		t.Fatal("expected lock timeout error")
+	}
+}
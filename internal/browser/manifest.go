@@ -0,0 +1,406 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InstalledVersion adalah satu versi Chrome yang terinstall di root directory
+type InstalledVersion struct {
+	Version      string    `json:"version"`
+	Distribution string    `json:"distribution"`
+	Path         string    `json:"path"`
+	Executable   string    `json:"executable,omitempty"`
+	InstalledAt  time.Time `json:"installed_at"`
+	LastUsed     time.Time `json:"last_used"`
+	Pinned       bool      `json:"pinned"`
+	SizeBytes    int64     `json:"size_bytes"`
+}
+
+// PruneOptions menentukan versi mana yang dihapus oleh PruneVersions.
+// Versi yang di-pin tidak pernah dihapus. Nilai nol berarti aturan tidak dipakai.
+type PruneOptions struct {
+	// Hapus versi yang tidak dipakai lebih lama dari MaxAge
+	MaxAge time.Duration
+
+	// Simpan hanya KeepLatest versi yang paling baru dipakai
+	KeepLatest int
+
+	// Hapus versi paling lama dipakai sampai total ukuran <= MaxTotalSize (bytes)
+	MaxTotalSize int64
+
+	// Hanya laporkan versi yang akan dihapus
+	DryRun bool
+}
+
+// manifest menyimpan daftar versi terinstall di <root>/manifest.json. Key
+// Versions adalah installKey ("<distribution>/<version>"), sama dengan path
+// direktori instalasi relatif terhadap root.
+type manifest struct {
+	Versions map[string]*InstalledVersion `json:"versions"`
+}
+
+func (cm *ChromiumManager) manifestPath() string {
+	return filepath.Join(cm.rootDir, "manifest.json")
+}
+
+// loadManifest membaca manifest (kosong jika belum ada)
+func (cm *ChromiumManager) loadManifest() (*manifest, error) {
+	m := &manifest{Versions: make(map[string]*InstalledVersion)}
+	data, err := os.ReadFile(cm.manifestPath())
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if m.Versions == nil {
+		m.Versions = make(map[string]*InstalledVersion)
+	}
+	return m, nil
+}
+
+// saveManifest menulis manifest secara atomik
+func (cm *ChromiumManager) saveManifest(m *manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := cm.manifestPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, cm.manifestPath())
+}
+
+// recordInstall mencatat versi saat ini di manifest dan memperbarui last-used.
+// Dipanggil dari Setup saat memegang lock.
+func (cm *ChromiumManager) recordInstall(installed bool) {
+	m, err := cm.loadManifest()
+	if err != nil {
+		cm.logger("chrome_manifest_error", err.Error())
+		return
+	}
+
+	now := time.Now()
+	key := installKey(cm.distributionName(), cm.release.Version)
+	entry, ok := m.Versions[key]
+	if !ok || installed {
+		pinned := ok && entry.Pinned
+		entry = &InstalledVersion{
+			Version:      cm.release.Version,
+			Distribution: cm.distributionName(),
+			Path:         cm.installDir,
+			InstalledAt:  now,
+			Pinned:       pinned,
+		}
+		if size, err := dirSize(cm.installDir); err == nil {
+			entry.SizeBytes = size
+		}
+		m.Versions[key] = entry
+	}
+	entry.LastUsed = now
+	entry.Executable = cm.execPath
+
+	if err := cm.saveManifest(m); err != nil {
+		cm.logger("chrome_manifest_error", err.Error())
+	}
+}
+
+// ListVersions mengembalikan versi terinstall, diurutkan dari yang terakhir dipakai
+func (cm *ChromiumManager) ListVersions() ([]InstalledVersion, error) {
+	m, err := cm.loadManifest()
+	if err != nil {
+		return nil, err
+	}
+	var versions []InstalledVersion
+	for _, key := range sortedKeys(m) {
+		versions = append(versions, *m.Versions[key])
+	}
+	return versions, nil
+}
+
+// PinVersion menandai versi agar tidak dihapus oleh PruneVersions. version
+// boleh berupa "<distribution>/<version>"; versi tanpa distribusi hanya
+// diterima jika terinstall untuk satu distribusi saja.
+func (cm *ChromiumManager) PinVersion(version string, pinned bool) error {
+	lock, err := cm.acquireLock(context.Background())
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	m, err := cm.loadManifest()
+	if err != nil {
+		return err
+	}
+
+	key, err := findInstallKey(m, version)
+	if err != nil {
+		return err
+	}
+	m.Versions[key].Pinned = pinned
+
+	cm.logger("chrome_version_pinned", fmt.Sprintf("%s=%t", version, pinned))
+	return cm.saveManifest(m)
+}
+
+// PruneVersions menghapus versi lama sesuai opts dan mengembalikan versi yang
+// dihapus. Versi yang Chrome-nya sedang berjalan (di proses mana pun)
+// diperlakukan seperti versi yang di-pin.
+func (cm *ChromiumManager) PruneVersions(opts PruneOptions) ([]InstalledVersion, error) {
+	lock, err := cm.acquireLock(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	m, err := cm.loadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := sortedKeys(m)
+	remove := make(map[string]bool)
+
+	// Lock in-use dipegang sampai selesai agar tidak ada Chrome yang mulai
+	// dari versi yang sedang dihapus
+	keep := make(map[string]bool)
+	for _, key := range keys {
+		if m.Versions[key].Pinned {
+			keep[key] = true
+			continue
+		}
+		f, inUse, err := lockUnused(filepath.Join(cm.rootDir, filepath.FromSlash(key)))
+		if err != nil {
+			return nil, err
+		}
+		if inUse {
+			cm.logger("chrome_version_in_use", key)
+			keep[key] = true
+			continue
+		}
+		if f != nil {
+			defer f.Close()
+		}
+	}
+
+	var total int64
+	unpinned := 0
+	for _, key := range keys {
+		v := m.Versions[key]
+		total += v.SizeBytes
+		if keep[key] {
+			continue
+		}
+		unpinned++
+		if opts.MaxAge > 0 && time.Since(v.LastUsed) > opts.MaxAge {
+			remove[key] = true
+		}
+		if opts.KeepLatest > 0 && unpinned > opts.KeepLatest {
+			remove[key] = true
+		}
+	}
+
+	if opts.MaxTotalSize > 0 {
+		for _, key := range keys {
+			if remove[key] {
+				total -= m.Versions[key].SizeBytes
+			}
+		}
+		// Hapus dari yang paling lama dipakai
+		for i := len(keys) - 1; i >= 0 && total > opts.MaxTotalSize; i-- {
+			v := m.Versions[keys[i]]
+			if keep[keys[i]] || remove[keys[i]] {
+				continue
+			}
+			remove[keys[i]] = true
+			total -= v.SizeBytes
+		}
+	}
+
+	var removed []InstalledVersion
+	for _, key := range keys {
+		if !remove[key] {
+			continue
+		}
+		v := *m.Versions[key]
+		if !opts.DryRun {
+			if err := os.RemoveAll(filepath.Join(cm.rootDir, filepath.FromSlash(key))); err != nil {
+				return removed, fmt.Errorf("failed to remove version %s: %w", key, err)
+			}
+			delete(m.Versions, key)
+		}
+		cm.logger("chrome_version_pruned", key)
+		removed = append(removed, v)
+	}
+
+	if !opts.DryRun && len(removed) > 0 {
+		if err := cm.saveManifest(m); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// sortedKeys mengurutkan key manifest dari versi yang terakhir dipakai
+func sortedKeys(m *manifest) []string {
+	keys := make([]string, 0, len(m.Versions))
+	for key := range m.Versions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return m.Versions[keys[i]].LastUsed.After(m.Versions[keys[j]].LastUsed)
+	})
+	return keys
+}
+
+// findInstallKey mencari key manifest untuk "<distribution>/<version>" atau
+// versi tanpa distribusi
+func findInstallKey(m *manifest, version string) (string, error) {
+	if dist, ver, ok := strings.Cut(version, "/"); ok {
+		key := installKey(dist, ver)
+		if _, ok := m.Versions[key]; !ok {
+			return "", fmt.Errorf("version %s is not installed", version)
+		}
+		return key, nil
+	}
+
+	var keys []string
+	for key, v := range m.Versions {
+		if v.Version == version {
+			keys = append(keys, key)
+		}
+	}
+	switch len(keys) {
+	case 0:
+		return "", fmt.Errorf("version %s is not installed", version)
+	case 1:
+		return keys[0], nil
+	}
+	sort.Strings(keys)
+	return "", fmt.Errorf("version %s is installed for several distributions, use one of: %s", version, strings.Join(keys, ", "))
+}
+
+// dirSize menghitung total ukuran file di dir
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size, err
+}
+
+// migrateLegacyInstall memindahkan instalasi lama yang diekstrak langsung di
+// root (<root>/.version dan <root>/chrome-linux64/) ke
+// <root>/chrome-for-testing/<version>/ lalu mencatatnya di manifest, sehingga
+// bisa dipakai ulang dan dihapus oleh PruneVersions. Jika versi itu sudah
+// terinstall dengan layout baru, file lama langsung dihapus. Dipanggil dari
+// Setup saat memegang lock.
+func (cm *ChromiumManager) migrateLegacyInstall() {
+	versionFile := filepath.Join(cm.rootDir, ".version")
+	data, err := os.ReadFile(versionFile)
+	if err != nil {
+		return
+	}
+	version := strings.TrimSpace(string(data))
+
+	// .version dipindahkan terakhir agar migrasi yang terputus diulang
+	var entries []string
+	for _, name := range []string{"chrome", ".chrome-download.zip"} {
+		if _, err := os.Lstat(filepath.Join(cm.rootDir, name)); err == nil {
+			entries = append(entries, name)
+		}
+	}
+	dirs, _ := os.ReadDir(cm.rootDir)
+	for _, entry := range dirs {
+		if entry.IsDir() && entry.Name() != "libs" {
+			if _, err := os.Stat(filepath.Join(cm.rootDir, entry.Name(), "chrome")); err == nil {
+				entries = append(entries, entry.Name())
+			}
+		}
+	}
+	entries = append(entries, ".version")
+
+	key := installKey(DistChromeForTesting, version)
+	target := filepath.Join(cm.rootDir, filepath.FromSlash(key))
+	if _, err := os.Stat(target); err == nil || version == "" {
+		for _, name := range entries {
+			if err := os.RemoveAll(filepath.Join(cm.rootDir, name)); err != nil {
+				cm.logger("chrome_legacy_error", err.Error())
+				return
+			}
+		}
+		cm.logger("chrome_legacy_removed", version)
+		return
+	}
+
+	if err := cm.moveLegacyInstall(entries, target); err != nil {
+		cm.logger("chrome_legacy_error", err.Error())
+		return
+	}
+
+	m, err := cm.loadManifest()
+	if err != nil {
+		cm.logger("chrome_manifest_error", err.Error())
+		return
+	}
+	info, err := os.Stat(filepath.Join(target, ".version"))
+	if err != nil {
+		cm.logger("chrome_legacy_error", err.Error())
+		return
+	}
+	entry := &InstalledVersion{
+		Version:      version,
+		Distribution: DistChromeForTesting,
+		Path:         target,
+		Executable:   findChromeExecutable(target),
+		InstalledAt:  info.ModTime(),
+		LastUsed:     info.ModTime(),
+	}
+	if size, err := dirSize(target); err == nil {
+		entry.SizeBytes = size
+	}
+	m.Versions[key] = entry
+	if err := cm.saveManifest(m); err != nil {
+		cm.logger("chrome_manifest_error", err.Error())
+		return
+	}
+	cm.logger("chrome_legacy_migrated", key)
+}
+
+// moveLegacyInstall memindahkan entries di root ke staging di sebelah target
+// lalu me-rename staging menjadi target
+func (cm *ChromiumManager) moveLegacyInstall(entries []string, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(target), filepath.Base(target)+".staging-*")
+	if err != nil {
+		return err
+	}
+	for _, name := range entries {
+		if err := os.Rename(filepath.Join(cm.rootDir, name), filepath.Join(staging, name)); err != nil {
+			return fmt.Errorf("failed to move legacy %s: %w", name, err)
+		}
+	}
+	return os.Rename(staging, target)
+}
@@ -0,0 +1,256 @@
+package browser_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// installVersions menginstall beberapa versi berurutan di root yang sama
+func installVersions(t *testing.T, versions ...string) (*browser.ChromiumManager, string) {
+	t.Helper()
+	archive := buildChromeZip(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	t.Cleanup(srv.Close)
+
+	var mgr *browser.ChromiumManager
+	var rootDir string
+	for i, version := range versions {
+		config := browser.Config{
+			DownloadURL:    srv.URL + "/" + version + "/chrome-linux64.zip",
+			InstallDirName: "chrome-test",
+			Version:        version,
+		}
+		if i == 0 {
+			mgr, rootDir = newTestManager(t, config)
+		} else {
+			mgr = browser.NewChromiumManagerWithConfig(config, nil)
+		}
+		if err := mgr.Setup(); err != nil {
+			t.Fatalf("Setup %s failed: %v", version, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return mgr, rootDir
+}
+
+func TestVersionsInstalledSideBySide(t *testing.T) {
+	mgr, rootDir := installVersions(t, "1.0.0.0", "2.0.0.0")
+
+	for _, version := range []string{"1.0.0.0", "2.0.0.0"} {
+		if _, err := os.Stat(filepath.Join(rootDir, "custom_url", version, "chrome-linux64", "chrome")); err != nil {
+			t.Errorf("version %s should still be installed: %v", version, err)
+		}
+	}
+
+	versions, err := mgr.ListVersions()
+	if err != nil {
+		t.Fatalf("ListVersions failed: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != "2.0.0.0" {
+		t.Fatalf("expected 2 versions with most recent first, got %+v", versions)
+	}
+	if versions[0].SizeBytes == 0 || versions[0].LastUsed.IsZero() {
+		t.Errorf("expected size and last-used to be recorded, got %+v", versions[0])
+	}
+}
+
+func TestPruneKeepsPinnedVersions(t *testing.T) {
+	mgr, rootDir := installVersions(t, "1.0.0.0", "2.0.0.0", "3.0.0.0")
+
+	if err := mgr.PinVersion("1.0.0.0", true); err != nil {
+		t.Fatalf("PinVersion failed: %v", err)
+	}
+	if err := mgr.PinVersion("9.9.9.9", true); err == nil {
+		t.Error("pinning a missing version should fail")
+	}
+
+	removed, err := mgr.PruneVersions(browser.PruneOptions{KeepLatest: 1})
+	if err != nil {
+		t.Fatalf("PruneVersions failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0].Version != "2.0.0.0" {
+		t.Fatalf("expected only 2.0.0.0 to be pruned, got %+v", removed)
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, "custom_url", "2.0.0.0")); !os.IsNotExist(err) {
+		t.Error("pruned version directory should be removed")
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, "custom_url", "1.0.0.0")); err != nil {
+		t.Error("pinned version should be kept")
+	}
+
+	versions, _ := mgr.ListVersions()
+	if len(versions) != 2 {
+		t.Errorf("expected 2 versions after prune, got %d", len(versions))
+	}
+}
+
+func TestPruneByAgeAndSize(t *testing.T) {
+	mgr, rootDir := installVersions(t, "1.0.0.0", "2.0.0.0")
+
+	removed, err := mgr.PruneVersions(browser.PruneOptions{MaxAge: time.Hour, DryRun: true})
+	if err != nil || len(removed) != 0 {
+		t.Fatalf("no version is older than an hour, got %+v (%v)", removed, err)
+	}
+
+	removed, err = mgr.PruneVersions(browser.PruneOptions{MaxTotalSize: 1, DryRun: true})
+	if err != nil || len(removed) != 2 {
+		t.Fatalf("expected both versions to exceed size budget, got %+v (%v)", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, "custom_url", "1.0.0.0")); err != nil {
+		t.Error("dry run should not remove anything")
+	}
+}
+
+func TestDistributionsInstalledSideBySide(t *testing.T) {
+	skipUnlessLinuxAmd64(t)
+	archives := map[string][]byte{
+		"/chrome-linux64.zip":                zipWithFile(t, "chrome-linux64/chrome"),
+		"/chrome-headless-shell-linux64.zip": zipWithFile(t, "chrome-headless-shell-linux64/chrome-headless-shell"),
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/last-known-good-versions-with-downloads.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"channels": map[string]interface{}{
+			"Stable": map[string]interface{}{
+				"version": "131.0.6778.204",
+				"downloads": map[string]interface{}{
+					"chrome":                []map[string]string{{"platform": "linux64", "url": srv.URL + "/chrome-linux64.zip"}},
+					"chrome-headless-shell": []map[string]string{{"platform": "linux64", "url": srv.URL + "/chrome-headless-shell-linux64.zip"}},
+				},
+			},
+		}})
+	})
+	downloads := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		w.Write(archives[r.URL.Path])
+	})
+
+	var mgr *browser.ChromiumManager
+	var rootDir string
+	setup := func(dist string) {
+		t.Helper()
+		config := browser.Config{InstallDirName: "chrome-test", Distribution: dist, VersionsBaseURL: srv.URL}
+		if mgr == nil {
+			mgr, rootDir = newTestManager(t, config)
+		} else {
+			mgr = browser.NewChromiumManagerWithConfig(config, nil)
+		}
+		if err := mgr.Setup(); err != nil {
+			t.Fatalf("Setup %s failed: %v", dist, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	setup(browser.DistChromeForTesting)
+	setup(browser.DistHeadlessShell)
+	// Kembali ke distribusi pertama tidak mengunduh ulang
+	setup(browser.DistChromeForTesting)
+	if downloads != 2 {
+		t.Errorf("expected 2 downloads, got %d", downloads)
+	}
+
+	for _, exe := range []string{
+		filepath.Join(rootDir, browser.DistChromeForTesting, "131.0.6778.204", "chrome-linux64", "chrome"),
+		filepath.Join(rootDir, browser.DistHeadlessShell, "131.0.6778.204", "chrome-headless-shell-linux64", "chrome-headless-shell"),
+	} {
+		if _, err := os.Stat(exe); err != nil {
+			t.Errorf("%s should be installed: %v", exe, err)
+		}
+	}
+
+	versions, err := mgr.ListVersions()
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("expected 2 manifest entries, got %+v (%v)", versions, err)
+	}
+
+	if err := mgr.PinVersion("131.0.6778.204", true); err == nil {
+		t.Error("pinning a version installed for two distributions should be ambiguous")
+	}
+	if err := mgr.PinVersion(browser.DistHeadlessShell+"/131.0.6778.204", true); err != nil {
+		t.Fatalf("PinVersion failed: %v", err)
+	}
+	removed, err := mgr.PruneVersions(browser.PruneOptions{MaxTotalSize: 1})
+	if err != nil || len(removed) != 1 || removed[0].Distribution != browser.DistChromeForTesting {
+		t.Fatalf("expected only chrome-for-testing to be pruned, got %+v (%v)", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, browser.DistHeadlessShell, "131.0.6778.204")); err != nil {
+		t.Error("pinned distribution should be kept")
+	}
+}
+
+func TestPruneSkipsVersionInUse(t *testing.T) {
+	cdp := newFakeCDP(t)
+	mgr := installFakeChrome(t, cdp.Script())
+
+	b, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{Sandbox: browser.SandboxOff})
+	if err != nil {
+		t.Fatalf("GetBrowserWithOptions failed: %v", err)
+	}
+	defer b.Close()
+
+	removed, err := mgr.PruneVersions(browser.PruneOptions{MaxTotalSize: 1})
+	if err != nil {
+		t.Fatalf("PruneVersions failed: %v", err)
+	}
+	if len(removed) != 0 {
+		t.Errorf("version with running chrome should not be pruned, got %+v", removed)
+	}
+	if _, err := os.Stat(mgr.GetExecutablePath()); err != nil {
+		t.Errorf("executable removed while chrome runs: %v", err)
+	}
+}
+
+func TestSetupMigratesLegacyInstall(t *testing.T) {
+	srv := newVersionsServer(t)
+	config := browser.Config{InstallDirName: "chrome-test", Version: "129.0.6668.100", VersionsBaseURL: srv.URL}
+	mgr, rootDir := newTestManager(t, config)
+
+	// Layout lama: Chrome diekstrak langsung di root, libs/ dipakai bersama
+	legacy := map[string]string{
+		".version":              "129.0.6668.100",
+		"chrome-linux64/chrome": "legacy",
+		"libs/libnss3.so":       "lib",
+	}
+	for name, content := range legacy {
+		path := filepath.Join(rootDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := mgr.Setup(); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	exe := filepath.Join(rootDir, browser.DistChromeForTesting, "129.0.6668.100", "chrome-linux64", "chrome")
+	if data, err := os.ReadFile(exe); err != nil || string(data) != "legacy" {
+		t.Errorf("legacy install should be moved without downloading again, got %q (%v)", data, err)
+	}
+	for _, name := range []string{".version", "chrome-linux64"} {
+		if _, err := os.Stat(filepath.Join(rootDir, name)); !os.IsNotExist(err) {
+			t.Errorf("legacy %s should be moved out of root", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, "libs", "libnss3.so")); err != nil {
+		t.Errorf("shared libs should be kept: %v", err)
+	}
+
+	versions, err := mgr.ListVersions()
+	if err != nil || len(versions) != 1 || versions[0].Distribution != browser.DistChromeForTesting {
+		t.Fatalf("expected migrated version in manifest, got %+v (%v)", versions, err)
+	}
+}
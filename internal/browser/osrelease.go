@@ -0,0 +1,41 @@
+package browser
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// osReleasePath adalah lokasi file identifikasi distro
+var osReleasePath = "/etc/os-release"
+
+// readOSRelease membaca file os-release (KEY=VALUE, value boleh diberi kutip).
+// File yang tidak ada menghasilkan map kosong.
+func readOSRelease(path string) map[string]string {
+	values := map[string]string{}
+	file, err := os.Open(path)
+	if err != nil {
+		return values
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else {
+			value = strings.Trim(value, `'"`)
+		}
+		values[key] = value
+	}
+	return values
+}
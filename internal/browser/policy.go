@@ -0,0 +1,212 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// blockedByClient adalah error Chrome untuk request yang digagalkan lewat Fetch.failRequest
+const blockedByClient = "net::ERR_BLOCKED_BY_CLIENT"
+
+// PolicyRule mencocokkan request. Field kosong berarti cocok dengan semua nilai;
+// field yang diisi harus cocok semuanya.
+type PolicyRule struct {
+	// Hosts berisi glob host, contoh "example.com" atau "*.example.com"
+	// ("*.example.com" tidak mencakup "example.com" itu sendiri)
+	Hosts []string
+	// Schemes contoh "https", "wss". WebSocket tidak lewat Fetch domain dan
+	// ditegakkan di proxy lokal: ws:// dicek lengkap, sedangkan wss:// (di
+	// dalam TLS) tidak bisa dibedakan dari https:// sehingga hanya host dan
+	// port yang dicek
+	Schemes []string
+	// ResourceTypes berisi tipe resource CDP, contoh "Document", "Image", "Script"
+	ResourceTypes []string
+	// Ports berisi port tujuan; URL tanpa port memakai port default scheme
+	Ports []int
+}
+
+// Policy membatasi request yang boleh dimuat browser. Deny selalu menang;
+// jika Allow tidak kosong, request harus cocok dengan salah satu rule Allow.
+// Request HTTP dicek lewat Fetch domain; koneksi keluar (termasuk WebSocket)
+// juga dicek di proxy lokal yang dipasang sebagai --proxy-server.
+type Policy struct {
+	Allow []PolicyRule
+	Deny  []PolicyRule
+}
+
+// NavigationBlockedError dikembalikan jika URL top-level ditolak policy
+type NavigationBlockedError struct {
+	URL    string
+	Reason string
+}
+
+func (e *NavigationBlockedError) Error() string {
+	return fmt.Sprintf("navigation to %s blocked by policy: %s", e.URL, e.Reason)
+}
+
+// Decide mengembalikan apakah request ke rawURL dengan tipe resourceType
+// diizinkan, beserta alasan jika ditolak
+func (p Policy) Decide(rawURL, resourceType string) (bool, string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, "invalid url"
+	}
+	for i, rule := range p.Deny {
+		if rule.matches(u, resourceType) {
+			return false, "denied by rule " + strconv.Itoa(i)
+		}
+	}
+	if len(p.Allow) == 0 {
+		return true, ""
+	}
+	for _, rule := range p.Allow {
+		if rule.matches(u, resourceType) {
+			return true, ""
+		}
+	}
+	return false, "not in allowlist"
+}
+
+// Navigate membuka rawURL di page setelah dicek ke policy. URL yang ditolak,
+// termasuk redirect ke host yang ditolak, menghasilkan *NavigationBlockedError.
+func (p Policy) Navigate(page *rod.Page, rawURL string) error {
+	if ok, reason := p.Decide(rawURL, string(proto.NetworkResourceTypeDocument)); !ok {
+		return &NavigationBlockedError{URL: rawURL, Reason: reason}
+	}
+	err := page.Navigate(rawURL)
+	var navErr *rod.NavigationError
+	if errors.As(err, &navErr) && navErr.Reason == blockedByClient {
+		return &NavigationBlockedError{URL: rawURL, Reason: "redirect or subresource blocked by policy"}
+	}
+	return err
+}
+
+// handler mengembalikan requestHandler yang menggagalkan request yang ditolak
+func (p Policy) handler(logger func(key, value string)) requestHandler {
+	return func(h *rod.Hijack) bool {
+		rawURL := h.Request.URL().String()
+		resourceType := string(h.Request.Type())
+		if ok, reason := p.Decide(rawURL, resourceType); !ok {
+			logger("policy_blocked", fmt.Sprintf("type=%s url=%s reason=%s", resourceType, rawURL, reason))
+			h.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+			return true
+		}
+		return false
+	}
+}
+
+// allowsConnection mengecek apakah koneksi ke host:port boleh dibuka untuk
+// salah satu schemes. Isi koneksi (path dan tipe resource) tidak terlihat,
+// sehingga ResourceTypes rule Allow diabaikan dan rule Deny dengan
+// ResourceTypes dilewati; request HTTP tetap dicek per URL lewat Fetch.
+func (p Policy) allowsConnection(host string, port int, schemes ...string) (bool, string) {
+	reason := "not in allowlist"
+	for _, scheme := range schemes {
+		u := &url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(port))}
+		denied := false
+		for i, rule := range p.Deny {
+			if len(rule.ResourceTypes) == 0 && rule.matches(u, "") {
+				denied, reason = true, "denied by rule "+strconv.Itoa(i)
+				break
+			}
+		}
+		if denied {
+			continue
+		}
+		if len(p.Allow) == 0 {
+			return true, ""
+		}
+		for _, rule := range p.Allow {
+			rule.ResourceTypes = nil
+			if rule.matches(u, "") {
+				return true, ""
+			}
+		}
+	}
+	return false, reason
+}
+
+// validate mengecek glob host yang tidak valid
+func (p Policy) validate() error {
+	for _, rules := range [][]PolicyRule{p.Allow, p.Deny} {
+		for _, rule := range rules {
+			for _, host := range rule.Hosts {
+				if _, err := path.Match(strings.ToLower(host), ""); err != nil {
+					return fmt.Errorf("invalid host glob %q: %w", host, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// matches mengecek apakah u dan resourceType cocok dengan rule
+func (r PolicyRule) matches(u *url.URL, resourceType string) bool {
+	if len(r.Schemes) > 0 && !containsFold(r.Schemes, u.Scheme) {
+		return false
+	}
+	if len(r.ResourceTypes) > 0 && !containsFold(r.ResourceTypes, resourceType) {
+		return false
+	}
+	if len(r.Hosts) > 0 {
+		host := strings.ToLower(u.Hostname())
+		matched := false
+		for _, pattern := range r.Hosts {
+			if ok, _ := path.Match(strings.ToLower(pattern), host); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.Ports) > 0 {
+		port := urlPort(u)
+		matched := false
+		for _, p := range r.Ports {
+			if p == port {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// urlPort mengembalikan port URL, atau port default scheme jika tidak ada
+func urlPort(u *url.URL) int {
+	if port := u.Port(); port != "" {
+		n, _ := strconv.Atoi(port)
+		return n
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "ws":
+		return 80
+	case "https", "wss":
+		return 443
+	case "ftp":
+		return 21
+	}
+	return 0
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+package browser_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+func TestPolicyDecide(t *testing.T) {
+	policy := browser.Policy{
+		Allow: []browser.PolicyRule{
+			{Hosts: []string{"example.com", "*.example.com"}, Schemes: []string{"https"}},
+			{Hosts: []string{"cdn.test"}, ResourceTypes: []string{"Image", "Stylesheet"}},
+			{Hosts: []string{"localhost"}, Ports: []int{8080}},
+		},
+		Deny: []browser.PolicyRule{
+			{Hosts: []string{"ads.example.com"}},
+		},
+	}
+
+	tests := []struct {
+		url          string
+		resourceType string
+		allowed      bool
+	}{
+		{"https://example.com/", "Document", true},
+		{"https://www.Example.com/page", "Document", true},
+		{"http://example.com/", "Document", false},
+		{"https://ads.example.com/banner.js", "Script", false},
+		{"https://evil.com/", "Document", false},
+		{"https://example.com.evil.com/", "Document", false},
+		{"http://cdn.test/logo.png", "image", true},
+		{"http://cdn.test/app.js", "Script", false},
+		{"http://localhost:8080/api", "XHR", true},
+		{"http://localhost/api", "XHR", false},
+	}
+	for _, tt := range tests {
+		allowed, reason := policy.Decide(tt.url, tt.resourceType)
+		if allowed != tt.allowed {
+			t.Errorf("Decide(%s, %s) = %v (%s), want %v", tt.url, tt.resourceType, allowed, reason, tt.allowed)
+		}
+	}
+
+	if allowed, _ := (browser.Policy{}).Decide("https://anything.test/", "Document"); !allowed {
+		t.Error("empty policy should allow everything")
+	}
+}
+
+func TestPolicyBlocksInterceptedRequests(t *testing.T) {
+	cdp := newFakeCDP(t)
+	logs := &logRecorder{}
+	mgr := installFakeChromeWithLogger(t, cdp.Script(), logs.Log)
+
+	policy := &browser.Policy{Allow: []browser.PolicyRule{{Hosts: []string{"example.com"}}}}
+	b, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{
+		Sandbox: browser.SandboxOff,
+		Policy:  policy,
+	})
+	if err != nil {
+		t.Fatalf("GetBrowserWithOptions failed: %v", err)
+	}
+	defer b.Close()
+
+	if cdp.Calls("Fetch.enable") == 0 {
+		t.Fatal("policy should enable the Fetch domain")
+	}
+
+	cdp.Emit("Fetch.requestPaused", "", pausedRequest("req-1", "https://tracker.test/pixel.gif", "Image"))
+	cdp.Emit("Fetch.requestPaused", "", pausedRequest("req-2", "https://example.com/app.js", "Script"))
+
+	waitFor(t, "blocked request to fail", func() bool { return cdp.Calls("Fetch.failRequest") == 1 })
+	waitFor(t, "allowed request to continue", func() bool { return cdp.Calls("Fetch.continueRequest") == 1 })
+	if !logs.Has("policy_blocked", "https://tracker.test/pixel.gif") {
+		t.Error("blocked request should be logged")
+	}
+	if logs.Has("policy_blocked", "example.com") {
+		t.Error("allowed request must not be logged as blocked")
+	}
+}
+
+func TestPolicyNavigateReturnsTypedError(t *testing.T) {
+	cdp := newFakeCDP(t)
+	mgr := installFakeChrome(t, cdp.Script())
+	policy := browser.Policy{Allow: []browser.PolicyRule{{Hosts: []string{"example.com"}}}}
+
+	b, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{
+		Sandbox: browser.SandboxOff,
+		Policy:  &policy,
+	})
+	if err != nil {
+		t.Fatalf("GetBrowserWithOptions failed: %v", err)
+	}
+	defer b.Close()
+	page, err := b.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		t.Fatalf("Page failed: %v", err)
+	}
+
+	err = policy.Navigate(page, "https://evil.test/")
+	var blocked *browser.NavigationBlockedError
+	if !errors.As(err, &blocked) || blocked.URL != "https://evil.test/" {
+		t.Fatalf("expected NavigationBlockedError, got %v", err)
+	}
+	if cdp.Calls("Page.navigate") != 0 {
+		t.Error("disallowed URL must not reach the browser")
+	}
+
+	// Redirect ke host yang ditolak digagalkan oleh interception
+	cdp.Handle("Page.navigate", func(json.RawMessage) (any, error) {
+		return map[string]string{"frameId": "frame", "errorText": "net::ERR_BLOCKED_BY_CLIENT"}, nil
+	})
+	err = policy.Navigate(page, "https://example.com/redirect")
+	if !errors.As(err, &blocked) {
+		t.Fatalf("expected NavigationBlockedError for blocked redirect, got %v", err)
+	}
+}
+
+// pausedRequest membuat params event Fetch.requestPaused
+func pausedRequest(id, url, resourceType string) map[string]any {
+	return map[string]any{
+		"requestId":    id,
+		"frameId":      "frame",
+		"resourceType": resourceType,
+		"request": map[string]any{
+			"url":             url,
+			"method":          "GET",
+			"headers":         map[string]string{},
+			"initialPriority": "High",
+			"referrerPolicy":  "no-referrer",
+		},
+	}
+}
+
+// wsHandshake mengirim handshake WebSocket lewat conn dan mengembalikan status respons
+func wsHandshake(t *testing.T, conn net.Conn, host string) int {
+	t.Helper()
+	fmt.Fprintf(conn, "GET /socket HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n", host)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("reading handshake response: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// Test WebSocket (yang tidak terlihat oleh Fetch) dicek Policy di proxy lokal
+func TestPolicyBlocksWebSocketThroughProxy(t *testing.T) {
+	upgrade := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		io.WriteString(conn, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		conn.Close()
+	})
+	allowed := httptest.NewServer(upgrade)
+	defer allowed.Close()
+	denied := httptest.NewServer(upgrade)
+	defer denied.Close()
+	deniedPort, _ := strconv.Atoi(denied.URL[strings.LastIndex(denied.URL, ":")+1:])
+
+	cdp := newFakeCDP(t)
+	logs := &logRecorder{}
+	argsFile := filepath.Join(t.TempDir(), "args")
+	mgr := installFakeChromeWithLogger(t, cdp.Script(`printf '%s\n' "$@" > `+argsFile), logs.Log)
+	b, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{
+		Sandbox: browser.SandboxOff,
+		Policy: &browser.Policy{
+			Allow: []browser.PolicyRule{{Hosts: []string{"127.0.0.1"}}},
+			Deny:  []browser.PolicyRule{{Schemes: []string{"ws"}, Ports: []int{deniedPort}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("GetBrowserWithOptions failed: %v", err)
+	}
+	defer b.Close()
+	proxyAddr := strings.TrimPrefix(proxyFlag(t, argsFile), "http://")
+
+	tunnel := func(target string) (net.Conn, int) {
+		conn, err := net.Dial("tcp", proxyAddr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", target, target)
+		resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: http.MethodConnect})
+		if err != nil {
+			t.Fatalf("CONNECT %s: %v", target, err)
+		}
+		return conn, resp.StatusCode
+	}
+
+	for name, tc := range map[string]struct {
+		target       string
+		tunnelStatus int
+		wsStatus     int
+	}{
+		"allowed":               {strings.TrimPrefix(allowed.URL, "http://"), http.StatusOK, http.StatusSwitchingProtocols},
+		"denied scheme":         {strings.TrimPrefix(denied.URL, "http://"), http.StatusOK, http.StatusForbidden},
+		"host not in allowlist": {"localhost:" + strconv.Itoa(deniedPort), http.StatusForbidden, 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			conn, status := tunnel(tc.target)
+			defer conn.Close()
+			if status != tc.tunnelStatus {
+				t.Fatalf("CONNECT status %d, want %d", status, tc.tunnelStatus)
+			}
+			if tc.wsStatus != 0 {
+				if got := wsHandshake(t, conn, tc.target); got != tc.wsStatus {
+					t.Errorf("handshake status %d, want %d", got, tc.wsStatus)
+				}
+			}
+		})
+	}
+	if !logs.Has("policy_blocked", "type=WebSocket url=ws://"+strings.TrimPrefix(denied.URL, "http://")+"/socket") {
+		t.Error("blocked WebSocket should be logged")
+	}
+	if !logs.Has("policy_blocked", "type=connect url=localhost:") {
+		t.Error("blocked tunnel should be logged")
+	}
+}
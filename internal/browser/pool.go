@@ -0,0 +1,496 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ErrPoolClosed dikembalikan oleh Acquire setelah Pool.Close dipanggil
+var ErrPoolClosed = errors.New("browser pool is closed")
+
+// maxAcquireAttempts membatasi percobaan Acquire saat browser crash berulang
+const maxAcquireAttempts = 3
+
+// PoolOptions mengatur ukuran dan daur ulang Pool
+type PoolOptions struct {
+	// Browsers adalah jumlah proses Chrome (default 1)
+	Browsers int
+	// ContextsPerBrowser adalah jumlah incognito context bersamaan per browser (default 4)
+	ContextsPerBrowser int
+	// MaxUsesPerBrowser mendaur ulang browser setelah sekian lease; 0 berarti tanpa batas
+	MaxUsesPerBrowser int
+	// Launch dipakai untuk setiap browser; zero value berarti DefaultLaunchOptions
+	Launch *LaunchOptions
+}
+
+// PoolStats berisi ringkasan kondisi Pool
+type PoolStats struct {
+	// Browsers adalah jumlah browser yang sedang berjalan, termasuk yang menunggu didaur ulang
+	Browsers int
+	// Capacity adalah jumlah lease maksimum bersamaan (Browsers x ContextsPerBrowser)
+	Capacity int
+	// InUse adalah jumlah lease yang belum di-Release
+	InUse int
+	// Waiting adalah jumlah pemanggil Acquire yang menunggu slot kosong
+	Waiting int
+	// Acquired, Launched, Recycled dan Crashed adalah counter sejak Pool dibuat
+	Acquired uint64
+	Launched uint64
+	Recycled uint64
+	Crashed  uint64
+}
+
+// Pool menjaga sejumlah browser yang dipakai bersama untuk banyak job.
+// Setiap Acquire membuat page di incognito context baru, sehingga cookie
+// dan storage tidak bocor antar job; Release menghapus context tersebut.
+type Pool struct {
+	cm     *ChromiumManager
+	opts   PoolOptions
+	launch LaunchOptions
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// sem membatasi jumlah lease bersamaan
+	sem chan struct{}
+
+	mu       sync.Mutex
+	slots    []*poolSlot
+	retiring map[*pooledBrowser]bool
+	closed   bool
+	stats    PoolStats
+	nextID   int
+}
+
+// poolSlot adalah posisi browser di pool; browser diganti saat didaur ulang
+type poolSlot struct {
+	browser   *pooledBrowser
+	launching *slotLaunch
+}
+
+// slotLaunch adalah launch browser yang sedang berjalan untuk sebuah slot.
+// err hanya boleh dibaca setelah done ditutup.
+type slotLaunch struct {
+	done chan struct{}
+	err  error
+}
+
+// pooledBrowser adalah satu proses Chrome di pool
+type pooledBrowser struct {
+	id      int
+	browser *rod.Browser
+	process *chromeProcess
+	active  int
+	uses    int
+	retired bool
+	closed  bool
+}
+
+// Lease adalah page yang dipinjam dari Pool. Kembalikan dengan Pool.Release.
+type Lease struct {
+	Page *rod.Page
+
+	pool     *Pool
+	browser  *pooledBrowser
+	incog    *rod.Browser
+	released bool
+}
+
+// NewPool menyiapkan Chrome (download jika perlu) lalu membuat Pool.
+// Browser dijalankan saat dibutuhkan oleh Acquire, bukan di sini.
+func NewPool(ctx context.Context, cm *ChromiumManager, opts PoolOptions) (*Pool, error) {
+	if opts.Browsers <= 0 {
+		opts.Browsers = 1
+	}
+	if opts.ContextsPerBrowser <= 0 {
+		opts.ContextsPerBrowser = 4
+	}
+	if opts.MaxUsesPerBrowser < 0 {
+		return nil, fmt.Errorf("invalid MaxUsesPerBrowser %d", opts.MaxUsesPerBrowser)
+	}
+	launch := DefaultLaunchOptions()
+	if opts.Launch != nil {
+		launch = *opts.Launch
+	}
+	if err := launch.validate(); err != nil {
+		return nil, fmt.Errorf("invalid launch options: %w", err)
+	}
+
+	if err := cm.SetupContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to setup chrome: %w", err)
+	}
+	if cm.execPath == "" {
+		return nil, fmt.Errorf("chrome executable not found after setup")
+	}
+
+	// Browser terikat ke context milik pool, bukan ke ctx pemanggil
+	poolCtx, cancel := context.WithCancel(context.Background())
+	capacity := opts.Browsers * opts.ContextsPerBrowser
+	p := &Pool{
+		cm:       cm,
+		opts:     opts,
+		launch:   launch,
+		ctx:      poolCtx,
+		cancel:   cancel,
+		sem:      make(chan struct{}, capacity),
+		slots:    make([]*poolSlot, opts.Browsers),
+		retiring: map[*pooledBrowser]bool{},
+	}
+	for i := range p.slots {
+		p.slots[i] = &poolSlot{}
+	}
+	p.stats.Capacity = capacity
+
+	cm.logger("pool_browsers", strconv.Itoa(opts.Browsers))
+	cm.logger("pool_contexts_per_browser", strconv.Itoa(opts.ContextsPerBrowser))
+	return p, nil
+}
+
+// Acquire meminjam page baru di incognito context. Menunggu jika semua slot
+// sedang dipakai sampai ada Release atau ctx selesai.
+func (p *Pool) Acquire(ctx context.Context) (*Lease, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrPoolClosed
+	}
+	p.stats.Waiting++
+	p.mu.Unlock()
+
+	select {
+	case p.sem <- struct{}{}:
+	case <-ctx.Done():
+		p.mu.Lock()
+		p.stats.Waiting--
+		p.mu.Unlock()
+		return nil, ctx.Err()
+	case <-p.ctx.Done():
+		p.mu.Lock()
+		p.stats.Waiting--
+		p.mu.Unlock()
+		return nil, ErrPoolClosed
+	}
+
+	p.mu.Lock()
+	p.stats.Waiting--
+	p.mu.Unlock()
+
+	var lastErr error
+	for attempt := 0; attempt < maxAcquireAttempts; attempt++ {
+		pb, err := p.pick(ctx)
+		if err != nil {
+			<-p.sem
+			return nil, err
+		}
+
+		lease, err := p.newLease(pb)
+		if err == nil {
+			return lease, nil
+		}
+		lastErr = err
+
+		// Gagal membuat context/page berarti browser rusak; daur ulang lalu coba lagi
+		p.mu.Lock()
+		pb.active--
+		p.markCrashedLocked(pb)
+		p.mu.Unlock()
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	<-p.sem
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return nil, fmt.Errorf("failed to acquire page: %w", lastErr)
+}
+
+// Release mengembalikan lease ke pool asalnya, sama dengan Pool.Release
+func (l *Lease) Release() error {
+	return l.pool.Release(l)
+}
+
+// Release mengembalikan lease ke pool dan menghapus incognito context-nya.
+// Release kedua kali untuk lease yang sama tidak melakukan apa-apa.
+func (p *Pool) Release(lease *Lease) error {
+	p.mu.Lock()
+	if lease == nil || lease.released {
+		p.mu.Unlock()
+		return nil
+	}
+	lease.released = true
+	closed := lease.browser.closed
+	p.mu.Unlock()
+
+	var err error
+	if !closed {
+		err = lease.incog.Close()
+	}
+
+	p.mu.Lock()
+	pb := lease.browser
+	pb.active--
+	p.stats.InUse--
+	if err != nil && !pb.closed {
+		// Context tidak bisa dihapus, anggap browser crash
+		p.markCrashedLocked(pb)
+	} else if pb.retired && pb.active == 0 {
+		p.closeBrowserLocked(pb)
+	}
+	p.mu.Unlock()
+
+	<-p.sem
+	if err != nil && !closed {
+		return fmt.Errorf("failed to release page: %w", err)
+	}
+	return nil
+}
+
+// Stats mengembalikan salinan statistik pool saat ini
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := p.stats
+	stats.Browsers = len(p.retiring)
+	for _, slot := range p.slots {
+		if slot.browser != nil {
+			stats.Browsers++
+		}
+	}
+	return stats
+}
+
+// Close menutup semua browser. Lease yang masih dipakai menjadi tidak valid.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	var browsers []*pooledBrowser
+	for _, slot := range p.slots {
+		if slot.browser != nil {
+			browsers = append(browsers, slot.browser)
+			slot.browser = nil
+		}
+	}
+	for pb := range p.retiring {
+		browsers = append(browsers, pb)
+	}
+	p.retiring = map[*pooledBrowser]bool{}
+	var toClose []*pooledBrowser
+	for _, pb := range browsers {
+		if !pb.closed {
+			pb.closed = true
+			toClose = append(toClose, pb)
+		}
+	}
+	p.mu.Unlock()
+
+	// Tunggu semua proses Chrome berhenti sebelum kembali
+	var wg sync.WaitGroup
+	for _, pb := range toClose {
+		wg.Add(1)
+		go func(pb *pooledBrowser) {
+			defer wg.Done()
+			pb.shutdown()
+		}(pb)
+	}
+	wg.Wait()
+
+	p.cancel()
+	p.cm.logger("pool_status", "closed")
+	return nil
+}
+
+// pick memilih browser dengan lease paling sedikit, atau menjalankan browser
+// baru di slot kosong. Pemanggil harus sudah memegang sem.
+func (p *Pool) pick(ctx context.Context) (*pooledBrowser, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+
+		var best *pooledBrowser
+		for _, slot := range p.slots {
+			pb := slot.browser
+			if pb == nil || pb.active >= p.opts.ContextsPerBrowser {
+				continue
+			}
+			if best == nil || pb.active < best.active {
+				best = pb
+			}
+		}
+		if best != nil {
+			best.active++
+			best.uses++
+			if p.opts.MaxUsesPerBrowser > 0 && best.uses >= p.opts.MaxUsesPerBrowser {
+				p.retireLocked(best)
+				p.stats.Recycled++
+				p.cm.logger("pool_browser_recycled", strconv.Itoa(best.id))
+			}
+			p.mu.Unlock()
+			return best, nil
+		}
+
+		// Tidak ada browser yang punya slot kosong: jalankan browser baru
+		// di slot kosong, atau tunggu browser yang sedang dijalankan
+		var wait *slotLaunch
+		for _, slot := range p.slots {
+			if slot.browser != nil {
+				continue
+			}
+			if slot.launching == nil {
+				slot.launching = &slotLaunch{done: make(chan struct{})}
+				go p.launchSlot(slot, slot.launching)
+			}
+			wait = slot.launching
+			break
+		}
+		p.mu.Unlock()
+
+		if wait == nil {
+			// Tidak mungkin terjadi selama sem dipegang, tapi jangan busy loop
+			return nil, fmt.Errorf("no browser slot available")
+		}
+		select {
+		case <-wait.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if wait.err != nil {
+			return nil, wait.err
+		}
+	}
+}
+
+// launchSlot menjalankan browser untuk slot di background agar pemanggil
+// Acquire yang batal tidak membatalkan launch untuk pemanggil lain
+func (p *Pool) launchSlot(slot *poolSlot, launch *slotLaunch) {
+	browser, process, err := p.cm.launch(p.ctx, p.ctx, p.launch)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err == nil && p.closed {
+		browser.Close()
+		process.Kill()
+		err = ErrPoolClosed
+	}
+	if err != nil {
+		launch.err = err
+		p.cm.logger("pool_launch_error", err.Error())
+	} else {
+		p.nextID++
+		pb := &pooledBrowser{id: p.nextID, browser: browser, process: process}
+		slot.browser = pb
+		p.stats.Launched++
+		p.cm.logger("pool_browser_launched", strconv.Itoa(pb.id))
+		go p.watch(pb)
+	}
+	slot.launching = nil
+	close(launch.done)
+}
+
+// watch menandai browser crash saat proses keluar atau koneksi DevTools terputus
+func (p *Pool) watch(pb *pooledBrowser) {
+	select {
+	case <-pb.process.Exited():
+	case <-watchDisconnect(pb.browser):
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !pb.closed {
+		p.markCrashedLocked(pb)
+	}
+}
+
+// newLease membuat incognito context dan page di browser terpilih
+func (p *Pool) newLease(pb *pooledBrowser) (*Lease, error) {
+	incog, err := pb.browser.Incognito()
+	if err != nil {
+		return nil, err
+	}
+	page, err := incog.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		incog.Close()
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.stats.InUse++
+	p.stats.Acquired++
+	p.mu.Unlock()
+
+	return &Lease{Page: page, pool: p, browser: pb, incog: incog}, nil
+}
+
+// retireLocked melepas browser dari slot-nya sehingga slot bisa diisi browser
+// baru; browser lama ditutup setelah lease terakhirnya di-Release
+func (p *Pool) retireLocked(pb *pooledBrowser) {
+	if pb.retired {
+		return
+	}
+	pb.retired = true
+	for _, slot := range p.slots {
+		if slot.browser == pb {
+			slot.browser = nil
+		}
+	}
+	if pb.active == 0 {
+		p.closeBrowserLocked(pb)
+		return
+	}
+	p.retiring[pb] = true
+}
+
+// markCrashedLocked mendaur ulang browser yang crash
+func (p *Pool) markCrashedLocked(pb *pooledBrowser) {
+	if pb.closed {
+		return
+	}
+	p.stats.Crashed++
+	p.cm.logger("pool_browser_crashed", strconv.Itoa(pb.id))
+	p.retireLocked(pb)
+	// Lease yang masih aktif di browser ini sudah tidak valid
+	p.closeBrowserLocked(pb)
+}
+
+// closeBrowserLocked menutup proses Chrome tanpa menunggu
+func (p *Pool) closeBrowserLocked(pb *pooledBrowser) {
+	if pb.closed {
+		return
+	}
+	pb.closed = true
+	delete(p.retiring, pb)
+	go pb.shutdown()
+}
+
+// shutdown menutup browser lewat DevTools lalu memastikan prosesnya mati
+func (pb *pooledBrowser) shutdown() {
+	pb.browser.Close()
+	pb.process.Kill()
+}
+
+// watchDisconnect mengembalikan channel yang ditutup saat koneksi DevTools
+// browser terputus (proses Chrome keluar atau crash)
+func watchDisconnect(b *rod.Browser) <-chan struct{} {
+	done := make(chan struct{})
+	events := b.Event()
+	go func() {
+		defer close(done)
+		for range events {
+		}
+	}()
+	return done
+}
@@ -0,0 +1,155 @@
+package browser_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// newTestPool membuat pool yang menjalankan fake Chrome milik cdp
+func newTestPool(t *testing.T, cdp *fakeCDP, opts browser.PoolOptions) *browser.Pool {
+	t.Helper()
+	mgr := installFakeChrome(t, cdp.Script())
+	launch := browser.LaunchOptions{Sandbox: browser.SandboxOff}
+	opts.Launch = &launch
+	pool, err := browser.NewPool(context.Background(), mgr, opts)
+	if err != nil {
+		t.Fatalf("NewPool failed: %v", err)
+	}
+	t.Cleanup(func() { pool.Close() })
+	return pool
+}
+
+// waitFor menunggu kondisi terpenuhi atau gagal setelah timeout
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestPoolLeasesUpToCapacity(t *testing.T) {
+	cdp := newFakeCDP(t)
+	pool := newTestPool(t, cdp, browser.PoolOptions{Browsers: 2, ContextsPerBrowser: 2})
+
+	var leases []*browser.Lease
+	for i := 0; i < 4; i++ {
+		lease, err := pool.Acquire(context.Background())
+		if err != nil {
+			t.Fatalf("Acquire %d failed: %v", i, err)
+		}
+		leases = append(leases, lease)
+	}
+
+	stats := pool.Stats()
+	if stats.InUse != 4 || stats.Browsers != 2 || stats.Capacity != 4 {
+		t.Errorf("unexpected stats with full pool: %+v", stats)
+	}
+	if got := cdp.Calls("Target.createBrowserContext"); got != 4 {
+		t.Errorf("each lease should get its own incognito context, got %d", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire on full pool should wait for ctx, got %v", err)
+	}
+
+	if err := leases[0].Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if err := pool.Release(leases[0]); err != nil {
+		t.Errorf("double Release should be a no-op, got %v", err)
+	}
+	if got := cdp.Calls("Target.disposeBrowserContext"); got != 1 {
+		t.Errorf("Release should dispose the incognito context, got %d", got)
+	}
+
+	lease, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire after Release failed: %v", err)
+	}
+	pool.Release(lease)
+	for _, lease := range leases[1:] {
+		pool.Release(lease)
+	}
+
+	stats = pool.Stats()
+	if stats.InUse != 0 || stats.Waiting != 0 || stats.Acquired != 5 || stats.Launched != 2 {
+		t.Errorf("unexpected stats after release: %+v", stats)
+	}
+}
+
+func TestPoolRecyclesAfterMaxUses(t *testing.T) {
+	cdp := newFakeCDP(t)
+	pool := newTestPool(t, cdp, browser.PoolOptions{Browsers: 1, ContextsPerBrowser: 1, MaxUsesPerBrowser: 2})
+
+	for i := 0; i < 3; i++ {
+		lease, err := pool.Acquire(context.Background())
+		if err != nil {
+			t.Fatalf("Acquire %d failed: %v", i, err)
+		}
+		if err := lease.Release(); err != nil {
+			t.Fatalf("Release %d failed: %v", i, err)
+		}
+	}
+
+	stats := pool.Stats()
+	if stats.Launched != 2 || stats.Recycled != 1 || stats.Crashed != 0 {
+		t.Errorf("expected one recycle after two uses: %+v", stats)
+	}
+	if stats.Browsers != 1 {
+		t.Errorf("recycled browser should be closed, got %d running", stats.Browsers)
+	}
+	waitFor(t, "recycled browser to close", func() bool { return cdp.Calls("Browser.close") == 1 })
+}
+
+func TestPoolReplacesCrashedBrowser(t *testing.T) {
+	cdp := newFakeCDP(t)
+	pool := newTestPool(t, cdp, browser.PoolOptions{Browsers: 1, ContextsPerBrowser: 2})
+
+	lease, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	cdp.Drop(0)
+	waitFor(t, "crash to be detected", func() bool { return pool.Stats().Crashed == 1 })
+
+	// Lease dari browser yang crash tetap bisa dikembalikan tanpa error
+	if err := lease.Release(); err != nil {
+		t.Errorf("Release of crashed lease failed: %v", err)
+	}
+
+	lease, err = pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire after crash failed: %v", err)
+	}
+	defer lease.Release()
+
+	if got := cdp.Connections(); got != 2 {
+		t.Errorf("expected a replacement browser, got %d connections", got)
+	}
+	if stats := pool.Stats(); stats.Launched != 2 || stats.Browsers != 1 {
+		t.Errorf("unexpected stats after crash: %+v", stats)
+	}
+}
+
+func TestPoolClosed(t *testing.T) {
+	cdp := newFakeCDP(t)
+	pool := newTestPool(t, cdp, browser.PoolOptions{})
+
+	if err := pool.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Acquire(context.Background()); !errors.Is(err, browser.ErrPoolClosed) {
+		t.Errorf("expected ErrPoolClosed, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package browser
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+// chromeProcess adalah proses Chrome yang dijalankan langsung oleh package ini
+// (bukan lewat launcher.Launch) agar exit status-nya bisa diamati
+type chromeProcess struct {
+	cmd    *exec.Cmd
+	stderr *stderrBuffer
+
+	// exited ditutup setelah proses keluar; waitErr valid setelahnya
+	exited  chan struct{}
+	waitErr error
+
+	// tempDataDir dihapus setelah proses keluar
+	tempDataDir string
+
+	killOnce sync.Once
+}
+
+// startChrome menjalankan Chrome dengan flag dari l dan menunggu URL DevTools.
+// ctx hanya membatasi proses start; lifetime mematikan proses saat selesai.
+func startChrome(ctx, lifetime context.Context, bin string, l *launcher.Launcher, env []string, stderr *stderrBuffer, tempDataDir string) (*chromeProcess, string, error) {
+	// Output dibaca lewat pipe sendiri agar cmd.Wait tidak menunggu proses
+	// anak Chrome yang mewarisi stdout/stderr
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, "", err
+	}
+
+	cmd := exec.Command(bin, l.FormatArgs()...)
+	cmd.Env = env
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	setupProcess(cmd)
+
+	if err := cmd.Start(); err != nil {
+		pr.Close()
+		pw.Close()
+		return nil, "", err
+	}
+	pw.Close()
+
+	p := &chromeProcess{
+		cmd:         cmd,
+		stderr:      stderr,
+		exited:      make(chan struct{}),
+		tempDataDir: tempDataDir,
+	}
+
+	parserCtx, cancelParser := context.WithCancel(ctx)
+	defer cancelParser()
+	parser := launcher.NewURLParser().Context(parserCtx)
+
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		io.Copy(io.MultiWriter(stderr, parser), pr)
+		pr.Close()
+	}()
+	go func() {
+		err := cmd.Wait()
+		// Tunggu sisa output agar stderr lengkap saat exited ditutup
+		<-copied
+		p.waitErr = err
+		if p.tempDataDir != "" {
+			os.RemoveAll(p.tempDataDir)
+		}
+		close(p.exited)
+	}()
+	context.AfterFunc(lifetime, p.Kill)
+
+	select {
+	case u := <-parser.URL:
+		resolved, err := launcher.ResolveURL(u)
+		if err != nil {
+			p.Kill()
+			return nil, "", err
+		}
+		return p, resolved, nil
+	case <-p.exited:
+		return nil, "", parser.Err()
+	case <-ctx.Done():
+		p.Kill()
+		return nil, "", ctx.Err()
+	}
+}
+
+// PID mengembalikan process ID Chrome
+func (p *chromeProcess) PID() int {
+	return p.cmd.Process.Pid
+}
+
+// Exited mengembalikan channel yang ditutup saat proses keluar
+func (p *chromeProcess) Exited() <-chan struct{} {
+	return p.exited
+}
+
+// ExitStatus mengembalikan status keluar, contoh "exit status 1" atau
+// "signal: killed". String kosong jika proses masih berjalan.
+func (p *chromeProcess) ExitStatus() string {
+	select {
+	case <-p.exited:
+	default:
+		return ""
+	}
+	if p.cmd.ProcessState != nil {
+		return p.cmd.ProcessState.String()
+	}
+	if p.waitErr != nil {
+		return p.waitErr.Error()
+	}
+	return ""
+}
+
+// Kill mematikan Chrome beserta proses anaknya
+func (p *chromeProcess) Kill() {
+	p.killOnce.Do(func() {
+		select {
+		case <-p.exited:
+			return
+		default:
+		}
+		killProcessGroup(p.cmd)
+	})
+}
@@ -0,0 +1,23 @@
+//go:build linux
+
+package browser
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setupProcess menjalankan Chrome di process group sendiri dan mematikannya
+// jika proses ini mati, pengganti guard leakless milik launcher rod
+func setupProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGKILL,
+	}
+}
+
+// killProcessGroup mematikan Chrome dan semua proses anaknya
+func killProcessGroup(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	cmd.Process.Kill()
+}
@@ -0,0 +1,19 @@
+//go:build !linux && !windows
+
+package browser
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setupProcess menjalankan Chrome di process group sendiri
+func setupProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup mematikan Chrome dan semua proses anaknya
+func killProcessGroup(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	cmd.Process.Kill()
+}
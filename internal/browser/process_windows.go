@@ -0,0 +1,13 @@
+//go:build windows
+
+package browser
+
+import "os/exec"
+
+// setupProcess tidak butuh pengaturan khusus di Windows
+func setupProcess(cmd *exec.Cmd) {}
+
+// killProcessGroup mematikan proses Chrome utama
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
@@ -0,0 +1,207 @@
+package browser
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ReplayUnmatched menentukan perlakuan request yang tidak ada di HAR
+type ReplayUnmatched string
+
+const (
+	// ReplayFail menggagalkan request (default), cocok untuk test offline yang ketat
+	ReplayFail ReplayUnmatched = "fail"
+	// ReplayPassthrough meneruskan request ke jaringan
+	ReplayPassthrough ReplayUnmatched = "passthrough"
+	// ReplayNotFound menjawab dengan 404
+	ReplayNotFound ReplayUnmatched = "404"
+)
+
+// replaySkippedHeaders tidak disalin dari HAR karena body di HAR sudah
+// didekode; Content-Length ditulis ulang sesuai body yang dikirim
+var replaySkippedHeaders = map[string]bool{
+	"content-encoding":  true,
+	"content-length":    true,
+	"transfer-encoding": true,
+}
+
+// ReplayOptions mengatur HARReplayer
+type ReplayOptions struct {
+	// MatchBody mewajibkan body request sama dengan postData di HAR
+	MatchBody bool
+	// Unmatched menentukan perlakuan request yang tidak cocok; kosong berarti ReplayFail
+	Unmatched ReplayUnmatched
+}
+
+// ReplayStats berisi jumlah request yang dijawab dari HAR dan yang tidak cocok
+type ReplayStats struct {
+	Matched   int
+	Unmatched int
+}
+
+// HARReplayer menjawab request dari rekaman HAR. Request dicocokkan
+// berdasarkan method dan URL (tanpa fragment), dan body jika MatchBody aktif.
+// Jika request yang sama direkam beberapa kali, response diputar sesuai
+// urutan rekaman lalu response terakhir diulang.
+type HARReplayer struct {
+	opts ReplayOptions
+
+	mu      sync.Mutex
+	entries map[string][]*HAREntry
+	served  map[*HAREntry]bool
+	stats   ReplayStats
+}
+
+// NewHARReplayer membuat replayer dari HAR. Entry yang gagal atau tidak punya
+// response (status 0) tidak dipakai.
+func NewHARReplayer(h *HAR, opts ReplayOptions) (*HARReplayer, error) {
+	switch opts.Unmatched {
+	case "":
+		opts.Unmatched = ReplayFail
+	case ReplayFail, ReplayPassthrough, ReplayNotFound:
+	default:
+		return nil, fmt.Errorf("invalid unmatched mode %q", opts.Unmatched)
+	}
+	r := &HARReplayer{
+		opts:    opts,
+		entries: map[string][]*HAREntry{},
+		served:  map[*HAREntry]bool{},
+	}
+	for i := range h.Log.Entries {
+		entry := &h.Log.Entries[i]
+		if entry.Error != "" || entry.Response.Status == 0 {
+			continue
+		}
+		key := replayKey(entry.Request.Method, entry.Request.URL)
+		r.entries[key] = append(r.entries[key], entry)
+	}
+	return r, nil
+}
+
+// LoadHARReplayer membaca file HAR lalu membuat replayer
+func LoadHARReplayer(path string, opts ReplayOptions) (*HARReplayer, error) {
+	h, err := ReadHAR(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewHARReplayer(h, opts)
+}
+
+// Match mencari entry HAR untuk request
+func (r *HARReplayer) Match(method, rawURL, body string) (*HAREntry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var candidates []*HAREntry
+	for _, entry := range r.entries[replayKey(method, rawURL)] {
+		if r.opts.MatchBody && entry.Request.PostData != nil && entry.Request.PostData.Text != body {
+			continue
+		}
+		candidates = append(candidates, entry)
+	}
+	if len(candidates) == 0 {
+		r.stats.Unmatched++
+		return nil, false
+	}
+	r.stats.Matched++
+	for _, entry := range candidates {
+		if !r.served[entry] {
+			r.served[entry] = true
+			return entry, true
+		}
+	}
+	return candidates[len(candidates)-1], true
+}
+
+// Stats mengembalikan jumlah request yang cocok dan tidak cocok
+func (r *HARReplayer) Stats() ReplayStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// AttachReplay menjawab request dari HAR untuk browser tempat page berada
+// (semua page browser tersebut). Hanya satu interception per browser: error
+// dikembalikan jika browser sudah memakai Policy, NetworkGuard, Blocker atau
+// Replay dari LaunchOptions atau attach lain. Panggil Stop pada router yang
+// dikembalikan untuk melepasnya.
+func (cm *ChromiumManager) AttachReplay(page *rod.Page, r *HARReplayer) (*rod.HijackRouter, error) {
+	router, err := startInterception(page.Browser(), []requestHandler{r.handler(cm.logger)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach har replay: %w", err)
+	}
+	cm.logger("har_replay_attached", fmt.Sprintf("unmatched=%s match_body=%t", r.opts.Unmatched, r.opts.MatchBody))
+	return router, nil
+}
+
+// handler mengembalikan requestHandler yang menjawab request dari HAR
+func (r *HARReplayer) handler(logger func(key, value string)) requestHandler {
+	return func(h *rod.Hijack) bool {
+		method := h.Request.Method()
+		rawURL := h.Request.URL().String()
+		entry, ok := r.Match(method, rawURL, h.Request.Body())
+		if ok {
+			if err := fulfillFromHAR(h, entry); err != nil {
+				logger("har_replay_error", fmt.Sprintf("url=%s error=%v", rawURL, err))
+				h.Response.Fail(proto.NetworkErrorReasonFailed)
+			}
+			return true
+		}
+
+		logger("har_replay_unmatched", fmt.Sprintf("mode=%s method=%s url=%s", r.opts.Unmatched, method, rawURL))
+		switch r.opts.Unmatched {
+		case ReplayPassthrough:
+			return false
+		case ReplayNotFound:
+			h.Response.Payload().ResponseCode = http.StatusNotFound
+			h.Response.SetHeader("Content-Type", "text/plain; charset=utf-8")
+			h.Response.SetBody("not found in HAR: " + method + " " + rawURL + "\n")
+		default:
+			h.Response.Fail(proto.NetworkErrorReasonInternetDisconnected)
+		}
+		return true
+	}
+}
+
+// fulfillFromHAR mengisi response hijack dari entry HAR
+func fulfillFromHAR(h *rod.Hijack, entry *HAREntry) error {
+	res := entry.Response
+	payload := h.Response.Payload()
+	payload.ResponseCode = res.Status
+	payload.ResponsePhrase = res.StatusText
+	for _, header := range res.Headers {
+		if !replaySkippedHeaders[strings.ToLower(header.Name)] {
+			payload.ResponseHeaders = append(payload.ResponseHeaders, &proto.FetchHeaderEntry{Name: header.Name, Value: header.Value})
+		}
+	}
+
+	body := []byte(res.Content.Text)
+	if res.Content.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(res.Content.Text)
+		if err != nil {
+			return fmt.Errorf("invalid base64 body: %w", err)
+		}
+		body = decoded
+	}
+	payload.Body = body
+	payload.ResponseHeaders = append(payload.ResponseHeaders, &proto.FetchHeaderEntry{Name: "Content-Length", Value: strconv.Itoa(len(body))})
+	return nil
+}
+
+// replayKey menormalkan method dan URL untuk pencocokan
+func replayKey(method, rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		u.Fragment = ""
+		u.RawFragment = ""
+		rawURL = u.String()
+	}
+	return strings.ToUpper(method) + " " + rawURL
+}
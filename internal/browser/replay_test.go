@@ -0,0 +1,184 @@
+package browser_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// replayFixture adalah HAR kecil dengan request berulang, POST dan request gagal
+func replayFixture() *browser.HAR {
+	entry := func(method, url, body string, status int, postData string) browser.HAREntry {
+		e := browser.HAREntry{
+			Request: browser.HARRequest{Method: method, URL: url},
+			Response: browser.HARResponse{
+				Status:     status,
+				StatusText: "OK",
+				Headers: []browser.HARNameValue{
+					{Name: "Content-Type", Value: "text/html"},
+					{Name: "Content-Encoding", Value: "gzip"},
+				},
+				Content: browser.HARContent{MimeType: "text/html", Text: body},
+			},
+		}
+		if postData != "" {
+			e.Request.PostData = &browser.HARPostData{Text: postData}
+		}
+		return e
+	}
+	failed := entry("GET", "https://example.com/broken", "", 0, "")
+	failed.Error = "net::ERR_FAILED"
+	return &browser.HAR{Log: browser.HARLog{Version: "1.2", Entries: []browser.HAREntry{
+		entry("GET", "https://example.com/", "first", 200, ""),
+		entry("GET", "https://example.com/", "second", 200, ""),
+		entry("POST", "https://example.com/api", `{"ok":true}`, 201, "a=1"),
+		failed,
+	}}}
+}
+
+func TestHARReplayerMatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.har")
+	if err := replayFixture().WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	r, err := browser.LoadHARReplayer(path, browser.ReplayOptions{MatchBody: true})
+	if err != nil {
+		t.Fatalf("LoadHARReplayer failed: %v", err)
+	}
+
+	for _, want := range []string{"first", "second", "second"} {
+		entry, ok := r.Match("get", "https://example.com/#top", "")
+		if !ok || entry.Response.Content.Text != want {
+			t.Fatalf("Match = %v, %v; want %q", entry, ok, want)
+		}
+	}
+	if _, ok := r.Match("POST", "https://example.com/api", "a=1"); !ok {
+		t.Error("POST with recorded body should match")
+	}
+	if _, ok := r.Match("POST", "https://example.com/api", "a=2"); ok {
+		t.Error("POST with different body must not match when MatchBody is set")
+	}
+	if _, ok := r.Match("GET", "https://example.com/broken", ""); ok {
+		t.Error("failed entries must not be replayed")
+	}
+	if stats := r.Stats(); stats.Matched != 4 || stats.Unmatched != 2 {
+		t.Errorf("Stats = %+v", stats)
+	}
+
+	if _, err := browser.NewHARReplayer(replayFixture(), browser.ReplayOptions{Unmatched: "ignore"}); err == nil {
+		t.Error("invalid unmatched mode should be rejected")
+	}
+}
+
+func TestHARReplayIntercept(t *testing.T) {
+	tests := []struct {
+		mode          browser.ReplayUnmatched
+		failed        int
+		continued     int
+		unmatchedCode int
+	}{
+		{browser.ReplayFail, 1, 0, 0},
+		{browser.ReplayPassthrough, 0, 1, 0},
+		{browser.ReplayNotFound, 0, 0, 404},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			replayer, err := browser.NewHARReplayer(replayFixture(), browser.ReplayOptions{Unmatched: tt.mode})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var mu sync.Mutex
+			var fulfilled []map[string]any
+			cdp := newFakeCDP(t)
+			cdp.Handle("Fetch.fulfillRequest", func(params json.RawMessage) (any, error) {
+				var p map[string]any
+				json.Unmarshal(params, &p)
+				mu.Lock()
+				fulfilled = append(fulfilled, p)
+				mu.Unlock()
+				return map[string]any{}, nil
+			})
+			mgr := installFakeChrome(t, cdp.Script())
+			b, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{
+				Sandbox: browser.SandboxOff,
+				Replay:  replayer,
+			})
+			if err != nil {
+				t.Fatalf("GetBrowserWithOptions failed: %v", err)
+			}
+			defer b.Close()
+
+			cdp.Emit("Fetch.requestPaused", "", pausedRequest("r1", "https://example.com/", "Document"))
+			waitFor(t, "recorded request to be fulfilled", func() bool { return cdp.Calls("Fetch.fulfillRequest") == 1 })
+			cdp.Emit("Fetch.requestPaused", "", pausedRequest("r2", "https://example.com/missing.js", "Script"))
+
+			want := 1
+			if tt.unmatchedCode != 0 {
+				want = 2
+			}
+			waitFor(t, "unmatched request to be handled", func() bool {
+				return cdp.Calls("Fetch.failRequest") == tt.failed &&
+					cdp.Calls("Fetch.continueRequest") == tt.continued &&
+					cdp.Calls("Fetch.fulfillRequest") == want
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			body, _ := base64.StdEncoding.DecodeString(fulfilled[0]["body"].(string))
+			if string(body) != "first" || fulfilled[0]["responseCode"].(float64) != 200 {
+				t.Errorf("unexpected replayed response: %v (body %q)", fulfilled[0], body)
+			}
+			for _, h := range fulfilled[0]["responseHeaders"].([]any) {
+				if h.(map[string]any)["name"] == "Content-Encoding" {
+					t.Error("Content-Encoding must not be replayed for decoded bodies")
+				}
+			}
+			if tt.unmatchedCode != 0 && fulfilled[1]["responseCode"].(float64) != float64(tt.unmatchedCode) {
+				t.Errorf("unmatched response code = %v", fulfilled[1]["responseCode"])
+			}
+		})
+	}
+}
+
+// Test AttachReplay memakai interception browser bersama dan ditolak jika
+// browser sudah memakai interception lain
+func TestAttachReplayUsesSharedInterception(t *testing.T) {
+	replayer, err := browser.NewHARReplayer(replayFixture(), browser.ReplayOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cdp := newFakeCDP(t)
+	mgr := installFakeChrome(t, cdp.Script())
+	b, err := mgr.GetBrowserWithOptions(context.Background(), browser.LaunchOptions{Sandbox: browser.SandboxOff})
+	if err != nil {
+		t.Fatalf("GetBrowserWithOptions failed: %v", err)
+	}
+	defer b.Close()
+	page, err := b.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		t.Fatalf("Page failed: %v", err)
+	}
+
+	router, err := mgr.AttachReplay(page, replayer)
+	if err != nil {
+		t.Fatalf("AttachReplay failed: %v", err)
+	}
+	defer router.Stop()
+	cdp.Emit("Fetch.requestPaused", "", pausedRequest("r1", "https://example.com/", "Document"))
+	waitFor(t, "recorded request to be fulfilled", func() bool { return cdp.Calls("Fetch.fulfillRequest") == 1 })
+
+	if _, err := mgr.AttachReplay(page, replayer); err == nil {
+		t.Error("second AttachReplay on the same browser should fail")
+	}
+	if _, err := mgr.AttachBlocker(page, &browser.Blocker{}); err == nil {
+		t.Error("AttachBlocker should fail while replay intercepts requests")
+	}
+}
@@ -0,0 +1,445 @@
+package browser
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// repoMetadataLimit membatasi ukuran repomd.xml dan index yang dimuat ke memori
+const repoMetadataLimit = 256 << 20
+
+// RPMSource adalah repository RPM (yum/dnf) tempat dependency diresolve
+// dari RPMPackage.Name. Hanya package yang diminta yang diambil; dependency
+// transitif tidak diresolve.
+type RPMSource struct {
+	// Repos berisi URL repository (direktori yang berisi repodata/).
+	// "$releasever" diganti VERSION_ID os-release (bagian mayor untuk
+	// turunan RHEL) dan "$basearch" diganti arsitektur RPM.
+	Repos []string
+}
+
+// APKSource adalah repository Alpine tempat dependency diresolve dari
+// APKPackage.Name. Hanya package yang diminta yang diambil.
+type APKSource struct {
+	// Repos berisi URL repository, contoh
+	// "https://dl-cdn.alpinelinux.org/alpine/$branch/main". "$branch" diganti
+	// "v<mayor>.<minor>" dari VERSION_ID os-release.
+	Repos []string
+	// Arch arsitektur Alpine; kosong berarti sesuai runtime.GOARCH
+	Arch string
+}
+
+// defaultRPMSources mengembalikan repository resmi Fedora dan Rocky Linux
+// (untuk RHEL dan turunannya)
+func defaultRPMSources() map[string]RPMSource {
+	return map[string]RPMSource{
+		"fedora": {Repos: []string{
+			"https://dl.fedoraproject.org/pub/fedora/linux/releases/$releasever/Everything/$basearch/os",
+		}},
+		"rhel": {Repos: []string{
+			"https://dl.rockylinux.org/pub/rocky/$releasever/BaseOS/$basearch/os",
+			"https://dl.rockylinux.org/pub/rocky/$releasever/AppStream/$basearch/os",
+		}},
+	}
+}
+
+// defaultAPKSources mengembalikan repository resmi Alpine
+func defaultAPKSources() map[string]APKSource {
+	return map[string]APKSource{
+		"alpine": {Repos: []string{
+			"https://dl-cdn.alpinelinux.org/alpine/$branch/main",
+			"https://dl-cdn.alpinelinux.org/alpine/$branch/community",
+		}},
+	}
+}
+
+// repoPackage adalah lokasi satu package dari index repository RPM/APK
+type repoPackage struct {
+	Name    string
+	Version string
+	URL     string
+	// SHA256 kosong jika index tidak memuatnya (APKINDEX)
+	SHA256 string
+	Size   int64
+}
+
+// rpmArch menerjemahkan GOARCH ke arsitektur RPM
+func rpmArch(goarch string) string {
+	switch goarch {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	case "386":
+		return "i686"
+	}
+	return goarch
+}
+
+// apkArch menerjemahkan GOARCH ke arsitektur Alpine
+func apkArch(goarch string) string {
+	switch goarch {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	case "386":
+		return "x86"
+	case "arm":
+		return "armv7"
+	}
+	return goarch
+}
+
+// repoNames mengembalikan nama package RPM/APK untuk dependency yang belum
+// terinstall (kosong jika dependency tidak punya Name untuk format ini)
+func (dm *DependencyManager) repoNames(format string, dependencies []Dependency) map[string]string {
+	names := map[string]string{}
+	for _, dep := range dependencies {
+		if dm.isLibraryInstalled(dep.LibraryName) {
+			continue
+		}
+		switch {
+		case format == PackageRPM && dep.RPM != nil && dep.RPM.Name != "":
+			names[dep.Name] = dep.RPM.Name
+		case format == PackageAPK && dep.APK != nil && dep.APK.Name != "":
+			names[dep.Name] = dep.APK.Name
+		}
+	}
+	return names
+}
+
+// resolveRepoPackages mencari package RPM/APK setiap dependency di
+// repository osType. Hasilnya dipetakan dari Dependency.Name; dependency
+// yang tidak ditemukan tidak ada di hasil.
+func (dm *DependencyManager) resolveRepoPackages(ctx context.Context, osType, format string, dependencies []Dependency) (map[string]*repoPackage, error) {
+	names := dm.repoNames(format, dependencies)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var index map[string]*repoPackage
+	var err error
+	switch format {
+	case PackageRPM:
+		source, ok := dm.RPMSources[osType]
+		if !ok {
+			return nil, nil
+		}
+		index, err = dm.fetchRPMIndex(ctx, osType, source)
+	case PackageAPK:
+		source, ok := dm.APKSources[osType]
+		if !ok {
+			return nil, nil
+		}
+		index, err = dm.fetchAPKIndex(ctx, source)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := map[string]*repoPackage{}
+	for depName, pkgName := range names {
+		if pkg, ok := index[pkgName]; ok {
+			resolved[depName] = pkg
+		}
+	}
+	return resolved, nil
+}
+
+// installRepoPackage mengunduh package hasil resolveRepoPackages, memverifikasi
+// ukuran dan SHA256 (jika ada di index) lalu mengekstraknya dengan backend
+func (dm *DependencyManager) installRepoPackage(ctx context.Context, backend PackageBackend, dep Dependency, pkg *repoPackage) error {
+	dm.logger("dependencies_url", pkg.URL)
+
+	tmpFile := filepath.Join(dm.libDir, fmt.Sprintf(".%s.%s", dep.Name, backend.Format()))
+	hasher := sha256.New()
+	if err := dm.downloader.DownloadContext(ctx, pkg.URL, tmpFile, hasher); err != nil {
+		return fmt.Errorf("failed to download: %w", err)
+	}
+	defer os.Remove(tmpFile)
+
+	actual := hex.EncodeToString(hasher.Sum(nil))
+	if pkg.SHA256 != "" && actual != pkg.SHA256 {
+		return &ChecksumMismatchError{URL: pkg.URL, Expected: pkg.SHA256, Actual: actual}
+	}
+	if pkg.Size > 0 {
+		info, err := os.Stat(tmpFile)
+		if err != nil {
+			return err
+		}
+		if info.Size() != pkg.Size {
+			return fmt.Errorf("%s: size %d, index says %d", pkg.URL, info.Size(), pkg.Size)
+		}
+	}
+	return dm.extractPackage(ctx, backend, tmpFile, dep.Name)
+}
+
+// releaseVer mengembalikan VERSION_ID os-release; jika major true hanya
+// bagian sebelum titik pertama
+func (dm *DependencyManager) releaseVer(major bool) string {
+	version := dm.osRelease()["VERSION_ID"]
+	if major {
+		version, _, _ = strings.Cut(version, ".")
+	}
+	return version
+}
+
+// fetchRPMIndex membaca primary index setiap repository. Package di
+// repository pertama diutamakan.
+func (dm *DependencyManager) fetchRPMIndex(ctx context.Context, osType string, source RPMSource) (map[string]*repoPackage, error) {
+	arch := rpmArch(runtime.GOARCH)
+	releasever := dm.releaseVer(osType == "rhel")
+	if releasever == "" {
+		return nil, fmt.Errorf("rpm repository requires VERSION_ID in os-release")
+	}
+
+	index := map[string]*repoPackage{}
+	for _, repo := range source.Repos {
+		base := strings.NewReplacer("$releasever", releasever, "$basearch", arch).Replace(strings.TrimSuffix(repo, "/"))
+		primaryURL, want, err := dm.fetchRepomd(ctx, base)
+		if err != nil {
+			return nil, err
+		}
+		data, err := dm.fetchRepoFile(ctx, primaryURL, repoMetadataLimit)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		if actual := hex.EncodeToString(sum[:]); actual != want {
+			return nil, &ChecksumMismatchError{URL: primaryURL, Expected: want, Actual: actual}
+		}
+		r, err := decompressByName(primaryURL, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", primaryURL, err)
+		}
+		err = parseRPMPrimary(r, base, arch, index)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", primaryURL, err)
+		}
+		dm.logger("dependencies_rpm_index", primaryURL)
+	}
+	return index, nil
+}
+
+// fetchRepomd membaca repodata/repomd.xml dan mengembalikan URL serta
+// SHA256 index primary
+func (dm *DependencyManager) fetchRepomd(ctx context.Context, base string) (string, string, error) {
+	url := base + "/repodata/repomd.xml"
+	data, err := dm.fetchRepoFile(ctx, url, repoMetadataLimit)
+	if err != nil {
+		return "", "", err
+	}
+	var repomd struct {
+		Data []struct {
+			Type     string `xml:"type,attr"`
+			Checksum struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"checksum"`
+			Location struct {
+				Href string `xml:"href,attr"`
+			} `xml:"location"`
+		} `xml:"data"`
+	}
+	if err := xml.Unmarshal(data, &repomd); err != nil {
+		return "", "", fmt.Errorf("%s: %w", url, err)
+	}
+	for _, d := range repomd.Data {
+		if d.Type != "primary" {
+			continue
+		}
+		if d.Checksum.Type != "sha256" {
+			return "", "", fmt.Errorf("%s: unsupported primary checksum %q", url, d.Checksum.Type)
+		}
+		return base + "/" + strings.TrimPrefix(d.Location.Href, "/"), strings.ToLower(strings.TrimSpace(d.Checksum.Value)), nil
+	}
+	return "", "", fmt.Errorf("%s: no primary index", url)
+}
+
+// parseRPMPrimary menambahkan package arch (atau noarch) dari primary.xml ke
+// index; nama yang sudah ada tidak diganti
+func parseRPMPrimary(r io.Reader, base, arch string, index map[string]*repoPackage) error {
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "package" {
+			continue
+		}
+		var pkg struct {
+			Name    string `xml:"name"`
+			Arch    string `xml:"arch"`
+			Version struct {
+				Epoch string `xml:"epoch,attr"`
+				Ver   string `xml:"ver,attr"`
+				Rel   string `xml:"rel,attr"`
+			} `xml:"version"`
+			Checksum struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"checksum"`
+			Size struct {
+				Package int64 `xml:"package,attr"`
+			} `xml:"size"`
+			Location struct {
+				Href string `xml:"href,attr"`
+			} `xml:"location"`
+		}
+		if err := decoder.DecodeElement(&pkg, &start); err != nil {
+			return err
+		}
+		if (pkg.Arch != arch && pkg.Arch != "noarch") || pkg.Checksum.Type != "sha256" {
+			continue
+		}
+		if _, ok := index[pkg.Name]; ok {
+			continue
+		}
+		version := pkg.Version.Ver + "-" + pkg.Version.Rel
+		if pkg.Version.Epoch != "" && pkg.Version.Epoch != "0" {
+			version = pkg.Version.Epoch + ":" + version
+		}
+		index[pkg.Name] = &repoPackage{
+			Name:    pkg.Name,
+			Version: version,
+			URL:     base + "/" + strings.TrimPrefix(pkg.Location.Href, "/"),
+			SHA256:  strings.ToLower(strings.TrimSpace(pkg.Checksum.Value)),
+			Size:    pkg.Size.Package,
+		}
+	}
+}
+
+// fetchAPKIndex membaca APKINDEX.tar.gz setiap repository. Package di
+// repository pertama diutamakan.
+func (dm *DependencyManager) fetchAPKIndex(ctx context.Context, source APKSource) (map[string]*repoPackage, error) {
+	arch := source.Arch
+	if arch == "" {
+		arch = apkArch(runtime.GOARCH)
+	}
+	parts := strings.SplitN(dm.releaseVer(false), ".", 3)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("apk repository requires VERSION_ID in os-release")
+	}
+	branch := "v" + parts[0] + "." + parts[1]
+
+	index := map[string]*repoPackage{}
+	for _, repo := range source.Repos {
+		base := strings.ReplaceAll(strings.TrimSuffix(repo, "/"), "$branch", branch) + "/" + arch
+		url := base + "/APKINDEX.tar.gz"
+		data, err := dm.fetchRepoFile(ctx, url, repoMetadataLimit)
+		if err != nil {
+			return nil, err
+		}
+		if err := parseAPKIndexArchive(bytes.NewReader(data), base, index); err != nil {
+			return nil, fmt.Errorf("%s: %w", url, err)
+		}
+		dm.logger("dependencies_apk_index", url)
+	}
+	return index, nil
+}
+
+// parseAPKIndexArchive membaca file APKINDEX dari APKINDEX.tar.gz (stream
+// gzip signature dan index yang disambung) ke index
+func parseAPKIndexArchive(r io.Reader, base string, index map[string]*repoPackage) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("no APKINDEX in archive")
+		}
+		if err != nil {
+			return err
+		}
+		if header.Name == "APKINDEX" {
+			return parseAPKIndex(tr, base, index)
+		}
+	}
+}
+
+// parseAPKIndex membaca stanza APKINDEX ("P:nama", "V:versi", "S:ukuran")
+func parseAPKIndex(r io.Reader, base string, index map[string]*repoPackage) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	fields := map[string]string{}
+	flush := func() {
+		name, version := fields["P"], fields["V"]
+		if _, ok := index[name]; name != "" && version != "" && !ok {
+			size, _ := strconv.ParseInt(fields["S"], 10, 64)
+			index[name] = &repoPackage{
+				Name:    name,
+				Version: version,
+				URL:     base + "/" + name + "-" + version + ".apk",
+				Size:    size,
+			}
+		}
+		fields = map[string]string{}
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			fields[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	flush()
+	return nil
+}
+
+// decompressByName membuka data sesuai ekstensi url (.gz, .xz, .zst)
+func decompressByName(url string, data []byte) (io.ReadCloser, error) {
+	r := bytes.NewReader(data)
+	switch {
+	case strings.HasSuffix(url, ".gz"):
+		return gzip.NewReader(r)
+	case strings.HasSuffix(url, ".xz"):
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(xr), nil
+	case strings.HasSuffix(url, ".zst"):
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	}
+	return io.NopCloser(r), nil
+}
@@ -0,0 +1,338 @@
+package browser
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Struktur file RPM: lead 96 byte, header signature (rata 8 byte), header
+// utama, lalu payload cpio terkompresi
+const (
+	rpmLeadSize       = 96
+	rpmLeadMagic      = 0xedabeedb
+	rpmHeaderMagic    = 0x8eade801
+	rpmHeaderPreamble = 16
+	rpmIndexEntrySize = 16
+	rpmSignatureAlign = 8
+	rpmMaxIndexCount  = 0xffff
+	rpmMaxStoreSize   = 256 << 20
+)
+
+// Tag header RPM yang dibaca
+const (
+	rpmTagName          = 1000
+	rpmTagVersion       = 1001
+	rpmTagRelease       = 1002
+	rpmTagEpoch         = 1003
+	rpmTagArch          = 1022
+	rpmTagPayloadFormat = 1124
+)
+
+// Tipe data entry header RPM
+const (
+	rpmTypeInt32      = 4
+	rpmTypeString     = 6
+	rpmTypeI18NString = 9
+)
+
+// cpioNewcHeaderSize adalah ukuran header cpio newc tanpa nama
+const cpioNewcHeaderSize = 110
+
+// rpmBackend mengekstrak payload cpio dari .rpm dengan pure Go
+type rpmBackend struct {
+	logger func(key, value string)
+}
+
+func (b *rpmBackend) Format() string {
+	return PackageRPM
+}
+
+// PackageURL memakai RHELURL di RHEL dan turunannya (fallback ke FedoraURL)
+func (b *rpmBackend) PackageURL(dep Dependency, osType string) string {
+	if dep.RPM == nil {
+		return ""
+	}
+	if osType == "rhel" && dep.RPM.RHELURL != "" {
+		return dep.RPM.RHELURL
+	}
+	return dep.RPM.FedoraURL
+}
+
+func (b *rpmBackend) Extract(ctx context.Context, rpmPath, root string) (PackageInfo, error) {
+	file, err := os.Open(rpmPath)
+	if err != nil {
+		return PackageInfo{}, fmt.Errorf("failed to read rpm: %w", err)
+	}
+	defer file.Close()
+
+	info, err := readRPMHeaders(file)
+	if err != nil {
+		return PackageInfo{}, err
+	}
+
+	// Sisa file adalah payload; kompresi (gzip, xz, zstd, bzip2) dideteksi dari magic bytes
+	payload, format, closeReader, err := decompressReader(file)
+	if err != nil {
+		return info, fmt.Errorf("failed to read rpm payload: %w", err)
+	}
+	defer closeReader()
+	b.logger("dependencies_rpm_payload", format)
+
+	if _, err := extractTarTo(ctx, newCpioReader(payload), root, nil); err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+// readRPMHeaders memvalidasi lead, melewati header signature dan membaca
+// metadata dari header utama. r berada di awal payload setelahnya.
+func readRPMHeaders(r io.Reader) (PackageInfo, error) {
+	lead := make([]byte, rpmLeadSize)
+	if _, err := io.ReadFull(r, lead); err != nil {
+		return PackageInfo{}, fmt.Errorf("not an rpm package: %w", err)
+	}
+	if binary.BigEndian.Uint32(lead) != rpmLeadMagic {
+		return PackageInfo{}, fmt.Errorf("not an rpm package: bad lead magic")
+	}
+
+	// Header signature diikuti padding sampai kelipatan 8 byte
+	_, sigSize, err := readRPMHeader(r)
+	if err != nil {
+		return PackageInfo{}, fmt.Errorf("invalid rpm signature header: %w", err)
+	}
+	if pad := (rpmSignatureAlign - sigSize%rpmSignatureAlign) % rpmSignatureAlign; pad > 0 {
+		if _, err := io.CopyN(io.Discard, r, int64(pad)); err != nil {
+			return PackageInfo{}, fmt.Errorf("invalid rpm signature header: %w", err)
+		}
+	}
+
+	tags, _, err := readRPMHeader(r)
+	if err != nil {
+		return PackageInfo{}, fmt.Errorf("invalid rpm header: %w", err)
+	}
+	if format := tags[rpmTagPayloadFormat]; format != "" && format != "cpio" {
+		return PackageInfo{}, fmt.Errorf("unsupported rpm payload format %q", format)
+	}
+
+	version := tags[rpmTagVersion]
+	if release := tags[rpmTagRelease]; release != "" {
+		version += "-" + release
+	}
+	if epoch := tags[rpmTagEpoch]; epoch != "" && epoch != "0" {
+		version = epoch + ":" + version
+	}
+	info := PackageInfo{Name: tags[rpmTagName], Version: version, Architecture: tags[rpmTagArch]}
+	if info.Name == "" {
+		return info, fmt.Errorf("invalid rpm header: missing name")
+	}
+	return info, nil
+}
+
+// readRPMHeader membaca satu struktur header (preamble, index, store) dan
+// mengembalikan tag string/int32 yang dikenali beserta ukuran header
+func readRPMHeader(r io.Reader) (map[int]string, int, error) {
+	preamble := make([]byte, rpmHeaderPreamble)
+	if _, err := io.ReadFull(r, preamble); err != nil {
+		return nil, 0, err
+	}
+	if binary.BigEndian.Uint32(preamble) != rpmHeaderMagic {
+		return nil, 0, fmt.Errorf("bad header magic")
+	}
+	count := binary.BigEndian.Uint32(preamble[8:])
+	storeSize := binary.BigEndian.Uint32(preamble[12:])
+	if count > rpmMaxIndexCount || storeSize > rpmMaxStoreSize {
+		return nil, 0, fmt.Errorf("header too large (%d entries, %d bytes)", count, storeSize)
+	}
+
+	index := make([]byte, int(count)*rpmIndexEntrySize)
+	store := make([]byte, storeSize)
+	if _, err := io.ReadFull(r, index); err != nil {
+		return nil, 0, err
+	}
+	if _, err := io.ReadFull(r, store); err != nil {
+		return nil, 0, err
+	}
+
+	tags := map[int]string{}
+	for i := 0; i < int(count); i++ {
+		entry := index[i*rpmIndexEntrySize:]
+		tag := int(binary.BigEndian.Uint32(entry))
+		typ := binary.BigEndian.Uint32(entry[4:])
+		offset := binary.BigEndian.Uint32(entry[8:])
+		if offset >= storeSize {
+			continue
+		}
+		data := store[offset:]
+		switch typ {
+		case rpmTypeString, rpmTypeI18NString:
+			// I18NSTRING berisi beberapa string; yang pertama adalah default
+			if end := bytes.IndexByte(data, 0); end >= 0 {
+				tags[tag] = string(data[:end])
+			}
+		case rpmTypeInt32:
+			if len(data) >= 4 {
+				tags[tag] = strconv.FormatUint(uint64(binary.BigEndian.Uint32(data)), 10)
+			}
+		}
+	}
+	return tags, rpmHeaderPreamble + len(index) + len(store), nil
+}
+
+// cpioReader membaca arsip cpio format newc ("070701"/"070702") sebagai
+// entryReader sehingga bisa diekstrak dengan extractTarTo. Hardlink (nlink > 1)
+// hanya menyimpan data di entry terakhir; nama lain dengan inode yang sama
+// dijadikan symlink relatif ke entry tersebut.
+type cpioReader struct {
+	r         io.Reader
+	remaining int64
+	pad       int64
+	// pendingLinks berisi nama hardlink tanpa data per inode
+	pendingLinks map[uint64][]string
+	// queued berisi symlink pengganti hardlink yang belum dikembalikan
+	queued []*tar.Header
+}
+
+func newCpioReader(r io.Reader) *cpioReader {
+	return &cpioReader{r: r, pendingLinks: map[uint64][]string{}}
+}
+
+func (c *cpioReader) Next() (*tar.Header, error) {
+	if c.remaining+c.pad > 0 {
+		if _, err := io.CopyN(io.Discard, c.r, c.remaining+c.pad); err != nil {
+			return nil, fmt.Errorf("cpio: %w", io.ErrUnexpectedEOF)
+		}
+		c.remaining, c.pad = 0, 0
+	}
+	if len(c.queued) > 0 {
+		hdr := c.queued[0]
+		c.queued = c.queued[1:]
+		return hdr, nil
+	}
+
+	for {
+		hdr, ino, nlink, err := c.readHeader()
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg || nlink <= 1 {
+			return hdr, nil
+		}
+		if hdr.Size == 0 {
+			c.pendingLinks[ino] = append(c.pendingLinks[ino], hdr.Name)
+			continue
+		}
+		for _, name := range c.pendingLinks[ino] {
+			linkname, err := relativeLink(name, hdr.Name)
+			if err != nil {
+				return nil, err
+			}
+			c.queued = append(c.queued, &tar.Header{Name: name, Typeflag: tar.TypeSymlink, Linkname: linkname, ModTime: hdr.ModTime})
+		}
+		delete(c.pendingLinks, ino)
+		return hdr, nil
+	}
+}
+
+// readHeader membaca satu header newc beserta nama entry
+func (c *cpioReader) readHeader() (*tar.Header, uint64, uint64, error) {
+	buf := make([]byte, cpioNewcHeaderSize)
+	if _, err := io.ReadFull(c.r, buf); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, 0, 0, fmt.Errorf("cpio: missing trailer")
+		}
+		return nil, 0, 0, fmt.Errorf("cpio: %w", err)
+	}
+	magic := string(buf[:6])
+	if magic != "070701" && magic != "070702" {
+		return nil, 0, 0, fmt.Errorf("cpio: unsupported format %q", magic)
+	}
+
+	// 13 field hex 8 karakter: ino, mode, uid, gid, nlink, mtime, filesize,
+	// devmajor, devminor, rdevmajor, rdevminor, namesize, check
+	var fields [13]uint64
+	for i := range fields {
+		v, err := strconv.ParseUint(string(buf[6+i*8:14+i*8]), 16, 32)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("cpio: bad header field: %w", err)
+		}
+		fields[i] = v
+	}
+	ino, mode, nlink, mtime, size, nameSize := fields[0], fields[1], fields[4], fields[5], int64(fields[6]), fields[11]
+	if nameSize == 0 || nameSize > 4096 {
+		return nil, 0, 0, fmt.Errorf("cpio: bad name size %d", nameSize)
+	}
+
+	// Header + nama rata 4 byte, begitu juga data
+	name := make([]byte, nameSize+(4-(cpioNewcHeaderSize+nameSize)%4)%4)
+	if _, err := io.ReadFull(c.r, name); err != nil {
+		return nil, 0, 0, fmt.Errorf("cpio: %w", io.ErrUnexpectedEOF)
+	}
+	entryName := string(bytes.TrimRight(name[:nameSize], "\x00"))
+	if entryName == "TRAILER!!!" {
+		return nil, 0, 0, io.EOF
+	}
+	c.remaining = size
+	c.pad = (4 - size%4) % 4
+
+	hdr := &tar.Header{Name: entryName, Mode: int64(mode & 0o7777), Size: size, ModTime: time.Unix(int64(mtime), 0)}
+	switch mode & 0o170000 {
+	case 0o100000:
+		hdr.Typeflag = tar.TypeReg
+	case 0o040000:
+		hdr.Typeflag = tar.TypeDir
+	case 0o120000:
+		hdr.Typeflag = tar.TypeSymlink
+		target, err := io.ReadAll(io.LimitReader(c, 4096))
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("cpio: %w", err)
+		}
+		hdr.Linkname = string(target)
+		hdr.Size = 0
+	case 0o020000:
+		hdr.Typeflag = tar.TypeChar
+	case 0o060000:
+		hdr.Typeflag = tar.TypeBlock
+	case 0o010000:
+		hdr.Typeflag = tar.TypeFifo
+	default:
+		// Socket dan tipe lain ditolak oleh extractTarTo
+		hdr.Typeflag = 'S'
+	}
+	return hdr, ino, nlink, nil
+}
+
+// Read membaca isi entry saat ini; io.EOF di akhir entry
+func (c *cpioReader) Read(p []byte) (int, error) {
+	if c.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.r.Read(p)
+	c.remaining -= int64(n)
+	if errors.Is(err, io.EOF) && c.remaining > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// relativeLink mengembalikan target symlink relatif dari name ke target
+// (keduanya nama entry di arsip yang sama)
+func relativeLink(name, target string) (string, error) {
+	rel, err := filepath.Rel(path.Dir(path.Clean("/"+name)), path.Clean("/"+target))
+	if err != nil || rel == "." {
+		return "", fmt.Errorf("cpio: invalid hardlink %q to %q", name, target)
+	}
+	return filepath.ToSlash(rel), nil
+}
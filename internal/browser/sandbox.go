@@ -0,0 +1,60 @@
+package browser
+
+import (
+	"fmt"
+	"strings"
+
+	"go-rod-testing-browser-restrict/internal/runtime"
+)
+
+// SandboxMode menentukan bagaimana sandbox Chrome dipakai
+type SandboxMode string
+
+const (
+	// SandboxAuto mengaktifkan sandbox jika sistem mendukung, dan memakai
+	// --no-sandbox (dengan log alasannya) jika tidak
+	SandboxAuto SandboxMode = "auto"
+	// SandboxOn selalu mengaktifkan sandbox tanpa deteksi
+	SandboxOn SandboxMode = "on"
+	// SandboxOff selalu memakai --no-sandbox
+	SandboxOff SandboxMode = "off"
+	// SandboxStrict mengaktifkan sandbox dan menolak launch jika sistem tidak mendukung
+	SandboxStrict SandboxMode = "strict"
+)
+
+// SandboxUnavailableError dikembalikan pada SandboxStrict jika sandbox tidak
+// bisa dipakai di sistem ini
+type SandboxUnavailableError struct {
+	Reasons []string
+}
+
+func (e *SandboxUnavailableError) Error() string {
+	return fmt.Sprintf("chrome sandbox unavailable: %s", strings.Join(e.Reasons, "; "))
+}
+
+// resolveSandbox menentukan apakah Chrome dijalankan dengan sandbox
+func (cm *ChromiumManager) resolveSandbox(mode SandboxMode) (bool, error) {
+	switch mode {
+	case SandboxOn:
+		return true, nil
+	case SandboxOff:
+		cm.logger("browser_sandbox", "disabled")
+		cm.logger("browser_sandbox_reason", "disabled by launch options")
+		return false, nil
+	}
+
+	status := runtime.DetectSandbox()
+	if status.Supported {
+		cm.logger("browser_sandbox", "enabled")
+		return true, nil
+	}
+
+	if mode == SandboxStrict {
+		return false, &SandboxUnavailableError{Reasons: status.Reasons}
+	}
+	cm.logger("browser_sandbox", "disabled")
+	for _, reason := range status.Reasons {
+		cm.logger("browser_sandbox_reason", reason)
+	}
+	return false, nil
+}
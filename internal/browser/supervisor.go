@@ -0,0 +1,350 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+const (
+	// defaultMaxRelaunches adalah jumlah percobaan relaunch berturut-turut per crash
+	defaultMaxRelaunches = 3
+	// defaultRelaunchBackoff adalah jeda sebelum relaunch pertama, lalu dikali dua
+	defaultRelaunchBackoff = time.Second
+	// crashExitGrace adalah waktu tunggu proses keluar setelah websocket putus
+	crashExitGrace = 2 * time.Second
+	// crashStderrTail adalah jumlah byte stderr terakhir yang disimpan di CrashEvent
+	crashStderrTail = 4 * 1024
+)
+
+// ErrSupervisorClosed dikembalikan oleh Browser setelah Close dipanggil
+var ErrSupervisorClosed = errors.New("supervised browser is closed")
+
+// Penyebab crash pada CrashEvent.Cause
+const (
+	CrashProcessExited   = "process_exited"
+	CrashWebsocketClosed = "websocket_closed"
+)
+
+// CrashEvent menjelaskan Chrome yang berhenti tanpa diminta
+type CrashEvent struct {
+	Time time.Time
+	PID  int
+	// Cause adalah CrashProcessExited atau CrashWebsocketClosed
+	Cause string
+	// ExitStatus contoh "exit status 1" atau "signal: killed" (proses yang
+	// masih hidup setelah websocket putus dimatikan); kosong jika tidak diketahui
+	ExitStatus string
+	// Stderr berisi output terakhir Chrome
+	Stderr string
+	// Crashes adalah jumlah crash sejak browser diawasi, termasuk yang ini
+	Crashes int
+}
+
+// BrowserCrashedError dikembalikan oleh SupervisedBrowser.Browser jika Chrome
+// crash dan tidak (atau gagal) dijalankan ulang
+type BrowserCrashedError struct {
+	Event CrashEvent
+	// Err berisi error relaunch terakhir, nil jika relaunch tidak diaktifkan
+	Err error
+}
+
+func (e *BrowserCrashedError) Error() string {
+	msg := fmt.Sprintf("chrome crashed (%s, pid %d", e.Event.Cause, e.Event.PID)
+	if e.Event.ExitStatus != "" {
+		msg += ", " + e.Event.ExitStatus
+	}
+	msg += ")"
+	if e.Err != nil {
+		msg += fmt.Sprintf(": relaunch failed: %v", e.Err)
+	}
+	return msg + formatStderr(e.Event.Stderr)
+}
+
+func (e *BrowserCrashedError) Unwrap() error {
+	return e.Err
+}
+
+// SupervisorOptions mengatur SupervisedBrowser
+type SupervisorOptions struct {
+	// Launch dipakai untuk launch pertama dan setiap relaunch; nil berarti DefaultLaunchOptions
+	Launch *LaunchOptions
+	// Relaunch menjalankan ulang Chrome secara otomatis setelah crash
+	Relaunch bool
+	// MaxRelaunches membatasi percobaan relaunch berturut-turut per crash (default 3)
+	MaxRelaunches int
+	// RelaunchBackoff adalah jeda sebelum percobaan pertama, dikali dua tiap gagal (default 1 detik)
+	RelaunchBackoff time.Duration
+	// OnCrash dipanggil untuk setiap crash sebelum relaunch dicoba
+	OnCrash func(CrashEvent)
+}
+
+// SupervisedBrowser mengawasi proses Chrome dan koneksi DevTools-nya.
+// Gunakan Browser(ctx) setiap kali butuh *rod.Browser, karena instance-nya
+// diganti setelah relaunch. Page yang dibuat sebelum crash tidak bisa dipakai lagi.
+type SupervisedBrowser struct {
+	cm     *ChromiumManager
+	opts   SupervisorOptions
+	launch LaunchOptions
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu         sync.Mutex
+	browser    *rod.Browser
+	process    *chromeProcess
+	ready      chan struct{}
+	generation int
+	crashes    int
+	err        error
+}
+
+// Supervise menyiapkan Chrome, menjalankannya dan mulai mengawasi prosesnya.
+// ctx hanya membatasi setup dan launch pertama; browser hidup sampai Close.
+func (cm *ChromiumManager) Supervise(ctx context.Context, opts SupervisorOptions) (*SupervisedBrowser, error) {
+	if opts.MaxRelaunches <= 0 {
+		opts.MaxRelaunches = defaultMaxRelaunches
+	}
+	if opts.RelaunchBackoff <= 0 {
+		opts.RelaunchBackoff = defaultRelaunchBackoff
+	}
+	launch := DefaultLaunchOptions()
+	if opts.Launch != nil {
+		launch = *opts.Launch
+	}
+	if err := launch.validate(); err != nil {
+		return nil, fmt.Errorf("invalid launch options: %w", err)
+	}
+
+	if err := cm.SetupContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to setup chrome: %w", err)
+	}
+	if cm.execPath == "" {
+		return nil, fmt.Errorf("chrome executable not found after setup")
+	}
+
+	supCtx, cancel := context.WithCancel(context.Background())
+	browser, process, err := cm.launch(ctx, supCtx, launch)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	s := &SupervisedBrowser{
+		cm:         cm,
+		opts:       opts,
+		launch:     launch,
+		ctx:        supCtx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+		browser:    browser,
+		process:    process,
+		ready:      make(chan struct{}),
+		generation: 1,
+	}
+	close(s.ready)
+
+	go s.supervise()
+	return s, nil
+}
+
+// Browser mengembalikan browser yang sedang berjalan. Jika Chrome sedang
+// dijalankan ulang, Browser menunggu sampai relaunch selesai atau ctx selesai.
+func (s *SupervisedBrowser) Browser(ctx context.Context) (*rod.Browser, error) {
+	for {
+		s.mu.Lock()
+		if s.err != nil {
+			err := s.err
+			s.mu.Unlock()
+			return nil, err
+		}
+		ready := s.ready
+		select {
+		case <-ready:
+			browser := s.browser
+			s.mu.Unlock()
+			return browser, nil
+		default:
+		}
+		s.mu.Unlock()
+
+		// Tunggu relaunch selesai lalu baca ulang state
+		select {
+		case <-ready:
+		case <-s.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Generation bertambah setiap kali Chrome berhasil dijalankan ulang
+func (s *SupervisedBrowser) Generation() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generation
+}
+
+// Done ditutup saat pengawasan berhenti karena Close atau relaunch menyerah
+func (s *SupervisedBrowser) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err mengembalikan alasan pengawasan berhenti, nil selama masih berjalan
+func (s *SupervisedBrowser) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close menutup Chrome dan menghentikan pengawasan
+func (s *SupervisedBrowser) Close() error {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = ErrSupervisorClosed
+	}
+	browser, process := s.browser, s.process
+	s.mu.Unlock()
+
+	s.cancel()
+	if browser != nil {
+		browser.Close()
+	}
+	if process != nil {
+		process.Kill()
+		<-process.Exited()
+	}
+	<-s.done
+	return nil
+}
+
+// supervise menunggu crash lalu menjalankan ulang Chrome jika diizinkan
+func (s *SupervisedBrowser) supervise() {
+	defer close(s.done)
+
+	for {
+		s.mu.Lock()
+		browser, process := s.browser, s.process
+		s.mu.Unlock()
+
+		cause := ""
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-process.Exited():
+			cause = CrashProcessExited
+		case <-watchDisconnect(browser):
+			cause = CrashWebsocketClosed
+		}
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		// Browser(ctx) menunggu relaunch mulai dari sini
+		s.mu.Lock()
+		s.ready = make(chan struct{})
+		s.mu.Unlock()
+		event := s.crashEvent(cause, process)
+
+		s.cm.logger("browser_crash", event.Cause)
+		s.cm.logger("browser_crash_pid", strconv.Itoa(event.PID))
+		if event.ExitStatus != "" {
+			s.cm.logger("browser_crash_exit_status", event.ExitStatus)
+		}
+		if s.opts.OnCrash != nil {
+			s.opts.OnCrash(event)
+		}
+
+		if !s.opts.Relaunch {
+			s.fail(&BrowserCrashedError{Event: event})
+			return
+		}
+		if err := s.relaunch(); err != nil {
+			if s.ctx.Err() != nil {
+				return
+			}
+			s.fail(&BrowserCrashedError{Event: event, Err: err})
+			return
+		}
+	}
+}
+
+// crashEvent mengumpulkan info crash. Jika websocket putus tetapi proses
+// masih hidup, proses diberi waktu keluar sebelum dimatikan.
+func (s *SupervisedBrowser) crashEvent(cause string, process *chromeProcess) CrashEvent {
+	select {
+	case <-process.Exited():
+	case <-time.After(crashExitGrace):
+		process.Kill()
+		select {
+		case <-process.Exited():
+		case <-time.After(crashExitGrace):
+		}
+	}
+
+	s.mu.Lock()
+	s.crashes++
+	crashes := s.crashes
+	s.mu.Unlock()
+
+	stderr := process.stderr.String()
+	if len(stderr) > crashStderrTail {
+		stderr = stderr[len(stderr)-crashStderrTail:]
+	}
+	return CrashEvent{
+		Time:       time.Now(),
+		PID:        process.PID(),
+		Cause:      cause,
+		ExitStatus: process.ExitStatus(),
+		Stderr:     stderr,
+		Crashes:    crashes,
+	}
+}
+
+// relaunch menjalankan ulang Chrome dengan backoff sampai MaxRelaunches kali
+func (s *SupervisedBrowser) relaunch() error {
+	backoff := s.opts.RelaunchBackoff
+	var lastErr error
+	for attempt := 1; attempt <= s.opts.MaxRelaunches; attempt++ {
+		select {
+		case <-time.After(backoff):
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		}
+		backoff *= 2
+
+		s.cm.logger("browser_relaunch_attempt", strconv.Itoa(attempt))
+		browser, process, err := s.cm.launch(s.ctx, s.ctx, s.launch)
+		if err != nil {
+			lastErr = err
+			s.cm.logger("browser_relaunch_error", err.Error())
+			continue
+		}
+
+		s.mu.Lock()
+		s.browser = browser
+		s.process = process
+		s.generation++
+		close(s.ready)
+		s.mu.Unlock()
+		s.cm.logger("browser_relaunched", strconv.Itoa(process.PID()))
+		return nil
+	}
+	return fmt.Errorf("gave up after %d attempts: %w", s.opts.MaxRelaunches, lastErr)
+}
+
+// fail menghentikan pengawasan dengan err
+func (s *SupervisedBrowser) fail(err error) {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.mu.Unlock()
+	s.cm.logger("browser_supervisor_error", err.Error())
+}
@@ -0,0 +1,102 @@
+package browser_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// superviseFakeChrome menjalankan fake Chrome di bawah supervisor
+func superviseFakeChrome(t *testing.T, script string, opts browser.SupervisorOptions) *browser.SupervisedBrowser {
+	t.Helper()
+	mgr := installFakeChrome(t, script)
+	launch := browser.LaunchOptions{Sandbox: browser.SandboxOff}
+	opts.Launch = &launch
+	sup, err := mgr.Supervise(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Supervise failed: %v", err)
+	}
+	t.Cleanup(func() { sup.Close() })
+	return sup
+}
+
+func TestSupervisorRelaunchesAfterProcessExit(t *testing.T) {
+	cdp := newFakeCDP(t)
+	// Launch pertama crash dengan exit code 3, launch berikutnya tetap hidup
+	marker := filepath.Join(t.TempDir(), "crashed")
+	script := cdp.Script(
+		`if [ ! -f "` + marker + `" ]; then touch "` + marker + `"; sleep 0.5; echo "boom: segfault" >&2; exit 3; fi`)
+
+	events := make(chan browser.CrashEvent, 1)
+	sup := superviseFakeChrome(t, script, browser.SupervisorOptions{
+		Relaunch:        true,
+		RelaunchBackoff: 10 * time.Millisecond,
+		OnCrash:         func(e browser.CrashEvent) { events <- e },
+	})
+
+	var event browser.CrashEvent
+	select {
+	case event = <-events:
+	case <-time.After(5 * time.Second):
+		t.Fatal("crash was not reported")
+	}
+	if event.Cause != browser.CrashProcessExited {
+		t.Errorf("unexpected cause %q", event.Cause)
+	}
+	if event.ExitStatus != "exit status 3" {
+		t.Errorf("unexpected exit status %q", event.ExitStatus)
+	}
+	if !strings.Contains(event.Stderr, "boom: segfault") {
+		t.Errorf("stderr tail not captured: %q", event.Stderr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	b, err := sup.Browser(ctx)
+	if err != nil || b == nil {
+		t.Fatalf("Browser after relaunch failed: %v", err)
+	}
+	if got := sup.Generation(); got != 2 {
+		t.Errorf("expected generation 2 after relaunch, got %d", got)
+	}
+	if got := cdp.Connections(); got != 2 {
+		t.Errorf("expected reconnect to the relaunched browser, got %d connections", got)
+	}
+}
+
+func TestSupervisorReportsWebsocketCrashWithoutRelaunch(t *testing.T) {
+	cdp := newFakeCDP(t)
+	events := make(chan browser.CrashEvent, 1)
+	sup := superviseFakeChrome(t, cdp.Script(), browser.SupervisorOptions{
+		OnCrash: func(e browser.CrashEvent) { events <- e },
+	})
+
+	cdp.Drop(0)
+
+	select {
+	case <-sup.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("supervisor did not stop")
+	}
+	event := <-events
+	if event.Cause != browser.CrashWebsocketClosed {
+		t.Errorf("unexpected cause %q", event.Cause)
+	}
+	if event.ExitStatus != "signal: killed" {
+		t.Errorf("hung process should be killed, got %q", event.ExitStatus)
+	}
+
+	_, err := sup.Browser(context.Background())
+	var crashed *browser.BrowserCrashedError
+	if !errors.As(err, &crashed) {
+		t.Fatalf("expected BrowserCrashedError, got %v", err)
+	}
+	if crashed.Err != nil {
+		t.Errorf("no relaunch error expected, got %v", crashed.Err)
+	}
+}
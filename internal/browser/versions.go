@@ -0,0 +1,128 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// versionCacheTTL adalah umur cache untuk resolusi channel/milestone/latest.
+// Resolusi versi exact tidak pernah kedaluwarsa.
+const versionCacheTTL = 24 * time.Hour
+
+// ResolvedVersion adalah entry cache hasil resolusi distribusi
+type ResolvedVersion struct {
+	Release
+	ResolvedAt time.Time `json:"resolved_at"`
+}
+
+// versionQuery mengembalikan kunci cache untuk distribusi dan config saat ini.
+// VersionsBaseURL ikut di kunci agar hasil dari endpoint lain tidak dipakai.
+func (cm *ChromiumManager) versionQuery(dist Distribution) (string, bool) {
+	prefix := dist.Name()
+	if baseURL := strings.TrimRight(cm.config.VersionsBaseURL, "/"); baseURL != "" {
+		prefix += "@" + baseURL
+	}
+	switch {
+	case cm.config.Channel != "":
+		return prefix + "|channel:" + normalizeChannel(cm.config.Channel), false
+	case cm.config.Milestone != "":
+		return prefix + "|milestone:" + cm.config.Milestone, false
+	case cm.config.Version != "":
+		return prefix + "|version:" + cm.config.Version, true
+	}
+	return prefix + "|latest", false
+}
+
+// versionCachePath mengembalikan lokasi cache hasil resolusi versi
+func (cm *ChromiumManager) versionCachePath() string {
+	return filepath.Join(cm.rootDir, ".versions.json")
+}
+
+// resolveVersion menentukan rilis yang akan dipakai. Jika DownloadURL diisi
+// manual, rilis diambil langsung dari Config; selain itu distribusi di-resolve
+// dan hasilnya disimpan di cache lokal sehingga run offline tetap bisa
+// memakai resolusi terakhir.
+func (cm *ChromiumManager) resolveVersion(ctx context.Context) error {
+	if cm.config.DownloadURL != "" {
+		cm.release = Release{
+			Version:     cm.config.Version,
+			DownloadURL: cm.config.DownloadURL,
+			Format:      formatFromURL(cm.config.DownloadURL),
+		}
+		return nil
+	}
+
+	dist, err := newDistribution(cm.config.Distribution, cm.config.VersionsBaseURL, cm.downloader.httpClient())
+	if err != nil {
+		return err
+	}
+	cm.logger("chrome_distribution", dist.Name())
+
+	query, exact := cm.versionQuery(dist)
+	cm.logger("chrome_version_query", query)
+
+	cache := loadVersionCache(cm.versionCachePath())
+	cached, hasCached := cache[query]
+	if hasCached && (exact || time.Since(cached.ResolvedAt) < versionCacheTTL) {
+		cm.logger("chrome_version_resolved", fmt.Sprintf("%s (cache)", cached.Version))
+		cm.release = cached.Release
+		return nil
+	}
+
+	release, err := dist.Resolve(ctx, cm.config)
+	if err != nil {
+		// Pembatalan dari pemanggil tidak dianggap sebagai kondisi offline
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if hasCached {
+			cm.logger("chrome_version_resolve_offline", fmt.Sprintf("using cached %s: %v", cached.Version, err))
+			cm.release = cached.Release
+			return nil
+		}
+		return fmt.Errorf("failed to resolve %s: %w", query, err)
+	}
+
+	cache[query] = ResolvedVersion{Release: release, ResolvedAt: time.Now()}
+	if err := saveVersionCache(cm.versionCachePath(), cache); err != nil {
+		cm.logger("chrome_version_cache_error", err.Error())
+	}
+
+	cm.logger("chrome_version_resolved", release.Version)
+	cm.release = release
+	return nil
+}
+
+// loadVersionCache membaca cache resolusi versi (map kosong jika belum ada)
+func loadVersionCache(path string) map[string]ResolvedVersion {
+	cache := make(map[string]ResolvedVersion)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	json.Unmarshal(data, &cache)
+	return cache
+}
+
+// saveVersionCache menulis cache resolusi versi secara atomik
+func saveVersionCache(path string, cache map[string]ResolvedVersion) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
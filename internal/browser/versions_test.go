@@ -0,0 +1,180 @@
+package browser_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// newVersionsServer menyajikan endpoint JSON Chrome for Testing dan arsip chrome
+func newVersionsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		t.Skip("fixture only provides linux64 downloads")
+	}
+
+	archive := buildChromeZip(t)
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+
+	release := func(version string) map[string]interface{} {
+		return map[string]interface{}{
+			"version":  version,
+			"revision": "1000",
+			"downloads": map[string]interface{}{
+				"chrome": []map[string]string{
+					{"platform": "mac-arm64", "url": srv.URL + "/mac/" + version + ".zip"},
+					{"platform": "linux64", "url": srv.URL + "/linux64/" + version + "/chrome-linux64.zip"},
+				},
+			},
+		}
+	}
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		json.NewEncoder(w).Encode(v)
+	}
+
+	mux.HandleFunc("/last-known-good-versions-with-downloads.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"channels": map[string]interface{}{
+			"Stable": release("131.0.6778.204"),
+			"Beta":   release("132.0.6834.57"),
+		}})
+	})
+	mux.HandleFunc("/latest-versions-per-milestone-with-downloads.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"milestones": map[string]interface{}{
+			"130": release("130.0.6723.116"),
+		}})
+	})
+	mux.HandleFunc("/known-good-versions-with-downloads.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"versions": []interface{}{
+			release("129.0.6668.100"),
+		}})
+	})
+	mux.HandleFunc("/linux64/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestResolveVersionFromChannelMilestoneAndExact(t *testing.T) {
+	srv := newVersionsServer(t)
+
+	cases := []struct {
+		name   string
+		config browser.Config
+		want   string
+	}{
+		{"channel", browser.Config{Channel: "beta"}, "132.0.6834.57"},
+		{"milestone", browser.Config{Milestone: "130"}, "130.0.6723.116"},
+		{"exact", browser.Config{Version: "129.0.6668.100"}, "129.0.6668.100"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.config.InstallDirName = "chrome-test"
+			tc.config.VersionsBaseURL = srv.URL
+			mgr, rootDir := newTestManager(t, tc.config)
+
+			if err := mgr.Setup(); err != nil {
+				t.Fatalf("Setup failed: %v", err)
+			}
+			data, _ := os.ReadFile(filepath.Join(rootDir, browser.DistChromeForTesting, tc.want, ".version"))
+			if string(data) != tc.want {
+				t.Errorf("expected version %s, got %q", tc.want, data)
+			}
+		})
+	}
+}
+
+func TestResolveVersionUnknownMilestone(t *testing.T) {
+	srv := newVersionsServer(t)
+	mgr, _ := newTestManager(t, browser.Config{
+		InstallDirName:  "chrome-test",
+		Milestone:       "99",
+		VersionsBaseURL: srv.URL,
+	})
+
+	if err := mgr.Setup(); err == nil {
+		t.Fatal("expected error for unknown milestone")
+	}
+}
+
+// Jika endpoint tidak bisa dihubungi, resolusi terakhir dari cache dipakai
+func TestResolveVersionOfflineUsesCache(t *testing.T) {
+	srv := newVersionsServer(t)
+	config := browser.Config{
+		InstallDirName:  "chrome-test",
+		Channel:         browser.ChannelStable,
+		VersionsBaseURL: srv.URL,
+	}
+	mgr, rootDir := newTestManager(t, config)
+	if err := mgr.Setup(); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	// Buat cache kedaluwarsa lalu matikan server
+	cachePath := filepath.Join(rootDir, ".versions.json")
+	var cache map[string]map[string]interface{}
+	data, err := os.ReadFile(cachePath)
+	if err != nil || json.Unmarshal(data, &cache) != nil || len(cache) == 0 {
+		t.Fatalf("expected version cache at %s: %v", cachePath, err)
+	}
+	for _, entry := range cache {
+		entry["resolved_at"] = time.Now().Add(-48 * time.Hour).Format(time.RFC3339)
+	}
+	data, _ = json.Marshal(cache)
+	if err := os.WriteFile(cachePath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	offline := browser.NewChromiumManagerWithConfig(config, nil)
+	if err := offline.Setup(); err != nil {
+		t.Fatalf("offline Setup should reuse cached resolution: %v", err)
+	}
+	if offline.GetExecutablePath() == "" {
+		t.Error("offline Setup should find the existing installation")
+	}
+}
+
+// Cache resolusi tidak dipakai ulang untuk VersionsBaseURL lain
+func TestResolveVersionCacheKeyIncludesBaseURL(t *testing.T) {
+	srv := newVersionsServer(t)
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"channels": map[string]interface{}{
+			"Stable": map[string]interface{}{
+				"version":  "131.0.6778.205",
+				"revision": "1001",
+				"downloads": map[string]interface{}{
+					"chrome": []map[string]string{
+						{"platform": "linux64", "url": srv.URL + "/linux64/131.0.6778.205/chrome-linux64.zip"},
+					},
+				},
+			},
+		}})
+	}))
+	t.Cleanup(mirror.Close)
+
+	config := browser.Config{InstallDirName: "chrome-test", Channel: browser.ChannelStable, VersionsBaseURL: srv.URL}
+	mgr, rootDir := newTestManager(t, config)
+	if err := mgr.Setup(); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	config.VersionsBaseURL = mirror.URL
+	if err := browser.NewChromiumManagerWithConfig(config, nil).Setup(); err != nil {
+		t.Fatalf("Setup with mirror failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(rootDir, browser.DistChromeForTesting, "131.0.6778.205", ".version"))
+	if string(data) != "131.0.6778.205" {
+		t.Errorf("expected version from mirror, got %q", data)
+	}
+}
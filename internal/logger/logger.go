@@ -1,38 +1,87 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 )
 
+// Format menentukan bentuk setiap baris log
+type Format string
+
+const (
+	// FormatText menulis "key: value" (default)
+	FormatText Format = "text"
+	// FormatJSON menulis satu objek JSON per baris: {"time":..,"key":..,"value":..}
+	FormatJSON Format = "json"
+)
+
+// ParseFormat mengubah nama format menjadi Format; kosong berarti FormatText
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(name))) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("unknown log format %q (want text or json)", name)
+}
+
 // Logger adalah struct untuk mengelola logging
 type Logger struct {
+	mu     sync.Mutex
 	writer io.Writer
 	path   string
+	format Format
 }
 
 // New membuat instance logger baru
 func New() (*Logger, error) {
+	return NewWithFormat(FormatText)
+}
+
+// NewWithFormat membuat logger dengan format baris tertentu
+func NewWithFormat(format Format) (*Logger, error) {
 	logFile, logPath, err := openLogFile()
 	if err != nil {
 		// Jika gagal membuka file, gunakan stdout saja
 		return &Logger{
 			writer: os.Stdout,
 			path:   "",
+			format: format,
 		}, fmt.Errorf("failed to open log file: %w", err)
 	}
 
 	return &Logger{
 		writer: io.MultiWriter(os.Stdout, logFile),
 		path:   logPath,
+		format: format,
 	}, nil
 }
 
+// NewWriter membuat logger yang hanya menulis ke w (tanpa file log)
+func NewWriter(w io.Writer, format Format) *Logger {
+	return &Logger{writer: w, format: format}
+}
+
 // LogKV mencatat key-value pair
 func (l *Logger) LogKV(key, value string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.format == FormatJSON {
+		line, _ := json.Marshal(struct {
+			Time  string `json:"time"`
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		}{time.Now().UTC().Format(time.RFC3339Nano), key, value})
+		fmt.Fprintf(l.writer, "%s\n", line)
+		return
+	}
 	fmt.Fprintf(l.writer, "%s: %s\n", key, value)
 }
 
@@ -0,0 +1,37 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-rod-testing-browser-restrict/internal/logger"
+)
+
+// Test format text dan json
+func TestLogKVFormats(t *testing.T) {
+	var text bytes.Buffer
+	logger.NewWriter(&text, logger.FormatText).LogKV("browser_pid", "42")
+	if got := text.String(); got != "browser_pid: 42\n" {
+		t.Errorf("text = %q", got)
+	}
+
+	var js bytes.Buffer
+	logger.NewWriter(&js, logger.FormatJSON).LogKV("page_title", `a "quoted" title`)
+	var line struct {
+		Time  string `json:"time"`
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(js.String())), &line); err != nil {
+		t.Fatalf("invalid json line %q: %v", js.String(), err)
+	}
+	if line.Key != "page_title" || line.Value != `a "quoted" title` || line.Time == "" {
+		t.Errorf("json line = %+v", line)
+	}
+
+	if _, err := logger.ParseFormat("xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
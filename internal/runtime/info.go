@@ -37,6 +37,7 @@ func (ri *Info) LogAll() {
 	ri.LogUserInfo()
 	ri.LogEnvironment()
 	ri.LogLinuxSpecific()
+	ri.LogSandbox()
 }
 
 // LogBasicInfo mencatat informasi dasar
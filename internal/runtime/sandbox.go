@@ -0,0 +1,164 @@
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// SandboxStatus berisi hasil deteksi apakah sandbox Chrome bisa dipakai.
+// Di Linux sandbox Chrome membutuhkan user namespace tanpa privilege dan
+// tidak boleh dijalankan sebagai root.
+type SandboxStatus struct {
+	// Supported bernilai true jika sandbox aman untuk diaktifkan
+	Supported bool
+	// Reasons berisi alasan sandbox tidak bisa dipakai (kosong jika Supported)
+	Reasons []string
+	// RunningAsRoot bernilai true jika effective UID adalah 0
+	RunningAsRoot bool
+	// UnprivilegedUsernsClone berisi isi kernel.unprivileged_userns_clone
+	// ("" jika sysctl tidak ada, misalnya kernel non-Debian)
+	UnprivilegedUsernsClone string
+	// AppArmorRestrictUnprivilegedUserns berisi isi
+	// kernel.apparmor_restrict_unprivileged_userns ("" jika sysctl tidak ada).
+	// Aktif secara default di Ubuntu 23.10+ dan runner ubuntu-24.04.
+	AppArmorRestrictUnprivilegedUserns string
+	// MaxUserNamespaces berisi user.max_user_namespaces (-1 jika tidak diketahui)
+	MaxUserNamespaces int
+	// Seccomp berisi mode seccomp proses ini: "0" disabled, "1" strict, "2" filter
+	Seccomp string
+}
+
+// SandboxProbe mendeteksi dukungan sandbox. Field kosong memakai nilai sistem,
+// field diisi untuk test atau untuk memeriksa proc root lain.
+type SandboxProbe struct {
+	// ProcRoot default "/proc"
+	ProcRoot string
+	// UID adalah effective UID; nil berarti os.Geteuid()
+	UID *int
+	// UserNamespaceProbe mencoba membuat user namespace; nil berarti probe
+	// bawaan platform. Dipanggil jika pemeriksaan sysctl dan seccomp belum
+	// menemukan alasan sandbox tidak bisa dipakai.
+	UserNamespaceProbe func() error
+}
+
+// DetectSandbox mendeteksi dukungan sandbox di sistem ini
+func DetectSandbox() SandboxStatus {
+	return SandboxProbe{}.Detect()
+}
+
+// Detect menjalankan semua pemeriksaan sandbox
+func (p SandboxProbe) Detect() SandboxStatus {
+	status := SandboxStatus{MaxUserNamespaces: -1}
+
+	// Di luar Linux Chrome tidak bergantung pada user namespace
+	if runtime.GOOS != "linux" && p.ProcRoot == "" {
+		status.Supported = true
+		return status
+	}
+
+	procRoot := p.ProcRoot
+	if procRoot == "" {
+		procRoot = "/proc"
+	}
+	uid := os.Geteuid()
+	if p.UID != nil {
+		uid = *p.UID
+	}
+
+	if uid == 0 {
+		status.RunningAsRoot = true
+		status.Reasons = append(status.Reasons, "running as root")
+	}
+
+	if val, err := readFirstLine(filepath.Join(procRoot, "sys", "kernel", "unprivileged_userns_clone")); err == nil {
+		status.UnprivilegedUsernsClone = val
+		if val == "0" {
+			status.Reasons = append(status.Reasons, "kernel.unprivileged_userns_clone=0")
+		}
+	}
+
+	// Dengan restriksi AppArmor, clone(CLONE_NEWUSER) tetap berhasil tetapi
+	// namespace-nya tanpa capability sehingga sandbox Chrome gagal ("No usable
+	// sandbox"). Chrome for Testing tidak membawa chrome-sandbox setuid maupun
+	// profil AppArmor, jadi probe di bawah tidak bisa mendeteksi kasus ini.
+	if val, err := readFirstLine(filepath.Join(procRoot, "sys", "kernel", "apparmor_restrict_unprivileged_userns")); err == nil {
+		status.AppArmorRestrictUnprivilegedUserns = val
+		if val == "1" {
+			status.Reasons = append(status.Reasons, "kernel.apparmor_restrict_unprivileged_userns=1")
+		}
+	}
+
+	if val, err := readFirstLine(filepath.Join(procRoot, "sys", "user", "max_user_namespaces")); err == nil {
+		if n, err := strconv.Atoi(val); err == nil {
+			status.MaxUserNamespaces = n
+			if n == 0 {
+				status.Reasons = append(status.Reasons, "user.max_user_namespaces=0")
+			}
+		}
+	}
+
+	status.Seccomp = readSeccompMode(filepath.Join(procRoot, "self", "status"))
+	if status.Seccomp == "1" {
+		status.Reasons = append(status.Reasons, "seccomp strict mode")
+	}
+
+	// Sysctl tidak konklusif (tidak ada, atau kernel/LSM lain membatasi):
+	// coba buat user namespace sungguhan. Filter seccomp (misalnya profil
+	// default Docker) sering memblokir clone(CLONE_NEWUSER).
+	if len(status.Reasons) == 0 {
+		probe := p.UserNamespaceProbe
+		if probe == nil {
+			probe = probeUserNamespace
+		}
+		if err := probe(); err != nil {
+			if status.Seccomp == "2" {
+				status.Reasons = append(status.Reasons, fmt.Sprintf("seccomp filter blocks user namespaces: %v", err))
+			} else {
+				status.Reasons = append(status.Reasons, fmt.Sprintf("cannot create user namespace: %v", err))
+			}
+		}
+	}
+
+	status.Supported = len(status.Reasons) == 0
+	return status
+}
+
+// LogSandbox mencatat hasil deteksi sandbox
+func (ri *Info) LogSandbox() {
+	status := DetectSandbox()
+	ri.logger("sandbox_supported", strconv.FormatBool(status.Supported))
+	ri.logger("sandbox_running_as_root", strconv.FormatBool(status.RunningAsRoot))
+	if status.UnprivilegedUsernsClone != "" {
+		ri.logger("sandbox_unprivileged_userns_clone", status.UnprivilegedUsernsClone)
+	}
+	if status.AppArmorRestrictUnprivilegedUserns != "" {
+		ri.logger("sandbox_apparmor_restrict_unprivileged_userns", status.AppArmorRestrictUnprivilegedUserns)
+	}
+	if status.MaxUserNamespaces >= 0 {
+		ri.logger("sandbox_max_user_namespaces", strconv.Itoa(status.MaxUserNamespaces))
+	}
+	if status.Seccomp != "" {
+		ri.logger("sandbox_seccomp", status.Seccomp)
+	}
+	for _, reason := range status.Reasons {
+		ri.logger("sandbox_unsupported_reason", reason)
+	}
+}
+
+// readSeccompMode membaca field "Seccomp:" dari /proc/<pid>/status
+func readSeccompMode(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "Seccomp:"); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,21 @@
+//go:build linux
+
+package runtime
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// probeUserNamespace menjalankan "true" di user namespace baru untuk
+// memastikan clone(CLONE_NEWUSER) tidak diblokir
+func probeUserNamespace() error {
+	bin, err := exec.LookPath("true")
+	if err != nil {
+		// Tidak bisa menguji; anggap didukung dan serahkan ke Chrome
+		return nil
+	}
+	cmd := exec.Command(bin)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Cloneflags: syscall.CLONE_NEWUSER}
+	return cmd.Run()
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package runtime
+
+// probeUserNamespace tidak relevan di luar Linux
+func probeUserNamespace() error {
+	return nil
+}
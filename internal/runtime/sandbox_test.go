@@ -0,0 +1,131 @@
+package runtime_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-rod-testing-browser-restrict/internal/runtime"
+)
+
+// fakeProc membuat proc root palsu berisi file sysctl dan status
+func fakeProc(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestDetectSandbox(t *testing.T) {
+	root, user := 0, 1000
+	blocked := func() error { return errors.New("operation not permitted") }
+	allowed := func() error { return nil }
+
+	tests := []struct {
+		name      string
+		files     map[string]string
+		uid       *int
+		probe     func() error
+		supported bool
+		reason    string
+	}{
+		{
+			name: "supported",
+			files: map[string]string{
+				"sys/kernel/unprivileged_userns_clone": "1\n",
+				"sys/user/max_user_namespaces":         "63704\n",
+				"self/status":                          "Name:\tgo\nSeccomp:\t0\n",
+			},
+			uid:       &user,
+			probe:     allowed,
+			supported: true,
+		},
+		{
+			name: "apparmor restricts user namespaces",
+			files: map[string]string{
+				"sys/kernel/apparmor_restrict_unprivileged_userns": "1\n",
+				"sys/user/max_user_namespaces":                     "63704\n",
+				"self/status":                                      "Seccomp:\t0\n",
+			},
+			uid: &user,
+			// clone(CLONE_NEWUSER) tetap berhasil di bawah AppArmor
+			probe:  allowed,
+			reason: "apparmor_restrict_unprivileged_userns=1",
+		},
+		{
+			name: "apparmor restriction disabled",
+			files: map[string]string{
+				"sys/kernel/apparmor_restrict_unprivileged_userns": "0\n",
+				"self/status": "Seccomp:\t0\n",
+			},
+			uid:       &user,
+			probe:     allowed,
+			supported: true,
+		},
+		{
+			name:   "probe fails without seccomp filter",
+			files:  map[string]string{"self/status": "Seccomp:\t0\n"},
+			uid:    &user,
+			probe:  blocked,
+			reason: "cannot create user namespace",
+		},
+		{
+			name:   "root",
+			files:  map[string]string{"self/status": "Seccomp:\t0\n"},
+			uid:    &root,
+			reason: "running as root",
+		},
+		{
+			name:   "userns clone disabled",
+			files:  map[string]string{"sys/kernel/unprivileged_userns_clone": "0\n"},
+			uid:    &user,
+			reason: "unprivileged_userns_clone=0",
+		},
+		{
+			name:   "no user namespaces",
+			files:  map[string]string{"sys/user/max_user_namespaces": "0\n"},
+			uid:    &user,
+			reason: "max_user_namespaces=0",
+		},
+		{
+			name:   "seccomp filter blocks clone",
+			files:  map[string]string{"self/status": "Seccomp:\t2\n"},
+			uid:    &user,
+			probe:  blocked,
+			reason: "seccomp filter",
+		},
+		{
+			name:      "seccomp filter allows clone",
+			files:     map[string]string{"self/status": "Seccomp:\t2\n"},
+			uid:       &user,
+			probe:     allowed,
+			supported: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := runtime.SandboxProbe{
+				ProcRoot:           fakeProc(t, tt.files),
+				UID:                tt.uid,
+				UserNamespaceProbe: tt.probe,
+			}.Detect()
+			if status.Supported != tt.supported {
+				t.Fatalf("Supported = %v, want %v (reasons %v)", status.Supported, tt.supported, status.Reasons)
+			}
+			if tt.reason != "" && !strings.Contains(strings.Join(status.Reasons, "; "), tt.reason) {
+				t.Errorf("reasons %v do not mention %q", status.Reasons, tt.reason)
+			}
+		})
+	}
+}
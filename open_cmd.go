@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// launchFlags adalah flag launch yang dipakai bersama oleh open, screenshot,
+// pdf dan serve
+type launchFlags struct {
+	headless  string
+	sandbox   string
+	proxy     string
+	allow     string
+	block     string
+	blockList string
+	guard     bool
+	harDir    string
+	harReplay string
+	unmatched string
+}
+
+// register mendaftarkan launchFlags ke fs
+func (f *launchFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.headless, "headless", "", "mode headless: new, old atau off (default dari config)")
+	fs.StringVar(&f.sandbox, "sandbox", "", "mode sandbox: auto, on, off atau strict (default dari config)")
+	fs.StringVar(&f.proxy, "proxy", "", "proxy server, contoh http://127.0.0.1:8080")
+	fs.StringVar(&f.allow, "allow", "", "daftar host yang boleh dimuat, dipisah koma (contoh example.com,*.example.com)")
+	fs.StringVar(&f.block, "block", "", "tipe resource yang diblokir, dipisah koma (contoh Image,Media,Font)")
+	fs.StringVar(&f.blockList, "blocklist", "", "file daftar blokir EasyList atau hosts")
+	fs.BoolVar(&f.guard, "ssrf-guard", false, "blokir request ke alamat private, loopback dan link-local")
+	fs.StringVar(&f.harDir, "har-dir", "", "rekam setiap page ke file HAR di direktori ini")
+	fs.StringVar(&f.harReplay, "har-replay", "", "jawab request dari file HAR")
+	fs.StringVar(&f.unmatched, "replay-unmatched", string(browser.ReplayFail), "request yang tidak ada di HAR: fail, passthrough atau 404")
+}
+
+// options menerapkan launchFlags ke salinan opsi launch global
+func (f *launchFlags) options(c *cli) (browser.LaunchOptions, error) {
+	opts := c.launch
+	if f.headless != "" {
+		opts.Headless = browser.HeadlessMode(f.headless)
+	}
+	if f.sandbox != "" {
+		opts.Sandbox = browser.SandboxMode(f.sandbox)
+	}
+	if f.proxy != "" {
+		opts.Proxy = f.proxy
+	}
+	if hosts := splitList(f.allow); len(hosts) > 0 {
+		opts.Policy = &browser.Policy{Allow: []browser.PolicyRule{{Hosts: hosts}}}
+	}
+	if types := splitList(f.block); len(types) > 0 || f.blockList != "" {
+		blocker := &browser.Blocker{ResourceTypes: types}
+		if f.blockList != "" {
+			list, err := browser.LoadBlockList(filepath.Base(f.blockList), f.blockList)
+			if err != nil {
+				return opts, err
+			}
+			blocker.Lists = append(blocker.Lists, list)
+		}
+		opts.Blocker = blocker
+	}
+	if f.guard {
+		opts.NetworkGuard = &browser.NetworkGuard{}
+	}
+	if f.harDir != "" {
+		if err := os.MkdirAll(f.harDir, 0o755); err != nil {
+			return opts, fmt.Errorf("failed to create har directory: %w", err)
+		}
+		opts.HAR = &browser.HAROptions{Dir: f.harDir, IncludeBodies: true}
+	}
+	if f.harReplay != "" {
+		replayer, err := browser.LoadHARReplayer(f.harReplay, browser.ReplayOptions{Unmatched: browser.ReplayUnmatched(f.unmatched)})
+		if err != nil {
+			return opts, err
+		}
+		opts.Replay = replayer
+	}
+	return opts, nil
+}
+
+// pageFlags adalah flag untuk subcommand yang membuka satu URL
+type pageFlags struct {
+	launchFlags
+	timeout time.Duration
+}
+
+// register mendaftarkan pageFlags ke fs
+func (f *pageFlags) register(fs *flag.FlagSet) {
+	f.launchFlags.register(fs)
+	fs.DurationVar(&f.timeout, "timeout", 2*time.Minute, "batas waktu setup, launch dan load page")
+}
+
+// pageSession adalah browser dengan satu page yang sudah selesai loading
+type pageSession struct {
+	browser *rod.Browser
+	page    *rod.Page
+	opts    browser.LaunchOptions
+	cancel  context.CancelFunc
+}
+
+// Close menutup browser
+func (s *pageSession) Close(c *cli) {
+	if s.opts.Blocker != nil {
+		c.log.LogKV("blocked_requests", s.opts.Blocker.Stats().String())
+	}
+	if s.opts.Replay != nil {
+		stats := s.opts.Replay.Stats()
+		c.log.LogKV("har_replay_stats", fmt.Sprintf("matched=%d unmatched=%d", stats.Matched, stats.Unmatched))
+	}
+	if err := s.browser.Close(); err != nil {
+		c.log.LogKV("browser_close_error", err.Error())
+	}
+	s.cancel()
+}
+
+// openPage menjalankan Chrome, membuka rawURL dan menunggu page selesai loading.
+// Jika gagal, exit code dikembalikan dan session bernilai nil.
+func (c *cli) openPage(f *pageFlags, rawURL string, prepare func(*rod.Page) error) (*pageSession, int) {
+	opts, err := f.options(c)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return nil, exitUsage
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	b, err := c.manager().GetBrowserWithOptions(ctx, opts)
+	if err != nil {
+		cancel()
+		return nil, c.launchExitCode(err)
+	}
+	s := &pageSession{browser: b, opts: opts, cancel: cancel}
+
+	// Timeout berlaku untuk operasi page; browser tetap bisa ditutup
+	// setelah timeout lewat
+	page, err := b.Context(ctx).Page(proto.TargetCreateTarget{})
+	if err != nil {
+		c.log.LogKV("page_error", err.Error())
+		fmt.Fprintf(os.Stderr, "Page error: %s\n", err)
+		s.Close(c)
+		return nil, exitLaunch
+	}
+	s.page = page
+	if prepare != nil {
+		if err := prepare(page); err != nil {
+			c.log.LogKV("page_error", err.Error())
+			fmt.Fprintf(os.Stderr, "Page error: %s\n", err)
+			s.Close(c)
+			return nil, exitError
+		}
+	}
+
+	if code := c.navigate(page, opts.Policy, rawURL); code != exitOK {
+		s.Close(c)
+		return nil, code
+	}
+	return s, exitOK
+}
+
+// navigate membuka rawURL dan menunggu load. URL yang ditolak policy tidak
+// pernah dimuat.
+func (c *cli) navigate(page *rod.Page, policy *browser.Policy, rawURL string) int {
+	var err error
+	if policy != nil {
+		err = policy.Navigate(page, rawURL)
+	} else {
+		err = page.Navigate(rawURL)
+	}
+	var blocked *browser.NavigationBlockedError
+	if errors.As(err, &blocked) {
+		c.log.LogKV("navigate_blocked", blocked.Error())
+		fmt.Fprintf(os.Stderr, "Navigate blocked: %s\n", blocked.Error())
+		return exitNavigate
+	} else if err != nil {
+		c.log.LogKV("navigate_error", err.Error())
+		fmt.Fprintf(os.Stderr, "Navigate error: %s\n", err)
+		return exitNavigate
+	}
+
+	if err := page.WaitLoad(); err != nil {
+		c.log.LogKV("load_error", err.Error())
+		fmt.Fprintf(os.Stderr, "Load error: %s\n", err)
+		return exitNavigate
+	}
+	return exitOK
+}
+
+// runOpen menjalankan subcommand "open <url>"
+func runOpen(c *cli, args []string) int {
+	fs := flag.NewFlagSet("open", flag.ContinueOnError)
+	var f pageFlags
+	f.register(fs)
+	rawURL, ok := parseURLArgs(fs, args, "open [flags] <url>")
+	if !ok {
+		return exitUsage
+	}
+
+	s, code := c.openPage(&f, rawURL, nil)
+	if s == nil {
+		return code
+	}
+	defer s.Close(c)
+
+	info, err := s.page.Info()
+	if err != nil {
+		c.log.LogKV("page_info_error", err.Error())
+		fmt.Fprintf(os.Stderr, "Page info error: %s\n", err)
+		return exitError
+	}
+	c.log.LogKV("page_title", info.Title)
+	c.log.LogKV("page_url", info.URL)
+	fmt.Printf("\nPage Title: %s\n", info.Title)
+	fmt.Printf("Page URL: %s\n", info.URL)
+	c.log.LogKV("status", "success")
+	return exitOK
+}
+
+// runScreenshot menjalankan subcommand "screenshot <url>"
+func runScreenshot(c *cli, args []string) int {
+	fs := flag.NewFlagSet("screenshot", flag.ContinueOnError)
+	var f pageFlags
+	f.register(fs)
+	output := fs.String("o", "screenshot.png", "file output; ekstensi .jpg/.jpeg menghasilkan JPEG")
+	fullPage := fs.Bool("full-page", false, "ambil seluruh page, bukan hanya viewport")
+	width := fs.Int("width", 1280, "lebar viewport")
+	height := fs.Int("height", 800, "tinggi viewport")
+	quality := fs.Int("quality", 90, "kualitas JPEG (0-100)")
+	rawURL, ok := parseURLArgs(fs, args, "screenshot [flags] -o file.png <url>")
+	if !ok {
+		return exitUsage
+	}
+	if *width <= 0 || *height <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: invalid viewport %dx%d\n", *width, *height)
+		return exitUsage
+	}
+
+	req := &proto.PageCaptureScreenshot{Format: proto.PageCaptureScreenshotFormatPng}
+	switch strings.ToLower(filepath.Ext(*output)) {
+	case ".jpg", ".jpeg":
+		req.Format = proto.PageCaptureScreenshotFormatJpeg
+		req.Quality = quality
+	}
+
+	s, code := c.openPage(&f, rawURL, func(page *rod.Page) error {
+		return page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+			Width:             *width,
+			Height:            *height,
+			DeviceScaleFactor: 1,
+		})
+	})
+	if s == nil {
+		return code
+	}
+	defer s.Close(c)
+
+	data, err := s.page.Screenshot(*fullPage, req)
+	if err != nil {
+		c.log.LogKV("screenshot_error", err.Error())
+		fmt.Fprintf(os.Stderr, "Screenshot error: %s\n", err)
+		return exitError
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return exitError
+	}
+	c.log.LogKV("screenshot_file", *output)
+	fmt.Println(*output)
+	return exitOK
+}
+
+// runPDF menjalankan subcommand "pdf <url>"
+func runPDF(c *cli, args []string) int {
+	fs := flag.NewFlagSet("pdf", flag.ContinueOnError)
+	var f pageFlags
+	f.register(fs)
+	output := fs.String("o", "page.pdf", "file output")
+	landscape := fs.Bool("landscape", false, "orientasi landscape")
+	background := fs.Bool("background", true, "cetak warna dan gambar background")
+	rawURL, ok := parseURLArgs(fs, args, "pdf [flags] -o file.pdf <url>")
+	if !ok {
+		return exitUsage
+	}
+
+	s, code := c.openPage(&f, rawURL, nil)
+	if s == nil {
+		return code
+	}
+	defer s.Close(c)
+
+	stream, err := s.page.PDF(&proto.PagePrintToPDF{Landscape: *landscape, PrintBackground: *background})
+	if err != nil {
+		c.log.LogKV("pdf_error", err.Error())
+		fmt.Fprintf(os.Stderr, "PDF error: %s\n", err)
+		return exitError
+	}
+	if err := writeStream(*output, stream); err != nil {
+		c.log.LogKV("pdf_error", err.Error())
+		fmt.Fprintf(os.Stderr, "PDF error: %s\n", err)
+		return exitError
+	}
+	c.log.LogKV("pdf_file", *output)
+	fmt.Println(*output)
+	return exitOK
+}
+
+// writeStream menyalin r ke file path
+func writeStream(path string, r io.Reader) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+// parseURLArgs mem-parse flag dan mengembalikan satu argumen URL
+func parseURLArgs(fs *flag.FlagSet, args []string, usage string) (string, bool) {
+	if err := fs.Parse(args); err != nil {
+		return "", false
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", usage)
+		return "", false
+	}
+	return fs.Arg(0), true
+}
+
+// splitList memecah daftar yang dipisah koma dan membuang entry kosong
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
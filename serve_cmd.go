@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// runServe menjalankan subcommand "serve": Chrome diawasi dan dijalankan ulang
+// saat crash, sampai SIGINT/SIGTERM diterima
+func runServe(c *cli, args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	var f launchFlags
+	f.register(fs)
+	port := fs.Int("port", 9222, "port remote debugging DevTools")
+	relaunch := fs.Bool("relaunch", true, "jalankan ulang Chrome setelah crash")
+	timeout := fs.Duration("timeout", 10*time.Minute, "batas waktu setup dan launch pertama")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "Usage: serve [-port 9222] [-relaunch=false] [flags]")
+		return exitUsage
+	}
+
+	opts, err := f.options(c)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return exitUsage
+	}
+	opts.DevtoolsPort = *port
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	setupCtx, cancel := context.WithTimeout(ctx, *timeout)
+	supervised, err := c.manager().Supervise(setupCtx, browser.SupervisorOptions{
+		Launch:   &opts,
+		Relaunch: *relaunch,
+		OnCrash: func(event browser.CrashEvent) {
+			c.log.LogKV("serve_crash", fmt.Sprintf("cause=%s pid=%d crashes=%d", event.Cause, event.PID, event.Crashes))
+		},
+	})
+	cancel()
+	if err != nil {
+		return c.launchExitCode(err)
+	}
+	defer func() {
+		if err := supervised.Close(); err != nil {
+			c.log.LogKV("browser_close_error", err.Error())
+		}
+	}()
+
+	address := "http://127.0.0.1:" + strconv.Itoa(*port)
+	c.log.LogKV("serve_devtools", address)
+	fmt.Printf("DevTools listening on %s (Ctrl+C to stop)\n", address)
+
+	select {
+	case <-ctx.Done():
+		c.log.LogKV("serve_status", "stopped")
+		return exitOK
+	case <-supervised.Done():
+		err := supervised.Err()
+		c.log.LogKV("serve_status", "browser_stopped")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return exitLaunch
+		}
+		return exitOK
+	}
+}
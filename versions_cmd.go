@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"go-rod-testing-browser-restrict/internal/browser"
+)
+
+// runVersions menjalankan subcommand "versions" (list, pin, unpin, prune)
+func runVersions(mgr *browser.ChromiumManager, args []string) int {
+	if len(args) == 0 {
+		printVersionsUsage()
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "list":
+		versions, err := mgr.ListVersions()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return exitError
+		}
+		if len(versions) == 0 {
+			fmt.Println("No installed versions")
+			return exitOK
+		}
+		fmt.Printf("%-20s %-22s %-8s %10s  %s\n", "VERSION", "DISTRIBUTION", "PINNED", "SIZE_MB", "LAST_USED")
+		for _, v := range versions {
+			fmt.Printf("%-20s %-22s %-8t %10.1f  %s\n", v.Version, v.Distribution, v.Pinned,
+				float64(v.SizeBytes)/(1024*1024), v.LastUsed.Format(time.RFC3339))
+		}
+		return exitOK
+
+	case "pin", "unpin":
+		if len(args) != 2 {
+			fmt.Fprintf(os.Stderr, "Usage: versions %s [<distribution>/]<version>\n", args[0])
+			return exitUsage
+		}
+		if err := mgr.PinVersion(args[1], args[0] == "pin"); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return exitError
+		}
+		fmt.Printf("%sned %s\n", args[0], args[1])
+		return exitOK
+
+	case "prune":
+		fs := flag.NewFlagSet("versions prune", flag.ContinueOnError)
+		maxAge := fs.Duration("max-age", 0, "hapus versi yang tidak dipakai lebih lama dari durasi ini (misal 720h)")
+		keep := fs.Int("keep", 0, "simpan hanya N versi yang terakhir dipakai")
+		maxSizeMB := fs.Int64("max-size-mb", 0, "batas total ukuran semua versi dalam MB")
+		dryRun := fs.Bool("dry-run", false, "hanya tampilkan versi yang akan dihapus")
+		if err := fs.Parse(args[1:]); err != nil {
+			return exitUsage
+		}
+
+		removed, err := mgr.PruneVersions(browser.PruneOptions{
+			MaxAge:       *maxAge,
+			KeepLatest:   *keep,
+			MaxTotalSize: *maxSizeMB * 1024 * 1024,
+			DryRun:       *dryRun,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return exitError
+		}
+		for _, v := range removed {
+			fmt.Printf("pruned %s (%s)\n", v.Version, v.Path)
+		}
+		if len(removed) == 0 {
+			fmt.Println("Nothing to prune")
+		}
+		return exitOK
+	}
+
+	printVersionsUsage()
+	return exitUsage
+}
+
+func printVersionsUsage() {
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr, "  versions list")
+	fmt.Fprintln(os.Stderr, "  versions pin [<distribution>/]<version>")
+	fmt.Fprintln(os.Stderr, "  versions unpin [<distribution>/]<version>")
+	fmt.Fprintln(os.Stderr, "  versions prune [-max-age 720h] [-keep 3] [-max-size-mb 1024] [-dry-run]")
+}